@@ -0,0 +1,52 @@
+package global
+
+import (
+	"image"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+// Anti-templates (find_game/anti/*.png) veto entry clicks: if one is matched near a
+// candidate entry, that entry is not clicked (e.g. a "full" badge over the button).
+//
+// Pairing is by the leading number, like entry priorities:
+//   - "20_full.png" only vetoes entries with priority 20 ("20.png", "20-1.png")
+//   - "full.png" (no leading number) vetoes every entry
+//
+// "Near" means overlapping the entry's box grown by constants.AntiTemplateMargin.
+
+// antiApplies reports whether the anti-template named antiName pairs with entity e
+func antiApplies(antiName string, e DetectedEntity) bool {
+	spec := ParseTargetName(antiName)
+	return !spec.HasPriority || spec.Priority == e.Priority
+}
+
+// vetoedBy returns the name of the anti-template that vetoes clicking e, if any
+func (b *GlobalBot) vetoedBy(screenImg image.Image, e DetectedEntity) (string, bool) {
+	area := image.Rectangle{Min: e.Position, Max: e.Position.Add(e.TemplateSize)}.Inset(-constants.AntiTemplateMargin)
+	for _, anti := range b.targetsAnti {
+		if !antiApplies(anti.Name, e) {
+			continue
+		}
+		if len(b.searcher.FindAllTemplatesInROI(screenImg, anti.Image, area, b.tick.Tolerance)) > 0 {
+			return anti.Name, true
+		}
+	}
+	return "", false
+}
+
+// filterVetoed drops entities vetoed by an anti-template
+func (b *GlobalBot) filterVetoed(screenImg image.Image, entities []DetectedEntity) []DetectedEntity {
+	if len(b.targetsAnti) == 0 {
+		return entities
+	}
+	var result []DetectedEntity
+	for _, e := range entities {
+		if anti, vetoed := b.vetoedBy(screenImg, e); vetoed {
+			b.debugFunc("[Entry] %s at (%d, %d) vetoed by anti-template %s", e.TemplateName, e.Position.X, e.Position.Y, anti)
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
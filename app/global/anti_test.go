@@ -0,0 +1,50 @@
+package global
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestAntiTemplateVetoesClick(t *testing.T) {
+	// A "full" badge right next to the 20 button; the 10 button further down is clear
+	badge := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			badge.SetNRGBA(x, y, color.NRGBA{255, 255, 255, 255})
+		}
+	}
+	b20, b10 := newTemplate(20, 20, 0), newTemplate(20, 20, 90)
+	frame := newScreen(200, 200)
+	paste(frame, b20, 30, 30)
+	paste(frame, badge, 55, 35)
+	paste(frame, b10, 30, 140)
+
+	tests := []struct {
+		anti string // "" = no anti-template
+		want image.Point
+	}{
+		{"", image.Point{X: 40, Y: 40}},             // The higher priority 20 is clicked
+		{"20_full.png", image.Point{X: 40, Y: 150}}, // Vetoed: the 10 instead
+		{"10_full.png", image.Point{X: 40, Y: 40}},  // Pairs with 10 only, which has no badge
+		{"full.png", image.Point{X: 40, Y: 150}},    // Unnumbered: vetoes any entry near it
+	}
+	for _, tt := range tests {
+		name := tt.anti
+		if name == "" {
+			name = "none"
+		}
+		t.Run(name, func(t *testing.T) {
+			b, _, actions := newFrameBot(t, frame)
+			b.targetsGames = []Target{{Name: "20.png", Image: b20}, {Name: "10.png", Image: b10}}
+			if tt.anti != "" {
+				b.targetsAnti = []Target{{Name: tt.anti, Image: badge}}
+			}
+			b.State = StateEntry
+			runTick(b)
+			if len(actions.clicks) != 1 || actions.clicks[0] != tt.want {
+				t.Errorf("clicks = %v, want one at %v", actions.clicks, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package global
+
+import (
+	"fmt"
+	"image"
+)
+
+// BlacklistAction is what the bot does when an entry entity is blacklisted
+// (clicked the tracker's max times without leaving the entry screen)
+type BlacklistAction int
+
+const (
+	BlacklistSkip   BlacklistAction = iota // Ignore the entity and keep scanning (default)
+	BlacklistScroll                        // Scroll the entry list (see scrollEntryList)
+	BlacklistNotify                        // Alert and notify, then keep scanning
+	BlacklistStop                          // Alert and notify, then stop the bot
+)
+
+func (a BlacklistAction) String() string {
+	switch a {
+	case BlacklistSkip:
+		return "skip"
+	case BlacklistScroll:
+		return "scroll"
+	case BlacklistNotify:
+		return "notify"
+	case BlacklistStop:
+		return "stop"
+	default:
+		return fmt.Sprintf("BlacklistAction(%d)", int(a))
+	}
+}
+
+// ParseBlacklistAction converts a config name ("skip", "scroll", "notify", "stop") to a
+// BlacklistAction. An empty string means BlacklistSkip.
+func ParseBlacklistAction(name string) (BlacklistAction, error) {
+	switch name {
+	case "", "skip":
+		return BlacklistSkip, nil
+	case "scroll":
+		return BlacklistScroll, nil
+	case "notify":
+		return BlacklistNotify, nil
+	case "stop":
+		return BlacklistStop, nil
+	default:
+		return BlacklistSkip, fmt.Errorf("unknown blacklist action %q", name)
+	}
+}
+
+// SetBlacklistAction sets what happens when an entry entity is blacklisted
+func (b *GlobalBot) SetBlacklistAction(action BlacklistAction) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.blacklistAction = action
+	b.cfg.EntryBlacklistAction = action.String()
+}
+
+// onBlacklisted applies the blacklist action to a just-blacklisted entity found on
+// screenImg. Returns true if the bot is stopping.
+func (b *GlobalBot) onBlacklisted(screenImg image.Image, entity DetectedEntity, clicks int) bool {
+	b.mu.Lock()
+	action := b.blacklistAction
+	b.mu.Unlock()
+	msg := fmt.Sprintf("[Entry] Entity %s at (%d,%d) blacklisted after %d clicks, action: %s",
+		entity.TemplateName, entity.Position.X, entity.Position.Y, clicks, action)
+
+	switch action {
+	case BlacklistScroll:
+		b.logFunc(msg)
+		// Scrolling drops every tracked entity (positions are stale), the blacklist too
+		b.scrollEntryList(b.scanRegion("entry", screenImg), "Blacklisted entry")
+	case BlacklistNotify, BlacklistStop:
+		if b.errorFunc != nil {
+			b.errorFunc(msg)
+		} else {
+			b.logFunc(msg)
+		}
+		if b.notifyFunc != nil {
+			b.notifyFunc("Entry blacklisted", msg)
+		}
+		if action == BlacklistStop {
+			b.logFunc("Auto-stopping due to a blacklisted entry.")
+			b.autoStop()
+			return true
+		}
+	default:
+		b.logFunc(msg)
+	}
+	return false
+}
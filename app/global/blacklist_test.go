@@ -0,0 +1,54 @@
+package global
+
+import (
+	"image"
+	"testing"
+)
+
+func TestOnBlacklisted(t *testing.T) {
+	tests := []struct {
+		action              BlacklistAction
+		scroll, alert, stop bool
+	}{
+		{action: BlacklistSkip},
+		{action: BlacklistScroll, scroll: true},
+		{action: BlacklistNotify, alert: true},
+		{action: BlacklistStop, alert: true, stop: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action.String(), func(t *testing.T) {
+			frame := newScreen(200, 200)
+			b, _, actions := newFrameBot(t, frame)
+			var errs, notes []string
+			b.SetAlertFuncs(
+				func(msg string) { errs = append(errs, msg) },
+				func(title, msg string) { notes = append(notes, title) },
+				nil,
+			)
+			b.SetBlacklistAction(tt.action)
+			startTestStep(b, StateEntry) // autoStop then ends the step instead of stopping a loop
+			entity := entityAt(20, 60, 80)
+			b.entryTracker.RecordClick(entity)
+
+			stopping := b.onBlacklisted(frame, entity, 7)
+
+			if scrolled := len(actions.scrolls) > 0; scrolled != tt.scroll {
+				t.Errorf("scrolled %v, want %v", actions.scrolls, tt.scroll)
+			}
+			if tt.scroll {
+				if tracked, _ := b.entryTracker.Stats(); tracked != 0 {
+					t.Errorf("%d entities still tracked after scrolling, want the tracker reset", tracked)
+				}
+				if want := (image.Point{X: 100, Y: 100}); actions.scrolls[0] != want {
+					t.Errorf("scrolled at %v, want the frame center %v", actions.scrolls[0], want)
+				}
+			}
+			if alerted := len(errs) == 1 && len(notes) == 1; alerted != tt.alert {
+				t.Errorf("errors %q, notifications %q; want alerted %v", errs, notes, tt.alert)
+			}
+			if stopping != tt.stop || b.stopping != tt.stop {
+				t.Errorf("onBlacklisted returned %v, bot stopping %v; want %v", stopping, b.stopping, tt.stop)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package global
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/window"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+// ClickFunc observes every click (dry runs included) at the global point it lands on.
+// It runs on the bot goroutine.
+type ClickFunc func(name string, at image.Point)
+
+// SetClickFunc sets the callback that observes clicks (nil = off)
+func (b *GlobalBot) SetClickFunc(f ClickFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clickFunc = f
+}
+
+// reportClick passes a click to the click observer, if any
+func (b *GlobalBot) reportClick(name string, at image.Point) {
+	b.mu.Lock()
+	f := b.clickFunc
+	b.mu.Unlock()
+	if f != nil {
+		f(name, at)
+	}
+}
+
+// clickMarker flashes a ring where each click lands, in a click-through window above
+// the game that fades out over constants.ClickMarkerDuration. The window is created
+// on the first click and then stays open (faded out) so later flashes never take
+// focus from the game. Fields are only touched on the main goroutine.
+type clickMarker struct {
+	win           fyne.Window
+	gen           int  // Bumped per flash, so a newer click cuts an older fade short
+	unsupported   bool // The platform cannot place overlays
+	onUnsupported func()
+}
+
+// newClickMarker returns a marker; onUnsupported is called once if the platform
+// cannot show it
+func newClickMarker(onUnsupported func()) *clickMarker {
+	return &clickMarker{onUnsupported: onUnsupported}
+}
+
+// Flash shows the marker centered on at (global coordinates). Safe from any goroutine.
+func (m *clickMarker) Flash(at image.Point) {
+	fyne.Do(func() {
+		if m.unsupported {
+			return
+		}
+		if m.win == nil && !m.open() {
+			m.disable()
+			return
+		}
+		half := constants.ClickMarkerSize / 2
+		if !window.PlaceOverlay(m.win, image.Rect(at.X-half, at.Y-half, at.X+half, at.Y+half)) {
+			m.disable()
+			return
+		}
+		window.SetOverlayAlpha(m.win, 1)
+		m.gen++
+		go m.fade(m.gen)
+	})
+}
+
+// Close removes the marker window. Main goroutine only.
+func (m *clickMarker) Close() {
+	if m.win != nil {
+		m.win.Close()
+		m.win = nil
+	}
+}
+
+// open creates and shows the borderless marker window
+func (m *clickMarker) open() bool {
+	drv, ok := fyne.CurrentApp().Driver().(desktop.Driver)
+	if !ok {
+		return false
+	}
+	ring := canvas.NewCircle(color.Transparent)
+	ring.StrokeColor = color.RGBA{R: 255, G: 40, B: 40, A: 255}
+	ring.StrokeWidth = 3
+
+	m.win = drv.CreateSplashWindow()
+	m.win.SetPadded(false)
+	m.win.SetContent(container.NewStack(canvas.NewRectangle(window.OverlayBackground), ring))
+	m.win.Resize(fyne.NewSize(constants.ClickMarkerSize, constants.ClickMarkerSize))
+	m.win.Show()
+	return true
+}
+
+// disable closes the marker for good and reports that the platform lacks overlays
+func (m *clickMarker) disable() {
+	m.Close()
+	m.unsupported = true
+	if m.onUnsupported != nil {
+		m.onUnsupported()
+	}
+}
+
+// fade steps the marker's opacity down to zero, unless a newer flash took over
+func (m *clickMarker) fade(gen int) {
+	step := constants.ClickMarkerDuration / constants.ClickMarkerFadeSteps
+	for i := constants.ClickMarkerFadeSteps - 1; i >= 0; i-- {
+		time.Sleep(step)
+		alpha := float64(i) / constants.ClickMarkerFadeSteps
+		stale := false
+		fyne.DoAndWait(func() {
+			if stale = m.gen != gen || m.win == nil; !stale {
+				window.SetOverlayAlpha(m.win, alpha)
+			}
+		})
+		if stale {
+			return
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package global
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+// ConfirmFunc asks the user to confirm the first real input of a run.
+// at is the global point about to be clicked, preview is the captured area around it
+// (centered on at). reply must be called exactly once, from any goroutine.
+type ConfirmFunc func(name string, at image.Point, preview image.Image, reply func(ok bool))
+
+// SetConfirmFunc sets the callback used by the confirm-first-click gate
+func (b *GlobalBot) SetConfirmFunc(f ConfirmFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.confirmFunc = f
+}
+
+// SetConfirmFirstClick turns the confirm-first-click gate on or off (applies from the next Start)
+func (b *GlobalBot) SetConfirmFirstClick(enabled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg.ConfirmFirstClick = enabled
+}
+
+// confirmInput gates the first real input of a run behind a user confirmation.
+// (x, y) is the display-local point about to be clicked. It blocks the loop until
+// the user answers or the bot is stopped, and returns false if the input must be skipped.
+// Dry runs never click, so they are not gated.
+func (b *GlobalBot) confirmInput(name string, x, y int) bool {
+	if b.inputConfirmed || b.tick.DryRun || !b.tick.ConfirmFirstClick || b.confirmFunc == nil {
+		return true
+	}
+
+	at := image.Point{X: x + b.displayOffsetX, Y: y + b.displayOffsetY}
+	var preview image.Image
+	if frame, err := b.searcher.Frame(); err == nil {
+		r := image.Rect(x, y, x, y).Inset(-constants.ConfirmPreviewRadius).Intersect(frame.Bounds())
+		if sub, ok := frame.(interface {
+			SubImage(image.Rectangle) image.Image
+		}); ok && !r.Empty() {
+			preview = sub.SubImage(r)
+		}
+	}
+
+	b.logFunc(fmt.Sprintf("About to click [%s] at (%d, %d). Waiting for confirmation...", name, at.X, at.Y))
+	reply := make(chan bool, 1)
+	b.confirmFunc(name, at, preview, func(ok bool) { reply <- ok })
+
+	select {
+	case ok := <-reply:
+		if !ok {
+			b.logFunc("First click declined. Stopping.")
+			b.autoStop()
+			return false
+		}
+		b.logFunc("First click confirmed.")
+		b.inputConfirmed = true
+		return true
+	case <-b.stopChan:
+		return false
+	}
+}
@@ -0,0 +1,149 @@
+package global
+
+import (
+	"archive/zip"
+	"fmt"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+	"github.com/ConserveLee/gui-idle/internal/logger"
+)
+
+// diagnosticFiles are copied into the diagnostics bundle when present: {name in the zip, path}
+var diagnosticFiles = [][2]string{
+	{"logs/gamebot.log", logger.LogPath},
+	{"logs/summary.log", SummaryLogPath},
+	{"logs/clicks.csv", ClickLogPath},
+	{"debug_entry_screen.png", "debug_entry_screen.png"}, // Saved on the first empty entry scan
+}
+
+// WriteDiagnostics zips what a maintainer needs to reproduce an issue into w:
+// the session and summary logs, the settings file at cfgPath, a capture of the
+// game display, and report.txt (version, OS, displays, loaded assets, state history).
+// Missing files are skipped. It returns the names written to the zip.
+func (b *GlobalBot) WriteDiagnostics(w io.Writer, cfgPath string) ([]string, error) {
+	zw := zip.NewWriter(w)
+	var written []string
+
+	add := func(name string, write func(io.Writer) error) error {
+		entry, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if err := write(entry); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		written = append(written, name)
+		return nil
+	}
+	addFile := func(name, path string) error {
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return add(name, func(w io.Writer) error {
+			_, err := io.Copy(w, f)
+			return err
+		})
+	}
+
+	if err := add("report.txt", func(w io.Writer) error {
+		_, err := io.WriteString(w, b.diagnosticReport())
+		return err
+	}); err != nil {
+		zw.Close()
+		return written, err
+	}
+
+	files := append([][2]string{{"settings/" + filepath.Base(cfgPath), cfgPath}}, diagnosticFiles...)
+	for _, f := range files {
+		if err := addFile(f[0], f[1]); err != nil {
+			zw.Close()
+			return written, err
+		}
+	}
+
+	// The game display as it looks now
+	b.mu.Lock()
+	display := b.searcher.DisplayIndex()
+	b.mu.Unlock()
+	name := fmt.Sprintf("frames/display_%d.png", display)
+	frame, err := screen.CaptureDisplay(display)
+	if err != nil {
+		name = fmt.Sprintf("frames/display_%d_error.txt", display)
+	}
+	if err := add(name, func(w io.Writer) error {
+		if err != nil {
+			_, werr := fmt.Fprintf(w, "capture failed: %v\n", err)
+			return werr
+		}
+		return png.Encode(w, frame)
+	}); err != nil {
+		zw.Close()
+		return written, err
+	}
+
+	return written, zw.Close()
+}
+
+// diagnosticReport describes the build, the machine and the bot's current state
+func (b *GlobalBot) diagnosticReport() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Generated: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "Version: %s\n", appVersion())
+	fmt.Fprintf(&sb, "Go: %s %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+
+	sb.WriteString("\nDisplays:\n")
+	for i, bounds := range screen.ActiveDisplays() {
+		fmt.Fprintf(&sb, "  %d: %s\n", i, screen.DisplayID(bounds))
+	}
+
+	b.mu.Lock()
+	fmt.Fprintf(&sb, "\nState: %s\n", b.State)
+	fmt.Fprintf(&sb, "Display: %d (id %q)\n", b.searcher.DisplayIndex(), b.cfg.DisplayID)
+	fmt.Fprintf(&sb, "Assets: %s\n", b.AssetsDir)
+	fmt.Fprintf(&sb, "Loaded Assets: Games=%d, Finding=%d, Anti=%d, Lobby=%d, Skill=%d, Exit=%d, Channel(return/open/select)=%d/%d/%d\n",
+		len(b.targetsGames), len(b.targetsFinding), len(b.targetsAnti), len(b.targetsLobby),
+		len(b.targetsSkill), len(b.targetsExit),
+		len(b.targetsChannelReturn), len(b.targetsChannelOpen), len(b.targetsChannelSelect))
+	b.mu.Unlock()
+
+	fmt.Fprintf(&sb, "\nRun:\n%s\n", b.RunSummary())
+
+	sb.WriteString("\nState History:\n")
+	for _, t := range b.StateHistory() {
+		fmt.Fprintf(&sb, "  %s\n", t)
+	}
+	return sb.String()
+}
+
+// appVersion returns the module version and VCS revision stamped into the binary
+func appVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	version := info.Main.Version
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			version += " " + s.Value
+		case "vcs.modified":
+			if s.Value == "true" {
+				version += " (modified)"
+			}
+		}
+	}
+	return version
+}
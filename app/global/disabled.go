@@ -0,0 +1,47 @@
+package global
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DisabledPrefix marks a template as disabled: "_20.png" stays in its directory
+// but is skipped on load, so it can be re-enabled later by renaming it back.
+const DisabledPrefix = "_"
+
+// IsDisabled reports whether the template file is disabled (see ParseTargetName)
+func IsDisabled(pngPath string) bool {
+	return !ParseTargetName(pngPath).Enabled
+}
+
+// SetTemplateEnabled enables or disables a template by renaming it (and its sidecar).
+// Returns the new PNG path. Refuses to overwrite an existing file.
+func SetTemplateEnabled(pngPath string, enabled bool) (string, error) {
+	if IsDisabled(pngPath) != enabled {
+		return pngPath, nil // Already in the requested state
+	}
+
+	dir, base := filepath.Split(pngPath)
+	newBase := DisabledPrefix + base
+	if enabled {
+		newBase = strings.TrimPrefix(base, DisabledPrefix)
+	}
+	newPath := filepath.Join(dir, newBase)
+
+	if _, err := os.Stat(newPath); err == nil {
+		return pngPath, fmt.Errorf("%s already exists", newPath)
+	}
+	if err := os.Rename(pngPath, newPath); err != nil {
+		return pngPath, err
+	}
+
+	// Keep the sidecar next to its template
+	if _, err := os.Stat(SidecarPath(pngPath)); err == nil {
+		if err := os.Rename(SidecarPath(pngPath), SidecarPath(newPath)); err != nil {
+			return newPath, err
+		}
+	}
+	return newPath, nil
+}
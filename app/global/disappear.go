@@ -0,0 +1,46 @@
+package global
+
+import "time"
+
+// WaitResult is the outcome of a DisappearWait check
+type WaitResult int
+
+const (
+	WaitPending  WaitResult = iota // Template still visible, keep polling
+	WaitGone                       // Template disappeared
+	WaitTimedOut                   // Template still visible after the timeout
+)
+
+// DisappearWait waits for a template to disappear (e.g. the lobby screen after the game starts).
+// It does not block: the state handler calls Check once per tick and returns Poll as its interval.
+type DisappearWait struct {
+	Poll    time.Duration // Interval between checks
+	Timeout time.Duration // Give up after this long
+	started time.Time
+}
+
+// NewDisappearWait creates a wait with the given poll interval and timeout
+func NewDisappearWait(poll, timeout time.Duration) *DisappearWait {
+	return &DisappearWait{Poll: poll, Timeout: timeout}
+}
+
+// Start (re)starts the timeout clock
+func (w *DisappearWait) Start(now time.Time) {
+	w.started = now
+}
+
+// Elapsed returns how long the wait has been running
+func (w *DisappearWait) Elapsed(now time.Time) time.Duration {
+	return now.Sub(w.started)
+}
+
+// Check reports whether the template is gone, still pending, or timed out
+func (w *DisappearWait) Check(visible bool, now time.Time) WaitResult {
+	if !visible {
+		return WaitGone
+	}
+	if w.Elapsed(now) >= w.Timeout {
+		return WaitTimedOut
+	}
+	return WaitPending
+}
@@ -0,0 +1,145 @@
+package global
+
+import (
+	"image"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/config"
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+func TestDisappearWait(t *testing.T) {
+	start := time.Now()
+	w := NewDisappearWait(2*time.Second, 10*time.Second)
+	w.Start(start)
+
+	if got := w.Check(true, start.Add(2*time.Second)); got != WaitPending {
+		t.Errorf("visible before the timeout: %v, want WaitPending", got)
+	}
+	if got := w.Check(false, start.Add(4*time.Second)); got != WaitGone {
+		t.Errorf("gone: %v, want WaitGone", got)
+	}
+	if got := w.Check(true, start.Add(10*time.Second)); got != WaitTimedOut {
+		t.Errorf("visible at the timeout: %v, want WaitTimedOut", got)
+	}
+	if got := w.Elapsed(start.Add(3 * time.Second)); got != 3*time.Second {
+		t.Errorf("Elapsed = %v, want 3s", got)
+	}
+}
+
+func TestLobbyWaitUntilGone(t *testing.T) {
+	lobby := newTemplate(20, 20, 0)
+	withLobby := newScreen(200, 200)
+	paste(withLobby, lobby, 50, 50)
+
+	b, src, _ := newFrameBot(t, withLobby)
+	b.targetsLobby = []Target{{Name: "lobby.png", Image: lobby}}
+	b.cfg.LobbyPollInterval = config.Duration(2 * time.Second)
+	b.cfg.LobbyTimeout = config.Duration(time.Minute)
+	b.State = StateEntryWaiting
+	b.lobbyWait.Start(time.Now())
+
+	// The lobby stays up for a few polls, each returning the poll interval
+	const polls = 3
+	for i := 1; i <= polls; i++ {
+		if got := runTick(b); got != 2*time.Second {
+			t.Fatalf("poll %d: interval %v, want the 2s poll interval", i, got)
+		}
+		if b.State != StateEntryWaiting {
+			t.Fatalf("poll %d: state %s while the lobby is visible", i, b.State)
+		}
+	}
+
+	// Then the game starts
+	src.frame = newScreen(200, 200)
+	if got := runTick(b); got != b.tick.InGameScanInterval.D() {
+		t.Errorf("interval after the lobby went: %v, want the in-game interval", got)
+	}
+	if b.State != StateInGame {
+		t.Errorf("state %s after the lobby went, want InGame", b.State)
+	}
+	if src.captures != polls+1 {
+		t.Errorf("%d captures, want one per poll (%d)", src.captures, polls+1)
+	}
+}
+
+func TestLobbyWaitTimesOut(t *testing.T) {
+	lobby := newTemplate(20, 20, 0)
+	ret := newTemplate(20, 20, 90)
+	frame := newScreen(200, 200)
+	paste(frame, lobby, 50, 50)
+	paste(frame, ret, 120, 150)
+
+	b, _, actions := newFrameBot(t, frame)
+	b.targetsLobby = []Target{{Name: "lobby.png", Image: lobby}}
+	b.targetsChannelReturn = []Target{{Name: "return.png", Image: ret}}
+	b.cfg.LobbyPollInterval = config.Duration(2 * time.Second)
+	b.cfg.LobbyTimeout = config.Duration(30 * time.Second)
+	b.State = StateEntryWaiting
+	b.lobbyWait.Start(time.Now().Add(-30 * time.Second))
+
+	runTick(b)
+	if b.State != StateSearchOpen {
+		t.Errorf("state %s after the timeout, want SearchOpen", b.State)
+	}
+	if b.stats.Timeouts != 1 {
+		t.Errorf("Timeouts = %d, want 1", b.stats.Timeouts)
+	}
+	// return.png is clicked at its center to leave the lobby
+	if len(actions.clicks) != 1 || actions.clicks[0] != (image.Point{X: 130, Y: 160}) {
+		t.Errorf("clicks = %v, want one on return.png at (130, 160)", actions.clicks)
+	}
+}
+
+func TestLobbyWaitIdles(t *testing.T) {
+	lobby := newTemplate(20, 20, 0)
+	frame := newScreen(200, 200)
+	paste(frame, lobby, 50, 50)
+
+	b, src, _ := newFrameBot(t, frame)
+	var logs []string
+	b.logFunc = func(msg string) { logs = append(logs, msg) }
+	b.targetsLobby = []Target{{Name: "lobby.png", Image: lobby}}
+	b.cfg.LobbyPollInterval = config.Duration(2 * time.Second)
+	b.cfg.LobbyTimeout = config.Duration(time.Minute)
+	b.State = StateEntryWaiting
+	b.lobbyWait.Start(time.Now())
+
+	// The same frame again and again: idle after constants.IdleFrames unchanged frames
+	idle := 2 * time.Second * constants.IdleIntervalFactor
+	for i := 0; i <= constants.IdleFrames; i++ {
+		want := 2 * time.Second
+		if i == constants.IdleFrames {
+			want = idle
+		}
+		if got := runTick(b); got != want {
+			t.Fatalf("identical frame %d: interval %v, want %v", i+1, got, want)
+		}
+	}
+	if !strings.Contains(logs[len(logs)-1], "entering idle") {
+		t.Errorf("last log %q, want entering idle", logs[len(logs)-1])
+	}
+
+	// Something moves: back to the poll interval
+	changed := newScreen(200, 200)
+	paste(changed, lobby, 50, 50)
+	paste(changed, newTemplate(40, 40, 90), 120, 120)
+	src.frame = changed
+	if got := runTick(b); got != 2*time.Second {
+		t.Errorf("changed frame: interval %v, want the 2s poll", got)
+	}
+	if !strings.Contains(logs[len(logs)-1], "leaving idle") {
+		t.Errorf("last log %q, want leaving idle", logs[len(logs)-1])
+	}
+
+	// Idle again, but never waiting past the lobby timeout
+	for i := 0; i < constants.IdleFrames; i++ {
+		runTick(b)
+	}
+	b.lobbyWait.Start(time.Now().Add(-56 * time.Second)) // About 4s left
+	if got := runTick(b); got <= 2*time.Second || got > 4*time.Second {
+		t.Errorf("idle interval near the timeout: %v, want it cut to the ~4s left", got)
+	}
+}
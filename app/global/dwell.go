@@ -0,0 +1,64 @@
+package global
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/config"
+)
+
+// pendingTransition is a transition held back until the current state's dwell time has passed
+type pendingTransition struct {
+	to      BotState
+	trigger string
+}
+
+// ParseBotState maps a state name (as printed in the history, e.g. "AutoDetect") to its BotState
+func ParseBotState(name string) (BotState, error) {
+	for s, n := range stateNames {
+		if n == name {
+			return s, nil
+		}
+	}
+	return StateStopped, fmt.Errorf("unknown state %q", name)
+}
+
+// parseStateDwell converts the configured per-state dwell times, skipping unknown state names
+func (b *GlobalBot) parseStateDwell(cfg map[string]config.Duration) map[BotState]time.Duration {
+	dwell := make(map[BotState]time.Duration, len(cfg))
+	for name, d := range cfg {
+		s, err := ParseBotState(name)
+		if err != nil {
+			b.logFunc(fmt.Sprintf("Config state_dwell ignored: %v", err))
+			continue
+		}
+		dwell[s] = d.D()
+	}
+	return dwell
+}
+
+// dwellRemaining returns how much longer the current state must stay active
+// before a transition out of it is allowed. Caller holds b.mu.
+func (b *GlobalBot) dwellRemaining(now time.Time) time.Duration {
+	dwell := b.stateDwell[b.State]
+	if dwell <= 0 {
+		return 0
+	}
+	return max(dwell-now.Sub(b.stateEntered), 0)
+}
+
+// applyPending performs a held-back transition once the dwell time has passed.
+// It returns false and the remaining wait while the transition is still held back.
+func (b *GlobalBot) applyPending() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending == nil {
+		return 0, true
+	}
+	if remaining := b.dwellRemaining(time.Now()); remaining > 0 {
+		return remaining, false
+	}
+	p := b.pending
+	b.transition(p.to, p.trigger+" (after dwell)")
+	return 0, true
+}
@@ -0,0 +1,45 @@
+package global
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/config"
+)
+
+func TestDwellDefersTransition(t *testing.T) {
+	b := newTestBot()
+	b.stateDwell = b.parseStateDwell(map[string]config.Duration{"AutoDetect": config.Duration(time.Minute)})
+	b.State = StateAutoDetect
+	b.stateEntered = time.Now()
+
+	// Too soon after entering AutoDetect: held back
+	b.setState(StateEntry, "games found")
+	if b.State != StateAutoDetect || b.pending == nil {
+		t.Fatalf("state %s, pending %v; want the transition held back", b.State, b.pending)
+	}
+	wait, ok := b.applyPending()
+	if ok || wait <= 0 || wait > time.Minute {
+		t.Fatalf("applyPending = %v, %v during the dwell; want the remaining dwell, false", wait, ok)
+	}
+
+	// Once the dwell has passed the loop applies it
+	b.stateEntered = time.Now().Add(-time.Minute)
+	if _, ok := b.applyPending(); !ok {
+		t.Fatal("applyPending still holding after the dwell")
+	}
+	if b.State != StateEntry || b.pending != nil {
+		t.Errorf("state %s, pending %v after the dwell; want Entry, none", b.State, b.pending)
+	}
+	history := b.StateHistory()
+	if last := history[len(history)-1]; !strings.Contains(last.Trigger, "after dwell") {
+		t.Errorf("last transition %q, want it marked as applied after the dwell", last.Trigger)
+	}
+
+	// States without a dwell switch at once
+	b.setState(StateEntryVerify, "clicked")
+	if b.State != StateEntryVerify {
+		t.Errorf("state %s, want EntryVerify immediately", b.State)
+	}
+}
@@ -1,8 +1,8 @@
 package global
 
 import (
+	"fmt"
 	"image"
-	"regexp"
 	"sort"
 	"strconv"
 	"sync"
@@ -11,10 +11,11 @@ import (
 
 // DetectedEntity represents an entry button detected on screen
 type DetectedEntity struct {
-	TemplateName string      // Template filename (e.g., "20.png")
-	Priority     int         // Number extracted from filename (e.g., 20)
-	Position     image.Point // Top-left position on screen
-	TemplateSize image.Point // Template dimensions (for center calculation)
+	TemplateName string          // Template filename (e.g., "20.png")
+	Priority     int             // Number extracted from filename (e.g., 20)
+	Position     image.Point     // Top-left position on screen
+	TemplateSize image.Point     // Template dimensions (for center calculation)
+	Region       image.Rectangle // Visible part of a partially matched button (empty = whole template)
 }
 
 // TrackedEntity wraps DetectedEntity with tracking metadata
@@ -33,10 +34,12 @@ type EntityTracker struct {
 	maxClicks      int                       // Max clicks before blacklisting (default: 7)
 	positionThresh int                       // Position matching threshold in pixels (default: 20)
 	ttl            time.Duration             // Time-to-live for entities (default: 2s)
+	maxAge         time.Duration             // Tracked longer than this: dropped and re-detected fresh (0 = never)
+	now            func() time.Time          // Clock (time.Now; injectable for replays and checks)
 
 	// ROI (Region of Interest) for fast detection
 	lastHighPriEntity *DetectedEntity // Last detected high priority entity
-	roiMargin         int             // Margin around last position for ROI (default: 100px)
+	roiMargin         ROIMargin       // Margin around last position for ROI (default: 100px each side)
 
 	// Debug callback
 	debugFunc func(string, ...interface{})
@@ -50,11 +53,48 @@ func NewEntityTracker() *EntityTracker {
 		maxClicks:      7,
 		positionThresh: 20,
 		ttl:            2 * time.Second,
-		roiMargin:      100, // 100px margin around last high priority entity
+		now:            time.Now,
+		roiMargin:      UniformROIMargin(100), // 100px margin around last high priority entity
 		debugFunc:      func(string, ...interface{}) {}, // No-op by default
 	}
 }
 
+// ROIMargin is the margin (px) added on each side of the last entity to form the ROI.
+// A larger Up margin follows lists that scroll entities upward (see findMovedEntity).
+type ROIMargin struct {
+	Up, Down, Left, Right int
+}
+
+// UniformROIMargin returns a margin of m pixels on every side
+func UniformROIMargin(m int) ROIMargin {
+	return ROIMargin{Up: m, Down: m, Left: m, Right: m}
+}
+
+// SetROIMargin sets the margin used by GetROI
+func (t *EntityTracker) SetROIMargin(m ROIMargin) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.roiMargin = m
+}
+
+// SetMaxAge sets how long an entity may be tracked before it is dropped and
+// re-detected fresh, even if still seen, so drifted state is not acted on (0 = never)
+func (t *EntityTracker) SetMaxAge(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maxAge = d
+}
+
+// SetClock replaces the tracker's clock (nil restores time.Now)
+func (t *EntityTracker) SetClock(now func() time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if now == nil {
+		now = time.Now
+	}
+	t.now = now
+}
+
 // SetDebugFunc sets the debug logging function
 func (t *EntityTracker) SetDebugFunc(f func(string, ...interface{})) {
 	t.debugFunc = f
@@ -73,13 +113,26 @@ func (t *EntityTracker) entityKey(e DetectedEntity) string {
 // - Adds new entities
 // - Removes expired entities (not seen for TTL duration)
 // - Handles Y-axis movement (entities moving up in the list)
+// - Drops entities tracked longer than the max age, so they are re-created fresh
 func (t *EntityTracker) Update(detected []DetectedEntity) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	now := time.Now()
+	now := t.now()
 	seen := make(map[string]bool)
 
+	// Forced re-evaluation: long-tracked entities start over (click count included)
+	if t.maxAge > 0 {
+		for key, tracked := range t.entities {
+			if age := now.Sub(tracked.FirstSeen); age > t.maxAge {
+				t.debugFunc("[Tracker] Re-evaluating %s at (%d,%d) key=%s: tracked %v (max age %v), clicks=%d",
+					tracked.Entity.TemplateName, tracked.Entity.Position.X, tracked.Entity.Position.Y,
+					key, age.Round(time.Millisecond), t.maxAge, tracked.ClickCount)
+				delete(t.entities, key)
+			}
+		}
+	}
+
 	// First pass: try to match detected entities with existing tracked entities
 	for _, d := range detected {
 		key := t.entityKey(d)
@@ -209,8 +262,8 @@ func (t *EntityTracker) RecordClick(e DetectedEntity) bool {
 		tracked = &TrackedEntity{
 			Entity:     e,
 			ClickCount: 0,
-			FirstSeen:  time.Now(),
-			LastSeen:   time.Now(),
+			FirstSeen:  t.now(),
+			LastSeen:   t.now(),
 		}
 		t.entities[key] = tracked
 	}
@@ -219,7 +272,7 @@ func (t *EntityTracker) RecordClick(e DetectedEntity) bool {
 
 	// Blacklist if max clicks reached
 	if tracked.ClickCount >= t.maxClicks {
-		t.blacklist[key] = time.Now()
+		t.blacklist[key] = t.now()
 		return true
 	}
 
@@ -253,6 +306,24 @@ func (t *EntityTracker) FilterBlacklisted(entities []DetectedEntity) []DetectedE
 	return result
 }
 
+// ExpireBlacklist removes blacklist entries older than maxAge and resets their click counts,
+// so those entities can be retried. Returns the number of entries removed.
+func (t *EntityTracker) ExpireBlacklist(maxAge time.Duration) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	expired := 0
+	now := t.now()
+	for key, since := range t.blacklist {
+		if now.Sub(since) > maxAge {
+			delete(t.blacklist, key)
+			delete(t.entities, key)
+			expired++
+		}
+	}
+	return expired
+}
+
 // Reset clears all tracked entities and blacklist (call when entering new game cycle)
 func (t *EntityTracker) Reset() {
 	t.mu.Lock()
@@ -291,14 +362,15 @@ func (t *EntityTracker) GetROI() image.Rectangle {
 	margin := t.roiMargin
 
 	// Create ROI around the entity position with margin
+	// (may extend past the screen; FindAllTemplatesInROI clamps it)
 	return image.Rectangle{
 		Min: image.Point{
-			X: e.Position.X - margin,
-			Y: e.Position.Y - margin,
+			X: e.Position.X - margin.Left,
+			Y: e.Position.Y - margin.Up,
 		},
 		Max: image.Point{
-			X: e.Position.X + e.TemplateSize.X + margin,
-			Y: e.Position.Y + e.TemplateSize.Y + margin,
+			X: e.Position.X + e.TemplateSize.X + margin.Right,
+			Y: e.Position.Y + e.TemplateSize.Y + margin.Down,
 		},
 	}
 }
@@ -310,34 +382,107 @@ func (t *EntityTracker) HasROI() bool {
 	return t.lastHighPriEntity != nil
 }
 
-// ExtractPriority extracts the priority number from a filename like "20.png" or "20-1.png"
-func ExtractPriority(filename string) int {
-	re := regexp.MustCompile(`^(\d+)`)
-	matches := re.FindStringSubmatch(filename)
-	if len(matches) > 1 {
-		if num, err := strconv.Atoi(matches[1]); err == nil {
-			return num
-		}
+
+// SortOrder selects which entry priority is tried first
+type SortOrder int
+
+const (
+	HighestFirst SortOrder = iota // Higher numbers first (default)
+	LowestFirst                   // Lower numbers first (farm low-value entries)
+)
+
+func (o SortOrder) String() string {
+	switch o {
+	case HighestFirst:
+		return "highest_first"
+	case LowestFirst:
+		return "lowest_first"
+	default:
+		return fmt.Sprintf("SortOrder(%d)", int(o))
+	}
+}
+
+// ParseSortOrder converts a config name ("highest_first", "lowest_first") to a SortOrder.
+// An empty string means HighestFirst.
+func ParseSortOrder(name string) (SortOrder, error) {
+	switch name {
+	case "", "highest_first":
+		return HighestFirst, nil
+	case "lowest_first":
+		return LowestFirst, nil
+	default:
+		return HighestFirst, fmt.Errorf("unknown sort order %q", name)
 	}
-	return 0
 }
 
 // SortEntitiesByPriority sorts entities by:
-// 1. Priority (higher number first)
+// 1. Priority (higher number first, or lower first with LowestFirst)
 // 2. Y coordinate (lower on screen first, i.e., higher Y value)
-func SortEntitiesByPriority(entities []DetectedEntity) {
+func SortEntitiesByPriority(entities []DetectedEntity, order SortOrder) {
 	sort.Slice(entities, func(i, j int) bool {
 		if entities[i].Priority != entities[j].Priority {
+			if order == LowestFirst {
+				return entities[i].Priority < entities[j].Priority
+			}
 			return entities[i].Priority > entities[j].Priority // Higher priority first
 		}
 		return entities[i].Position.Y > entities[j].Position.Y // Lower on screen first
 	})
 }
 
+// DedupEntities collapses same-priority entities whose centers are within half a
+// template of each other (e.g. "20.png" and "20-1.png" matching the same button).
+// Of each overlapping group the match with the larger template is kept, since it
+// verified more pixels; on a tie the first (template load order) wins.
+func DedupEntities(entities []DetectedEntity) []DetectedEntity {
+	if len(entities) < 2 {
+		return entities
+	}
+
+	kept := make([]DetectedEntity, 0, len(entities))
+	for _, e := range entities {
+		duplicate := false
+		for i, k := range kept {
+			if k.Priority != e.Priority {
+				continue
+			}
+			ec, kc := e.Center(), k.Center()
+			dx := min(e.TemplateSize.X, k.TemplateSize.X) / 2
+			dy := min(e.TemplateSize.Y, k.TemplateSize.Y) / 2
+			if abs(ec.X-kc.X) > dx || abs(ec.Y-kc.Y) > dy {
+				continue
+			}
+			duplicate = true
+			if e.TemplateSize.X*e.TemplateSize.Y > k.TemplateSize.X*k.TemplateSize.Y {
+				kept[i] = e
+			}
+			break
+		}
+		if !duplicate {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
 // Center returns the center point of the entity for clicking
+// (of the visible part, for a partial match)
 func (e *DetectedEntity) Center() image.Point {
+	if !e.Region.Empty() {
+		return image.Point{X: (e.Region.Min.X + e.Region.Max.X) / 2, Y: (e.Region.Min.Y + e.Region.Max.Y) / 2}
+	}
 	return image.Point{
 		X: e.Position.X + e.TemplateSize.X/2,
 		Y: e.Position.Y + e.TemplateSize.Y/2,
 	}
 }
+
+// containsEntity reports whether entities holds e (same template at the same position)
+func containsEntity(entities []DetectedEntity, e DetectedEntity) bool {
+	for _, x := range entities {
+		if x == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package global
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+)
+
+func entityAt(priority, x, y int) DetectedEntity {
+	return DetectedEntity{Priority: priority, Position: image.Point{X: x, Y: y}, TemplateSize: image.Point{X: 20, Y: 20}}
+}
+
+func TestSortEntitiesByPriority(t *testing.T) {
+	tests := []struct {
+		order SortOrder
+		want  []DetectedEntity
+	}{
+		{HighestFirst, []DetectedEntity{entityAt(20, 0, 300), entityAt(20, 0, 100), entityAt(10, 0, 200), entityAt(5, 0, 50)}},
+		{LowestFirst, []DetectedEntity{entityAt(5, 0, 50), entityAt(10, 0, 200), entityAt(20, 0, 300), entityAt(20, 0, 100)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order.String(), func(t *testing.T) {
+			// Within a priority, lower on screen (higher Y) comes first either way
+			entities := []DetectedEntity{entityAt(10, 0, 200), entityAt(20, 0, 100), entityAt(5, 0, 50), entityAt(20, 0, 300)}
+			SortEntitiesByPriority(entities, tt.order)
+			for i := range tt.want {
+				if entities[i] != tt.want[i] {
+					t.Fatalf("sorted = %v, want %v", entities, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetROI(t *testing.T) {
+	tracker := NewEntityTracker()
+	if roi := tracker.GetROI(); !roi.Empty() {
+		t.Fatalf("ROI before any entity = %v, want empty", roi)
+	}
+
+	// Larger upward margin follows a list scrolling up
+	tracker.SetROIMargin(ROIMargin{Up: 150, Down: 20, Left: 30, Right: 40})
+	tracker.SetLastHighPriority(entityAt(20, 100, 200))
+	want := image.Rect(100-30, 200-150, 100+20+40, 200+20+20)
+	if roi := tracker.GetROI(); roi != want {
+		t.Errorf("GetROI = %v, want %v", roi, want)
+	}
+}
+
+func TestGetROIClampedToScreen(t *testing.T) {
+	// An entity at the top-left corner: the ROI extends past the screen
+	tracker := NewEntityTracker()
+	tracker.SetROIMargin(ROIMargin{Up: 150, Down: 20, Left: 30, Right: 40})
+	tracker.SetLastHighPriority(entityAt(20, 0, 10))
+	roi := tracker.GetROI()
+	if roi.Min.X >= 0 || roi.Min.Y >= 0 {
+		t.Fatalf("GetROI = %v, want it to extend past the top-left corner", roi)
+	}
+
+	// The scan clamps it to the screen and still finds the entity
+	tpl := newTemplate(20, 20, 0)
+	scr := newScreen(200, 200)
+	paste(scr, tpl, 0, 10)
+	points := screen.NewSearcher().FindAllTemplatesInROI(scr, tpl, roi, constants.DefaultTolerance)
+	if len(points) != 1 || points[0] != (image.Point{X: 0, Y: 10}) {
+		t.Errorf("ROI scan found %v, want the entity at (0, 10)", points)
+	}
+
+	// An ROI entirely off-screen finds nothing rather than failing
+	tracker.SetLastHighPriority(entityAt(20, -500, -500))
+	if points := screen.NewSearcher().FindAllTemplatesInROI(scr, tpl, tracker.GetROI(), constants.DefaultTolerance); len(points) != 0 {
+		t.Errorf("off-screen ROI found %v, want nothing", points)
+	}
+}
+
+func TestDedupEntities(t *testing.T) {
+	// "20.png" and "20-1.png" both match the same button, a few pixels apart
+	a := DetectedEntity{TemplateName: "20.png", Priority: 20, Position: image.Point{X: 100, Y: 100}, TemplateSize: image.Point{X: 40, Y: 20}}
+	b := DetectedEntity{TemplateName: "20-1.png", Priority: 20, Position: image.Point{X: 104, Y: 102}, TemplateSize: image.Point{X: 44, Y: 24}}
+	// Another priority on the same spot and the same priority elsewhere are kept
+	other := DetectedEntity{TemplateName: "10.png", Priority: 10, Position: image.Point{X: 100, Y: 100}, TemplateSize: image.Point{X: 40, Y: 20}}
+	far := DetectedEntity{TemplateName: "20.png", Priority: 20, Position: image.Point{X: 100, Y: 300}, TemplateSize: image.Point{X: 40, Y: 20}}
+
+	got := DedupEntities([]DetectedEntity{a, b, other, far})
+	if len(got) != 3 {
+		t.Fatalf("DedupEntities = %v, want 3 entities", got)
+	}
+	// The larger template verified more pixels, so it is kept
+	if got[0] != b || got[1] != other || got[2] != far {
+		t.Errorf("DedupEntities = %v, want [%v %v %v]", got, b, other, far)
+	}
+}
+
+func TestEvaluateEntryMergesSamePriorityTemplates(t *testing.T) {
+	button := newTemplate(40, 20, 0)
+	// 20-1.png is a tighter crop of the same button. Its match lands 12px to the right,
+	// in another tracker cell, so without the merge the tracker would hold both.
+	crop := image.NewNRGBA(image.Rect(0, 0, 28, 20))
+	draw.Draw(crop, crop.Bounds(), button, image.Point{X: 12}, draw.Src)
+	frame := newScreen(200, 200)
+	paste(frame, button, 70, 80)
+
+	b, _, _ := newFrameBot(t, frame)
+	b.targetsGames = []Target{{Name: "20.png", Image: button}, {Name: "20-1.png", Image: crop}}
+	entity, _ := b.EvaluateEntry(frame)
+	if entity == nil || entity.TemplateName != "20.png" {
+		t.Fatalf("EvaluateEntry chose %v, want the full 20.png match", entity)
+	}
+	if tracked, _ := b.entryTracker.Stats(); tracked != 1 {
+		t.Errorf("tracker holds %d entities, want the two matches merged into 1", tracked)
+	}
+}
+
+func TestEntityMaxAge(t *testing.T) {
+	now := time.Now()
+	tracker := NewEntityTracker()
+	tracker.SetClock(func() time.Time { return now })
+	tracker.SetMaxAge(time.Minute)
+	e := entityAt(20, 100, 100)
+
+	tracker.Update([]DetectedEntity{e})
+	tracker.RecordClick(e)
+	tracker.RecordClick(e)
+
+	// Still seen every scan, but tracked for less than the max age: state is kept
+	for i := 0; i < 5; i++ {
+		now = now.Add(10 * time.Second)
+		tracker.Update([]DetectedEntity{e})
+	}
+	if got := tracker.GetClickCount(e); got != 2 {
+		t.Fatalf("click count after 50s = %d, want 2", got)
+	}
+
+	// Past the max age it is re-created fresh
+	now = now.Add(11 * time.Second)
+	tracker.Update([]DetectedEntity{e})
+	if got := tracker.GetClickCount(e); got != 0 {
+		t.Errorf("click count after the max age = %d, want 0 (re-created)", got)
+	}
+	if tracked, _ := tracker.Stats(); tracked != 1 {
+		t.Errorf("%d entities tracked, want the re-created one", tracked)
+	}
+
+	// 0 never re-creates
+	tracker.SetMaxAge(0)
+	tracker.RecordClick(e)
+	now = now.Add(time.Hour)
+	tracker.Update([]DetectedEntity{e})
+	if got := tracker.GetClickCount(e); got != 1 {
+		t.Errorf("click count with no max age = %d, want 1", got)
+	}
+}
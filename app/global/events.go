@@ -0,0 +1,74 @@
+package global
+
+import (
+	"fmt"
+	"time"
+)
+
+// EventType identifies the kind of BotEvent
+type EventType int
+
+const (
+	EventStateChanged      EventType = iota // From/To/Trigger are set
+	EventEntityClicked                      // Entity is set
+	EventEntityBlacklisted                  // Entity is set
+	EventCycleCompleted                     // Back at the entry screen after a full game cycle
+)
+
+var eventNames = map[EventType]string{
+	EventStateChanged:      "StateChanged",
+	EventEntityClicked:     "EntityClicked",
+	EventEntityBlacklisted: "EntityBlacklisted",
+	EventCycleCompleted:    "CycleCompleted",
+}
+
+func (t EventType) String() string {
+	if name, ok := eventNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("EventType(%d)", int(t))
+}
+
+// BotEvent is a typed record of something the bot detected or did
+type BotEvent struct {
+	Type EventType
+	At   time.Time
+
+	// EventStateChanged
+	From    BotState
+	To      BotState
+	Trigger string
+
+	// EventEntityClicked, EventEntityBlacklisted
+	Entity DetectedEntity
+}
+
+func (e BotEvent) String() string {
+	switch e.Type {
+	case EventStateChanged:
+		return fmt.Sprintf("%s %s -> %s (%s)", e.Type, e.From, e.To, e.Trigger)
+	case EventEntityClicked, EventEntityBlacklisted:
+		return fmt.Sprintf("%s %s at (%d, %d)", e.Type, e.Entity.TemplateName, e.Entity.Position.X, e.Entity.Position.Y)
+	default:
+		return e.Type.String()
+	}
+}
+
+// Events returns the bot's event stream.
+// The channel is buffered (constants.EventBufferSize) and never closed; events are
+// sent without blocking, so when the buffer is full (or nobody reads) new events are dropped.
+func (b *GlobalBot) Events() <-chan BotEvent {
+	return b.events
+}
+
+// emit sends an event without blocking the bot loop
+func (b *GlobalBot) emit(e BotEvent) {
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	b.stats.count(e)
+	select {
+	case b.events <- e:
+	default: // No reader keeping up, drop
+	}
+}
@@ -0,0 +1,145 @@
+package global
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+// FreezeAction is what the bot does when the game looks frozen (see
+// screen.FreezeDetector)
+type FreezeAction int
+
+const (
+	FreezePause   FreezeAction = iota // Pause until the screen changes (default)
+	FreezeStop                        // Stop the bot
+	FreezeRecover                     // Press the recovery key and/or click the recovery point
+)
+
+func (a FreezeAction) String() string {
+	switch a {
+	case FreezePause:
+		return "pause"
+	case FreezeStop:
+		return "stop"
+	case FreezeRecover:
+		return "recover"
+	default:
+		return fmt.Sprintf("FreezeAction(%d)", int(a))
+	}
+}
+
+// ParseFreezeAction converts a config name ("pause", "stop", "recover") to a
+// FreezeAction. An empty string means FreezePause.
+func ParseFreezeAction(name string) (FreezeAction, error) {
+	switch name {
+	case "", "pause":
+		return FreezePause, nil
+	case "stop":
+		return FreezeStop, nil
+	case "recover":
+		return FreezeRecover, nil
+	default:
+		return FreezePause, fmt.Errorf("unknown freeze action %q", name)
+	}
+}
+
+// checkFreeze feeds the tick's frame and whether it matched anything to the freeze
+// detector, and handles a freeze. Returns true if the bot is stopping.
+func (b *GlobalBot) checkFreeze() bool {
+	b.mu.Lock()
+	timeout := b.cfg.FreezeTimeout.D()
+	b.mu.Unlock()
+	if timeout <= 0 {
+		return false
+	}
+	frame, err := b.searcher.Frame()
+	if err != nil {
+		return false // Capture failures escalate on their own
+	}
+	if !b.freeze.Observe(frame, b.lastMatches > 0, time.Now()) {
+		return false
+	}
+	return b.onFrozen(timeout)
+}
+
+// onFrozen logs (and optionally notifies) a frozen screen and applies the freeze
+// action. Returns true if the bot is stopping.
+func (b *GlobalBot) onFrozen(timeout time.Duration) bool {
+	b.mu.Lock()
+	action := b.freezeAction
+	notify := b.cfg.FreezeNotify
+	key, click := b.cfg.FreezeRecoveryKey, b.cfg.FreezeRecoveryClick
+	b.mu.Unlock()
+	attempt := b.freeze.Triggers()
+	if action == FreezeRecover && attempt > constants.FreezeMaxRecoveries {
+		action = FreezePause
+	}
+
+	msg := fmt.Sprintf("[Freeze] Screen unchanged and nothing expected found for %v in %s, action: %s",
+		time.Duration(attempt)*timeout, b.State, action)
+	if b.errorFunc != nil {
+		b.errorFunc(msg)
+	} else {
+		b.logFunc(msg)
+	}
+	if notify && b.notifyFunc != nil && attempt == 1 {
+		b.notifyFunc("Game frozen", msg)
+	}
+
+	switch action {
+	case FreezeStop:
+		b.logFunc("Auto-stopping due to a frozen screen.")
+		b.autoStop()
+		return true
+	case FreezeRecover:
+		b.logFunc(fmt.Sprintf("[Freeze] Recovery attempt %d/%d", attempt, constants.FreezeMaxRecoveries))
+		if key != "" {
+			if IsSupportedKey(key) {
+				b.performKeyTap("freeze recovery", key)
+			} else {
+				b.logFunc(fmt.Sprintf("[Freeze] Unsupported recovery key %q", key))
+			}
+		}
+		if click != nil {
+			// A 0x0 size makes performClick click exactly at the point
+			b.performClick("freeze recovery", click.X, click.Y, 0, 0)
+		}
+	default:
+		resume := b.Pause("screen frozen, resumes when it changes")
+		b.mu.Lock()
+		b.freezeResume = resume
+		b.mu.Unlock()
+	}
+	return false
+}
+
+// checkThaw resumes a freeze pause once the screen changes; the loop calls it
+// while paused
+func (b *GlobalBot) checkThaw() {
+	b.mu.Lock()
+	resume := b.freezeResume
+	b.mu.Unlock()
+	if resume == nil {
+		return
+	}
+	frame, err := b.searcher.CaptureScreen()
+	if err != nil || !b.freeze.Changed(frame) {
+		return
+	}
+	b.logFunc("[Freeze] Screen changed again.")
+	b.freeze.Reset()
+	b.releaseFreeze()
+}
+
+// releaseFreeze ends a freeze pause, if any
+func (b *GlobalBot) releaseFreeze() {
+	b.mu.Lock()
+	resume := b.freezeResume
+	b.freezeResume = nil
+	b.mu.Unlock()
+	if resume != nil {
+		resume()
+	}
+}
@@ -0,0 +1,72 @@
+package global
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var stateNames = map[BotState]string{
+	StateStopped:      "Stopped",
+	StateAutoDetect:   "AutoDetect",
+	StateEntry:        "Entry",
+	StateEntryWaiting: "EntryWaiting",
+	StateInGame:       "InGame",
+	StateExitStep1:    "ExitStep1",
+	StateExitStep2:    "ExitStep2",
+	StateSearchOpen:   "SearchOpen",
+	StateSearchSelect: "SearchSelect",
+	StateSearchVerify: "SearchVerify",
+	StateEntryVerify:  "EntryVerify",
+}
+
+func (s BotState) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("BotState(%d)", int(s))
+}
+
+// StateTransition records one state machine transition
+type StateTransition struct {
+	From    BotState
+	To      BotState
+	At      time.Time
+	Trigger string // Why the transition happened (e.g. "lobby visible")
+}
+
+func (t StateTransition) String() string {
+	return fmt.Sprintf("[%s] %s -> %s (%s)", t.At.Format("15:04:05"), t.From, t.To, t.Trigger)
+}
+
+// StateHistory is a bounded log of state transitions (oldest dropped first)
+type StateHistory struct {
+	mu      sync.Mutex
+	max     int
+	entries []StateTransition
+}
+
+// NewStateHistory creates a history keeping at most max transitions
+func NewStateHistory(max int) *StateHistory {
+	return &StateHistory{max: max}
+}
+
+// Add records a transition and returns it
+func (h *StateHistory) Add(from, to BotState, trigger string, at time.Time) StateTransition {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	t := StateTransition{From: from, To: to, At: at, Trigger: trigger}
+	h.entries = append(h.entries, t)
+	if len(h.entries) > h.max {
+		h.entries = h.entries[len(h.entries)-h.max:]
+	}
+	return t
+}
+
+// List returns a copy of the recorded transitions, oldest first
+func (h *StateHistory) List() []StateTransition {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]StateTransition(nil), h.entries...)
+}
@@ -0,0 +1,20 @@
+package global
+
+// supportedKeys lists the key names accepted in keyboard-action filenames.
+// Names follow robotgo.KeyTap; single letters/digits (a-z, 0-9) are also allowed.
+var supportedKeys = map[string]bool{
+	"space": true, "enter": true, "esc": true, "tab": true, "backspace": true, "delete": true,
+	"up": true, "down": true, "left": true, "right": true,
+	"home": true, "end": true, "pageup": true, "pagedown": true,
+	"f1": true, "f2": true, "f3": true, "f4": true, "f5": true, "f6": true,
+	"f7": true, "f8": true, "f9": true, "f10": true, "f11": true, "f12": true,
+}
+
+// IsSupportedKey reports whether key can be used as a keyboard action
+func IsSupportedKey(key string) bool {
+	if len(key) == 1 {
+		c := key[0]
+		return (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9')
+	}
+	return supportedKeys[key]
+}
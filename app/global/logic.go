@@ -1,14 +1,20 @@
 package global
 
 import (
+	"errors"
 	"fmt"
 	"image"
-	"path/filepath"
+	"io/fs"
+	"math/rand"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/ConserveLee/gui-idle/assets"
+	"github.com/ConserveLee/gui-idle/internal/config"
 	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/input"
 	"github.com/ConserveLee/gui-idle/internal/engine/screen"
 	"github.com/go-vgo/robotgo"
 )
@@ -27,22 +33,45 @@ const (
 	StateSearchOpen            // Step 1: Click step1/1.png to open channel list
 	StateSearchSelect          // Step 2: Select Target Channel
 	StateSearchVerify          // Step 3: Verify Channel Highlighted -> back to Entry
+	StateEntryVerify           // Verifying an entry click (one attempt per tick)
 )
 
+// entryVerify is the progress of an entry click verification across ticks
+type entryVerify struct {
+	entity          DetectedEntity
+	before          image.Image // Screen at click time, for the region-change fallback
+	attempt         int
+	leftEntryScreen bool // Track if we actually left the entry screen
+}
+
 type Target struct {
-	Name  string
-	Image image.Image
+	Name          string
+	Image         image.Image
+	Sequence      []ClickStep   // Extra clicks after the target itself (from sidecar JSON)
+	Key           string        // If set, press this key instead of clicking (from "name_key=space.png")
+	Hold          time.Duration // Press and hold instead of clicking (from sidecar JSON)
+	Drag          *image.Point  // Drag from the center by this offset instead of clicking (from sidecar JSON)
+	Hover         time.Duration // Rest on the target before clicking (from sidecar JSON)
+	HoverVerify   bool          // Click only if the target is still there after hovering (from sidecar JSON)
+	MatchFraction float64       // Partial match fallback for entry buttons: min fraction of matching pixels (from sidecar JSON; 0 = off)
+	Source        image.Point   // Display size the template was cropped on (from sidecar JSON; zero if unknown)
+	Hash          uint64        // AverageHash of Image, for the fixed-position fast path
+	Hashable      bool          // False for templates with transparency (or smaller than 8x8)
 }
 
 // GlobalBot handles the specific state machine for Global Expedition
 type GlobalBot struct {
 	State      BotState
 	AssetsDir  string
+	cfg        config.Config
+	tick       config.Config // cfg as of the running tick (see snapshot); the handlers read this
+	embedded   fs.FS // Templates built into the binary, used where AssetsDir lacks one (nil = none)
 
 	// Assets - organized by new directory structure
 	// find_game/
 	targetsGames   []Target // find_game/games/*.png - game entry buttons
 	targetsFinding []Target // find_game/finding.png - verify on entry screen
+	targetsAnti    []Target // find_game/anti/*.png - veto nearby entry buttons (see anti.go)
 
 	// waiting/
 	targetsLobby []Target // waiting/lobby.png - verify in lobby
@@ -56,20 +85,78 @@ type GlobalBot struct {
 	targetsChannelOpen   []Target // channel/open.png - open channel list
 	targetsChannelSelect []Target // channel/select.png - select target channel
 
+	corruptAssets int // Templates that failed to decode in the last loadAllAssets
+
 	// Entity Tracking
 	entryTracker *EntityTracker
+	entryOrder   SortOrder // Which entry priority is tried first
+	entryMaxY    int       // Entry matches below this y are ignored (scaled to the display height)
+
+	// State Dwell (see setState)
+	stateDwell   map[BotState]time.Duration // Minimum time in a state before leaving it
+	stateEntered time.Time                  // When the current state was entered
+	pending      *pendingTransition         // Transition held back by the dwell time
+
+	// Confirm First Click (see confirmInput)
+	confirmFunc    ConfirmFunc
+	inputConfirmed bool // The user confirmed the first click of this run
+
+	// Entry Scroll (see scrollEntryList)
+	emptyEntryScans int // Consecutive full entry scans that found nothing
+
+	blacklistAction BlacklistAction // What happens when an entry entity is blacklisted (see onBlacklisted)
+
+	// Incremental Scan (see config.IncrementalScan; nil = off)
+	incremental *screen.IncrementalMatcher
+
+	// Entry Verify State
+	entryVerify entryVerify // The pending entry click being verified
 
 	// Entry Waiting State
-	entryWaitCount int // Count of checks in waiting state (max 10, then exit)
+	lobbyWait *DisappearWait // Waits for lobby.png to disappear (game started), then times out
+	lobbyIdle *screen.IdleDetector // Slows lobby polling while the screen does not change (EntryWaiting only)
+
+	// Frozen Screen (see checkFreeze)
+	freeze       *screen.FreezeDetector
+	freezeAction FreezeAction
+	freezeResume func() // Ends the pause taken for a freeze (nil = none)
 
 	// Search State Retry Counter
 	searchRetryCount int // Count of failed attempts in current search state (max 5, then fallback)
+	verifyFailures   int // Consecutive search cycles whose highlight was never verified
+
+	// Search Step Positions (buttons stay put once found, so check there first)
+	searchPositions map[string]image.Point // Last top-left per search-step/auto-detect template name
+
+	// Scan Throughput
+	scanRate    *ScanRate // Rolling scans-per-second
+	lastStatus  string    // Last status text set by a state handler
+	lastMatches int       // Template matches found during the last scan
+
+	// Capture Failure Escalation
+	captureFailures int          // Consecutive CaptureScreen failures
+	errorFunc       func(string) // For errors that must be visible to the user
+	notifyFunc      func(string, string)
+	stoppedFunc     func() // Called after the bot stops (incl. auto-stop)
+
+	// State History
+	history        *StateHistory
+	transitionFunc func(StateTransition)
+
+	// Event Stream (see Events)
+	events chan BotEvent
+
+	// Run Summary (see RunSummary)
+	stats RunStats
 
 	// Debug
-	debugScreenshotTaken bool // Only save one debug screenshot per session
+	debugScreenshotTaken bool    // Only save one debug screenshot per session
+	roiFunc              ROIFunc // Observer of the entry ROI fast path (see SetROIFunc)
 
 	// Dependencies
 	searcher   *screen.Searcher
+	actions    input.Actions
+	rng        *rand.Rand // Shared source for randomized behavior, reseeded each run (see config.Seed)
 	logFunc    func(string)
 	statusFunc func(string)
 	debugFunc  func(string, ...interface{})
@@ -77,11 +164,25 @@ type GlobalBot struct {
 	// Display Offset
 	displayOffsetX int
 	displayOffsetY int
+	displayScale   float64 // Display scale factor (for the click log)
+
+	// Click Accuracy Log (see ClickLogPath)
+	clickLog  *input.ClickLog
+	clickFunc ClickFunc // Observer of every click (see SetClickFunc)
 
 	// Control
 	stopChan chan struct{}
+	stopping bool // Stop is waiting for the loop to exit
 	wg       sync.WaitGroup
 	mu       sync.Mutex
+
+	// Pause (see Pause)
+	gate        *input.Gate // Held while paused: the loop idles and input is skipped
+	pauseReason string      // Reason of the latest pause, for the status
+
+	// Single Step (see Step)
+	stepping  bool     // A step is running
+	stepState BotState // State the last step left (StateStopped = start over at AutoDetect)
 }
 
 func NewGlobalBot(log func(string), status func(string), debug func(string, ...interface{})) *GlobalBot {
@@ -89,94 +190,519 @@ func NewGlobalBot(log func(string), status func(string), debug func(string, ...i
 	tracker.SetDebugFunc(debug)
 	searcher := screen.NewSearcher()
 	searcher.SetDebugFunc(debug)
+	cfg := config.Default()
+	embedded, _ := fs.Sub(assets.Defaults(), "global_targets") // Sub never fails on a valid path
 	return &GlobalBot{
-		State:        StateStopped,
-		AssetsDir:    "assets/global_targets",
-		entryTracker: tracker,
-		searcher:     searcher,
-		logFunc:      log,
-		statusFunc:   status,
-		debugFunc:    debug,
-		stopChan:     make(chan struct{}),
+		State:           StateStopped,
+		AssetsDir:       cfg.AssetsDir,
+		cfg:             cfg,
+		embedded:        embedded,
+		entryTracker:    tracker,
+		searcher:        searcher,
+		actions:         input.Robot{},
+		scanRate:        NewScanRate(constants.ScanRateWindow),
+		history:         NewStateHistory(constants.StateHistorySize),
+		events:          make(chan BotEvent, constants.EventBufferSize),
+		searchPositions: make(map[string]image.Point),
+		entryMaxY:       constants.EntryMaxY,
+		lobbyWait:       NewDisappearWait(cfg.LobbyPollInterval.D(), cfg.LobbyTimeout.D()),
+		lobbyIdle:       screen.NewIdleDetector(constants.IdleFrames, constants.IdleIntervalFactor, constants.IdleChangeThreshold, cfg.Tolerance),
+		freeze:          screen.NewFreezeDetector(cfg.FreezeTimeout.D(), constants.IdleChangeThreshold, cfg.Tolerance),
+		logFunc:         log,
+		statusFunc:      status,
+		debugFunc:       debug,
+		stopChan:        make(chan struct{}),
+		clickLog:        input.NewClickLog(ClickLogPath),
+		gate:            &input.Gate{},
 	}
 }
 
-func (b *GlobalBot) SetDisplayID(id int) {
+func (b *GlobalBot) SetDisplayID(id int) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	b.searcher.SetDisplayID(id)
+	if err := b.searcher.SetDisplayID(id); err != nil {
+		return err
+	}
 	
-	x, y, _, _ := robotgo.GetDisplayBounds(id)
+	x, y, w, h := robotgo.GetDisplayBounds(id)
 	b.displayOffsetX = x
 	b.displayOffsetY = y
+	b.displayScale = robotgo.SysScale(id)
 	b.logFunc(fmt.Sprintf("Display %d Offset set to (%d, %d)", id, x, y))
+
+	// Recompute resolution-dependent thresholds for the new capture size
+	b.entryMaxY = screen.ScaleY(constants.EntryMaxY, constants.EntryMaxYRefHeight, h)
+	b.logFunc(fmt.Sprintf("Display %d capture size %dx%d, entry Y cutoff %d", id, w, h, b.entryMaxY))
+	return nil
+}
+
+// SetConfig applies a loaded config (assets dir, display, tolerance, match mode, intervals, etc.)
+func (b *GlobalBot) SetConfig(cfg config.Config) {
+	b.mu.Lock()
+	b.cfg = cfg
+	b.AssetsDir = cfg.AssetsDir
+	b.freezeAction, _ = ParseFreezeAction(cfg.FreezeAction) // Validated on load
+	mode, _ := screen.ParseMatchMode(cfg.MatchMode) // Validated on load
+	b.searcher.SetMatchMode(mode)
+	b.searcher.SetNormalization(cfg.ColorCalibration.Normalization())
+	b.searcher.SetCaptureRetry(cfg.CaptureRetries, cfg.CaptureRetryBackoff.D())
+	b.entryOrder, _ = ParseSortOrder(cfg.EntrySortOrder)
+	b.blacklistAction, _ = ParseBlacklistAction(cfg.EntryBlacklistAction) // Validated on load
+	b.stateDwell = b.parseStateDwell(cfg.StateDwell)
+	b.entryTracker.SetROIMargin(ROIMargin{Up: cfg.EntryROIMarginUp, Down: cfg.EntryROIMargin, Left: cfg.EntryROIMargin, Right: cfg.EntryROIMargin})
+	b.entryTracker.SetMaxAge(cfg.EntityMaxAge.D())
+	if cfg.IncrementalScan {
+		if b.incremental == nil {
+			b.incremental = screen.NewIncrementalMatcher(b.searcher, constants.IncrementalBlockSize, constants.IncrementalDiffTolerance)
+		}
+	} else {
+		b.incremental = nil
+	}
+	b.mu.Unlock()
+	if err := b.SetDisplayID(b.resolveDisplay(cfg.DisplayID, cfg.Display)); err != nil {
+		b.logFunc(fmt.Sprintf("Config display ignored: %v, using display 0", err))
+		b.SetDisplayID(0)
+	}
+}
+
+// resolveDisplay maps a stable display id to its current index, falling back to
+// the configured index when the monitor is not connected. Callers pass copies of
+// cfg.DisplayID and cfg.Display, as b.cfg may only be read under b.mu.
+func (b *GlobalBot) resolveDisplay(displayID string, display int) int {
+	if displayID == "" {
+		return display
+	}
+	if index, ok := screen.ResolveDisplay(displayID, screen.ActiveDisplays()); ok {
+		if index != display {
+			b.logFunc(fmt.Sprintf("Display %s is now index %d (was %d)", displayID, index, display))
+		}
+		return index
+	}
+	b.logFunc(fmt.Sprintf("Display %s not found, using display %d", displayID, display))
+	return display
+}
+
+// SetEntrySortOrder sets which entry priority is tried first.
+// The entry templates are re-sorted on the next Start.
+func (b *GlobalBot) SetEntrySortOrder(order SortOrder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.entryOrder = order
+	b.cfg.EntrySortOrder = order.String()
+}
+
+// SetROIMargin sets the entry ROI margin (sides/below and above)
+func (b *GlobalBot) SetROIMargin(margin, up int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg.EntryROIMargin = margin
+	b.cfg.EntryROIMarginUp = up
+	b.entryTracker.SetROIMargin(ROIMargin{Up: up, Down: margin, Left: margin, Right: margin})
+}
+
+// SetActions replaces the input backend (mouse/keyboard)
+func (b *GlobalBot) SetActions(a input.Actions) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.actions = a
+}
+
+// SetLobbyTimeout changes how long to wait in the lobby before giving up
+func (b *GlobalBot) SetLobbyTimeout(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg.LobbyTimeout = config.Duration(d)
+}
+
+// SetLobbyPollInterval changes how often lobby.png is checked while waiting in the lobby
+func (b *GlobalBot) SetLobbyPollInterval(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg.LobbyPollInterval = config.Duration(d)
+}
+
+// SetWarmUp changes the delay before the first scan of the next run
+func (b *GlobalBot) SetWarmUp(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg.WarmUp = config.Duration(d)
 }
 
-func (b *GlobalBot) setState(s BotState) {
+// SetEntryClickWait changes the settle time between an entry click and its verification
+func (b *GlobalBot) SetEntryClickWait(d time.Duration) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	b.cfg.EntryClickWait = config.Duration(d)
+}
+
+// SetSearchClickWait changes the settle time after each click in the channel search
+func (b *GlobalBot) SetSearchClickWait(d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg.SearchClickWait = config.Duration(d)
+}
+
+// setState transitions the state machine, recording the trigger in the history.
+// A transition out of a state that has not been active for its minimum dwell time
+// (config state_dwell) is held back and applied by the loop once the dwell has passed.
+func (b *GlobalBot) setState(s BotState, trigger string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s != b.State && b.dwellRemaining(time.Now()) > 0 {
+		b.logFunc(fmt.Sprintf("[Dwell] %s -> %s (%s) held back, %s dwell is %v", b.State, s, trigger, b.State, b.stateDwell[b.State]))
+		b.pending = &pendingTransition{to: s, trigger: trigger}
+		return
+	}
+	b.transition(s, trigger)
+}
+
+// transition switches to s immediately. Caller holds b.mu.
+func (b *GlobalBot) transition(s BotState, trigger string) {
+	b.recordTransition(s, trigger)
+	if s != b.State {
+		b.stateEntered = time.Now()
+	}
 	b.State = s
+	b.pending = nil
+	b.lobbyIdle.Reset() // Every wait starts at the normal interval
+	b.emptyEntryScans = 0
+}
+
+// recordTransition logs and records a transition from the current state. Caller holds b.mu.
+func (b *GlobalBot) recordTransition(to BotState, trigger string) {
+	if b.State == to {
+		return
+	}
+	t := b.history.Add(b.State, to, trigger, time.Now())
+	b.logFunc(fmt.Sprintf("State: %s -> %s (%s)", t.From, t.To, t.Trigger))
+	if b.transitionFunc != nil {
+		b.transitionFunc(t)
+	}
+	b.emit(BotEvent{Type: EventStateChanged, At: t.At, From: t.From, To: t.To, Trigger: t.Trigger})
+	// Search verified -> Entry closes the loop: entry, game, exit, channel search
+	if t.From == StateSearchVerify && t.To == StateEntry {
+		b.emit(BotEvent{Type: EventCycleCompleted, At: t.At})
+	}
 }
 
-func (b *GlobalBot) Start() {
+// StateHistory returns the recorded state transitions, oldest first
+func (b *GlobalBot) StateHistory() []StateTransition {
+	return b.history.List()
+}
+
+// SetTransitionFunc sets a callback fired on every state transition (e.g. for the history view)
+func (b *GlobalBot) SetTransitionFunc(f func(StateTransition)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.transitionFunc = f
+}
+
+// Start loads the templates and starts the loop. It returns the load error when the bot
+// cannot start (the loop never runs, so the stopped callback does not fire).
+func (b *GlobalBot) Start() error {
 	b.mu.Lock()
 	if b.State != StateStopped {
 		b.mu.Unlock()
-		return
+		return nil
 	}
 	
 	if err := b.loadAllAssets(); err != nil {
 		b.logFunc(fmt.Sprintf("Startup Error: %v", err))
 		b.mu.Unlock()
-		return
+		return err
 	}
 
+	b.recordTransition(StateAutoDetect, "started")
 	b.State = StateAutoDetect
+	b.stepState = StateStopped // A run leaves no state to step on from
+	b.stateEntered = time.Now()
+	b.pending = nil
+	b.inputConfirmed = false
+	b.captureFailures = 0
+	b.verifyFailures = 0
+	b.searchPositions = make(map[string]image.Point)
+	if b.incremental != nil {
+		b.incremental.Reset()
+	}
+	b.freeze.Reset()
+	b.stats = RunStats{Started: time.Now()}
 	b.stopChan = make(chan struct{})
+	// The setters may change cfg from the UI once the lock is released
+	displayID, display := b.cfg.DisplayID, b.cfg.Display
+	cfgSeed := b.cfg.Seed
+	powerSaver, powerSaverFactor := b.cfg.PowerSaver, b.cfg.PowerSaverFactor
 	b.mu.Unlock()
 
+	// Monitors may have been re-plugged since the display was chosen
+	if displayID != "" {
+		if err := b.SetDisplayID(b.resolveDisplay(displayID, display)); err != nil {
+			b.logFunc(fmt.Sprintf("Display %s unavailable: %v", displayID, err))
+		}
+	}
+
+	b.warnResolutionMismatch()
+	b.warnUnmatchableTemplates()
+
 	b.logFunc("Global Expedition Bot Started. Auto-detecting state...")
+	var seed int64
+	b.rng, seed = input.NewRand(cfgSeed)
+	b.logFunc(fmt.Sprintf("Random seed: %d (pass -seed %d to reproduce)", seed, seed))
+	if powerSaver {
+		b.logFunc(fmt.Sprintf("Power saver on: scan intervals x%g", powerSaverFactor))
+	}
 	b.wg.Add(1)
 	go b.loop()
+	return nil
 }
 
-func (b *GlobalBot) Stop() {
+// Running reports whether the bot loop is active
+func (b *GlobalBot) Running() bool {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	return b.State != StateStopped
+}
 
-	if b.State == StateStopped {
+func (b *GlobalBot) Stop() {
+	b.mu.Lock()
+	if b.stepping {
+		// No loop to wait on: the step ends stopped once its tick returns (see endStep)
+		b.stopStep()
+		b.mu.Unlock()
 		return
 	}
-
+	if b.State == StateStopped || b.stopping {
+		b.mu.Unlock()
+		return
+	}
+	b.stopping = true
 	close(b.stopChan)
+	b.mu.Unlock()
+
+	// The loop may need b.mu (setState) to finish its current tick
 	b.wg.Wait()
+	b.releaseFreeze() // A freeze pause must not hold the next run
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stopping = false
+
+	summary := b.RunSummary()
+	for _, line := range strings.Split(summary, "\n") {
+		b.logFunc(line)
+	}
+	if err := appendSummary(SummaryLogPath, summary); err != nil {
+		b.debugFunc("Failed to write %s: %v", SummaryLogPath, err)
+	}
+	b.recordTransition(StateStopped, "stopped")
 	b.State = StateStopped
 	b.logFunc("Bot Stopped.")
 	b.statusFunc("Status: Stopped")
+	if b.stoppedFunc != nil {
+		b.stoppedFunc()
+	}
+}
+
+// autoStop stops the bot from inside a tick (capture or verify failures, a declined
+// click, ...). Stop waits on the loop goroutine, so it runs separately; a step is
+// stopped directly, so it ends stopped however late Stop would have run.
+func (b *GlobalBot) autoStop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stepping {
+		b.stopStep()
+		return
+	}
+	go b.Stop()
+}
+
+// SetAlertFuncs sets the callbacks for user-visible errors, desktop notifications
+// (title, message) and bot stop (including auto-stop)
+func (b *GlobalBot) SetAlertFuncs(errorFunc func(string), notifyFunc func(string, string), stoppedFunc func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.errorFunc = errorFunc
+	b.notifyFunc = notifyFunc
+	b.stoppedFunc = stoppedFunc
+}
+
+// captureFrame returns the current frame, escalating after repeated capture failures
+// (e.g. screen recording permission revoked mid-run) instead of retrying silently forever.
+func (b *GlobalBot) captureFrame() (image.Image, error) {
+	img, err := b.searcher.Frame()
+	if err == nil {
+		if b.captureFailures >= b.tick.CaptureFailThreshold {
+			b.logFunc(fmt.Sprintf("Screen capture recovered after %d failures.", b.captureFailures))
+		}
+		b.captureFailures = 0
+		return img, nil
+	}
+
+	b.captureFailures++
+	b.debugFunc("CaptureScreen failed (%d in a row): %v", b.captureFailures, err)
+	if b.captureFailures == b.tick.CaptureFailThreshold {
+		msg := fmt.Sprintf("Screen capture failed %d times in a row: %v", b.captureFailures, err)
+		if b.errorFunc != nil {
+			b.errorFunc(msg)
+		} else {
+			b.logFunc(msg)
+		}
+		if b.notifyFunc != nil {
+			b.notifyFunc("Screen capture failing", msg)
+		}
+		if b.tick.StopOnCaptureFailure {
+			b.logFunc("Auto-stopping due to capture failures.")
+			b.autoStop()
+		}
+	}
+	return nil, err
 }
 
 func (b *GlobalBot) loop() {
 	defer b.wg.Done()
+	b.snapshot()
+	if !b.warmUp(b.tick.WarmUp.D()) {
+		return
+	}
 	timer := time.NewTimer(0)
 
+	// Max runtime (0 = unlimited)
+	var deadline <-chan time.Time
+	if b.tick.MaxRuntime > 0 {
+		deadlineTimer := time.NewTimer(b.tick.MaxRuntime.D())
+		defer deadlineTimer.Stop()
+		deadline = deadlineTimer.C
+	}
+
 	for {
 		select {
 		case <-b.stopChan:
 			timer.Stop()
 			return
+		case <-deadline:
+			timer.Stop()
+			b.logFunc(fmt.Sprintf("Max runtime (%v) reached. Stopping...", b.tick.MaxRuntime.D()))
+			// Stop waits on this goroutine, so it must run separately
+			go b.Stop()
+			return
 		case <-timer.C:
+			b.snapshot()
+			// Paused (e.g. a dialog is open): idle without scanning
+			if b.gate.Held() {
+				b.pausedStatus()
+				b.checkThaw()
+				timer.Reset(constants.PausePollInterval)
+				continue
+			}
+			// A held-back transition runs first; until then the old handler is not repeated
+			if wait, ok := b.applyPending(); !ok {
+				timer.Reset(wait)
+				continue
+			}
+			b.searcher.InvalidateFrame() // New tick, new frame
+			start := time.Now()
 			nextInterval := b.processState()
-			timer.Reset(nextInterval)
+			b.stats.Scans++
+			b.stats.ScanTime += time.Since(start)
+			b.recordScan()
+			if b.checkFreeze() {
+				timer.Stop()
+				return
+			}
+			timer.Reset(b.throttle(nextInterval))
 		}
 	}
 }
 
+// snapshot copies cfg for the tick about to run. The setters change cfg under b.mu
+// from the UI goroutine, while the handlers read b.tick without the lock, so a tick
+// sees one consistent config. The setters replace cfg's maps rather than modifying
+// them, so the copy can share them. The lobby wait and the idle and freeze detectors
+// are only used on the bot goroutine and take their settings from the copy.
+func (b *GlobalBot) snapshot() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tick = b.cfg
+	b.lobbyWait.Poll = b.tick.LobbyPollInterval.D()
+	b.lobbyWait.Timeout = b.tick.LobbyTimeout.D()
+	b.lobbyIdle.Tolerance = b.tick.Tolerance
+	b.freeze.Timeout = b.tick.FreezeTimeout.D()
+	b.freeze.Tolerance = b.tick.Tolerance
+}
+
+// throttle stretches a scan interval while the power saver is on
+func (b *GlobalBot) throttle(d time.Duration) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.cfg.PowerSaver {
+		return d
+	}
+	return time.Duration(float64(d) * b.cfg.PowerSaverFactor)
+}
+
+// SetPowerSaver turns the power saver on or off; it takes effect on the next scan
+func (b *GlobalBot) SetPowerSaver(enabled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cfg.PowerSaver == enabled {
+		return
+	}
+	b.cfg.PowerSaver = enabled
+	if enabled {
+		entry := b.cfg.EntryScanInterval.D()
+		b.logFunc(fmt.Sprintf("Power saver on: scan intervals x%g (e.g. entry scan %v -> %v)",
+			b.cfg.PowerSaverFactor, entry, time.Duration(float64(entry)*b.cfg.PowerSaverFactor)))
+	} else {
+		b.logFunc("Power saver off: scan intervals back to normal")
+	}
+}
+
+// warmUp counts down d in the status before the first scan, so the user can switch
+// to the game. It returns false if the bot is stopped meanwhile.
+func (b *GlobalBot) warmUp(d time.Duration) bool {
+	for remaining := d; remaining > 0; remaining -= time.Second {
+		b.statusFunc(fmt.Sprintf("Status: Starting in %ds... (switch to the game)", int((remaining+time.Second-1)/time.Second)))
+		if !b.wait(min(remaining, time.Second)) {
+			return false
+		}
+	}
+	return true
+}
+
+// wait blocks for d, returning false early if the bot is stopped meanwhile.
+// Handlers should return their wait as the next interval instead; this is for
+// waits inside a single action (e.g. a click sequence).
+func (b *GlobalBot) wait(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-b.stopChan:
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// setStatus updates the status label; the scan rate is appended after each scan
+func (b *GlobalBot) setStatus(msg string) {
+	b.lastStatus = msg
+	b.statusFunc(msg)
+}
+
+// recordScan counts a completed scan and refreshes the status with the throughput readout
+func (b *GlobalBot) recordScan() {
+	b.scanRate.Record(time.Now())
+	b.lastMatches = b.searcher.TakeMatchCount()
+	b.statusFunc(fmt.Sprintf("%s %.1f scans/s, %d matches", b.lastStatus, b.scanRate.PerSecond(time.Now()), b.lastMatches))
+}
+
 func (b *GlobalBot) processState() time.Duration {
 	switch b.State {
 	case StateAutoDetect:
 		return b.handleAutoDetectState()
 	case StateEntry:
 		return b.handleEntryState()
+	case StateEntryVerify:
+		return b.handleEntryVerifyState()
 	case StateEntryWaiting:
 		return b.handleEntryWaitingState()
 	case StateInGame:
@@ -192,35 +718,58 @@ func (b *GlobalBot) processState() time.Duration {
 	case StateSearchVerify:
 		return b.handleSearchVerifyState()
 	default:
-		return constants.EntryScanIntervalHighSpeed
+		return b.tick.EntryScanInterval.D()
 	}
 }
 
 func (b *GlobalBot) handleAutoDetectState() time.Duration {
-	b.statusFunc("Status: Auto Detecting State...")
+	b.setStatus("Status: Auto Detecting State...")
 
-	screenImg, err := b.searcher.CaptureScreen()
+	screenImg, err := b.captureFrame()
 	if err != nil {
 		b.debugFunc("CaptureScreen failed: %v", err)
-		return constants.EntryScanIntervalHighSpeed
+		return b.tick.EntryScanInterval.D()
 	}
 
+	// A transition needs AutoDetectMinMatches templates of the group to match
+	// (capped at the group size), so one spurious match can't derail the cycle
 	check := func(targets []Target, nextState BotState, logMsg string) bool {
-		for _, target := range targets {
-			_, _, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
+		if len(targets) == 0 {
+			return false
+		}
+		required := b.tick.AutoDetectMinMatches
+		if required > len(targets) {
+			required = len(targets)
+		}
+
+		var matched []string
+		for _, target := range b.scanOrder(targets) {
+			_, _, found := b.findFixed(screenImg, target)
 			if found {
-				b.logFunc(fmt.Sprintf("Auto-Detect: Found [%s]. State -> %s", target.Name, logMsg))
-				b.searchRetryCount = 0 // Reset retry counter on state transition
-				b.setState(nextState)
-				return true
+				matched = append(matched, target.Name)
+				if len(matched) >= required {
+					break
+				}
 			}
 		}
-		return false
+
+		if len(matched) == 0 {
+			return false
+		}
+		if len(matched) < required {
+			b.logFunc(fmt.Sprintf("Auto-Detect: Rejected %s, only %d/%d templates matched %v", logMsg, len(matched), required, matched))
+			return false
+		}
+
+		b.logFunc(fmt.Sprintf("Auto-Detect: Found %v. State -> %s", matched, logMsg))
+		b.searchRetryCount = 0 // Reset retry counter on state transition
+		b.setState(nextState, fmt.Sprintf("auto-detect found %v", matched))
+		return true
 	}
 
 	// Detection order: from "deep" states to "shallow" states
 	// 1. In-game states (highest priority)
-	if check(b.targetsSkill, StateInGame, "InGame(skill)") { return constants.InGameScanInterval }
+	if check(b.targetsSkill, StateInGame, "InGame(skill)") { return b.tick.InGameScanInterval.D() }
 	if check(b.targetsExit, StateExitStep1, "ExitStep1(exit)") { return 0 }
 	if check(b.targetsLobby, StateEntryWaiting, "EntryWaiting(lobby)") { return 0 }
 
@@ -235,53 +784,71 @@ func (b *GlobalBot) handleAutoDetectState() time.Duration {
 
 	// Nothing found - keep scanning
 	b.debugFunc("[AutoDetect] No recognizable state found")
-	return constants.SearchScanInterval
+	return b.tick.SearchScanInterval.D()
 }
 
 func (b *GlobalBot) handleEntryState() time.Duration {
-	b.statusFunc("Status: Scanning Entry...")
+	b.setStatus("Status: Scanning Entry...")
 
-	screenImg, err := b.searcher.CaptureScreen()
+	screenImg, err := b.captureFrame()
 	if err != nil {
-		return 400 * time.Millisecond
+		return constants.EntryCaptureRetryInterval
 	}
 
 	// Priority check: Are we already in-game? (exit button visible)
-	for _, target := range b.targetsExit {
-		_, _, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
-		if found {
-			b.logFunc("Already in-game (exit button detected). Switching to Exit state.")
-			b.entryTracker.Reset()
-			b.setState(StateExitStep1)
-			return 0
-		}
+	if _, _, found := b.findAny(screenImg, b.targetsExit, b.tick.Tolerance); found {
+		b.logFunc("Already in-game (exit button detected). Switching to Exit state.")
+		b.entryTracker.Reset()
+		b.setState(StateExitStep1, "exit button visible")
+		return 0
 	}
 
 	// Secondary check: Are we in lobby? (in.png visible)
-	for _, target := range b.targetsLobby {
-		_, _, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
-		if found {
-			b.logFunc("In lobby (in.png detected). Switching to EntryWaiting state.")
-			b.entryTracker.Reset()
-			b.entryWaitCount = 0
-			b.setState(StateEntryWaiting)
-			return 5 * time.Second
-		}
+	if _, _, found := b.findAny(screenImg, b.targetsLobby, b.tick.Tolerance); found {
+		b.logFunc("In lobby (in.png detected). Switching to EntryWaiting state.")
+		b.entryTracker.Reset()
+		b.lobbyWait.Start(time.Now())
+		b.setState(StateEntryWaiting, "lobby visible")
+		return b.lobbyWait.Poll
 	}
 
+	entity, interval := b.EvaluateEntry(screenImg)
+	if entity != nil {
+		return b.clickAndVerifyEntry(screenImg, *entity)
+	}
+	if b.tick.EntryScrollAfter > 0 && b.emptyEntryScans >= b.tick.EntryScrollAfter {
+		b.emptyEntryScans = 0
+		b.scrollEntryList(b.scanRegion("entry", screenImg), fmt.Sprintf("No entries for %d scans", b.tick.EntryScrollAfter))
+	}
+	return interval
+}
+
+// EvaluateEntry runs entry detection and selection on a supplied frame without
+// clicking. It returns the entity the entry state would click, or nil and the
+// interval to wait before the next scan. Tracker state is updated as in a live scan,
+// so replaying a sequence of frames reproduces the bot's decisions.
+func (b *GlobalBot) EvaluateEntry(frame image.Image) (*DetectedEntity, time.Duration) {
+	// Entry buttons are only searched inside the active scan region (if set)
+	screenImg := b.scanRegion("entry", frame)
+	tolerance := b.tolerance("entry")
+	// Strict tier first; configured looser tiers only when a template finds nothing
+	tiers := append([]float64{tolerance}, b.tick.EntryToleranceTiers...)
+
 	// ROI Fast Path: If we have a ROI from last high priority detection,
 	// first scan only that region for high priority targets
 	roi := b.entryTracker.GetROI()
+	roiHit := false
+	defer func() { b.reportROI(frame, roi, roiHit) }()
 	if !roi.Empty() {
-		// Scan ROI for highest priority templates first (sorted descending by name)
+		// Scan ROI for preferred templates first (load order follows the entry sort order)
 		for _, target := range b.targetsGames {
-			points := b.searcher.FindAllTemplatesInROI(screenImg, target.Image, roi, constants.DefaultTolerance)
+			points := b.searcher.FindAllTemplatesInROI(screenImg, target.Image, roi, tolerance)
 			if len(points) > 0 {
-				priority := ExtractPriority(target.Name)
+				priority := ParseTargetName(target.Name).Priority
 				templateSize := image.Point{X: target.Image.Bounds().Dx(), Y: target.Image.Bounds().Dy()}
 
 				for _, p := range points {
-					if p.Y > 950 {
+					if p.Y > b.entryMaxY {
 						continue
 					}
 
@@ -292,37 +859,76 @@ func (b *GlobalBot) handleEntryState() time.Duration {
 						TemplateSize: templateSize,
 					}
 
-					// Skip if blacklisted
+					// Skip if blacklisted or vetoed by an anti-template
 					if b.entryTracker.IsBlacklisted(entity) {
 						continue
 					}
+					if anti, vetoed := b.vetoedBy(screenImg, entity); vetoed {
+						b.debugFunc("[Entry] ROI Fast: %s vetoed by anti-template %s", target.Name, anti)
+						continue
+					}
 
 					// Update tracker to refresh LastSeen (prevent expiration)
 					b.entryTracker.Update([]DetectedEntity{entity})
 
 					// Found high priority entity in ROI - click immediately!
 					b.debugFunc("[Entry] ROI Fast: Found %s (pri=%d) at (%d, %d)", target.Name, priority, p.X, p.Y)
-					return b.clickAndVerifyEntry(screenImg, entity)
+					b.logDetections([]DetectedEntity{entity}, []DetectedEntity{entity})
+					roiHit = true
+					return &entity, 0
 				}
 			}
 		}
 		b.debugFunc("[Entry] ROI scan empty, falling back to full screen")
 	}
 
-	// Full Screen Scan: Collect all detected entities from all templates
+	// Full Screen Scan: Collect all detected entities from all templates.
+	// Scan order doesn't affect cost here: every template is scanned (no early exit), and
+	// with quick-reject probes each pass costs ~screen area regardless of template size.
+	// Measured on a 1080p no-match frame, 8 templates: 174ms in priority order vs 179ms largest-first.
+	// Only the checks that stop at the first hit follow config.LargestFirstScan (see scanOrder).
 	var allEntities []DetectedEntity
+	if b.incremental != nil {
+		b.incremental.Next(screenImg)
+		changed, total := b.incremental.Changed()
+		b.debugFunc("[Entry] Incremental scan: %d/%d blocks changed", changed, total)
+	}
 
-	for _, target := range b.targetsGames {
-		points := b.searcher.FindAllTemplates(screenImg, target.Image, constants.DefaultTolerance)
-		priority := ExtractPriority(target.Name)
+	for i, target := range b.targetsGames {
+		// Progress feedback so slow (e.g. 4K) scans don't look hung
+		if i > 0 && i%constants.ScanProgressEvery == 0 {
+			b.statusFunc(fmt.Sprintf("Status: Scanning Entry (template %d/%d)...", i+1, len(b.targetsGames)))
+		}
+		points, tier := b.findEntry(screenImg, target, tiers)
+		priority := ParseTargetName(target.Name).Priority
 		templateSize := image.Point{
 			X: target.Image.Bounds().Dx(),
 			Y: target.Image.Bounds().Dy(),
 		}
 
+		// Partly covered buttons (e.g. under an overlay): accept a fraction of the pixels
+		// and click the part that matched
+		if len(points) == 0 && target.MatchFraction > 0 {
+			for _, m := range b.searcher.FindAllPartial(screenImg, target.Image, tolerance, target.MatchFraction) {
+				if m.At.Y > b.entryMaxY {
+					continue
+				}
+				b.debugFunc("[Entry] Template %s partially matched at (%d, %d) (%.0f%% of pixels, visible %v)",
+					target.Name, m.At.X, m.At.Y, m.Fraction*100, m.Region)
+				allEntities = append(allEntities, DetectedEntity{
+					TemplateName: target.Name,
+					Priority:     priority,
+					Position:     m.At,
+					TemplateSize: templateSize,
+					Region:       m.Region,
+				})
+			}
+			continue
+		}
+
 		// Debug: Log raw matches count for each template
 		if len(points) > 0 {
-			b.debugFunc("[Entry] Template %s found %d raw matches", target.Name, len(points))
+			b.debugFunc("[Entry] Template %s found %d raw matches (tier %d, tolerance %.1f)", target.Name, len(points), tier, tiers[tier])
 			for i, p := range points {
 				b.debugFunc("[Entry]   raw[%d] at (%d, %d)", i, p.X, p.Y)
 			}
@@ -330,7 +936,7 @@ func (b *GlobalBot) handleEntryState() time.Duration {
 
 		for _, p := range points {
 			// Y-Axis Filter: Ignore matches at the very bottom (likely false positives)
-			if p.Y > 950 {
+			if p.Y > b.entryMaxY {
 				continue
 			}
 
@@ -343,11 +949,21 @@ func (b *GlobalBot) handleEntryState() time.Duration {
 		}
 	}
 
+	// Templates of the same priority may all match one button: keep one entity per button
+	if deduped := DedupEntities(allEntities); len(deduped) < len(allEntities) {
+		b.debugFunc("[Entry] Merged %d overlapping same-priority matches", len(allEntities)-len(deduped))
+		allEntities = deduped
+	}
+
 	// Update tracker with all detected entities (handles TTL-based removal)
 	b.entryTracker.Update(allEntities)
 
+	if len(allEntities) > 0 {
+		b.emptyEntryScans = 0
+	}
 	if len(allEntities) == 0 {
 		b.debugFunc("[Entry] No entities found on screen (templates: %d)", len(b.targetsGames))
+		b.emptyEntryScans++
 		// Save debug screenshot once and list templates
 		if !b.debugScreenshotTaken {
 			b.debugScreenshotTaken = true
@@ -359,23 +975,29 @@ func (b *GlobalBot) handleEntryState() time.Duration {
 			}
 			// Log screen dimensions
 			b.logFunc(fmt.Sprintf("[Debug] Screen capture size: %dx%d", screenImg.Bounds().Dx(), screenImg.Bounds().Dy()))
-			if err := b.searcher.SaveDebugScreenshot("debug_entry_screen.png"); err == nil {
+			if err := screen.SaveImage("debug_entry_screen.png", frame); err == nil {
 				b.logFunc("[Debug] Saved screenshot to debug_entry_screen.png - compare with templates")
 			}
 		}
-		return constants.EntryScanIntervalHighSpeed
+		return nil, b.tick.EntryScanInterval.D()
 	}
 
-	// Filter out blacklisted entities
-	validEntities := b.entryTracker.FilterBlacklisted(allEntities)
+	// Filter out blacklisted entities and those vetoed by an anti-template
+	validEntities := b.filterVetoed(screenImg, b.entryTracker.FilterBlacklisted(allEntities))
+
+	// Sort by priority (per configured order) then by Y coordinate (lower on screen first)
+	SortEntitiesByPriority(validEntities, b.entryOrder)
+	b.logDetections(allEntities, validEntities)
 	if len(validEntities) == 0 {
 		tracked, blacklisted := b.entryTracker.Stats()
 		b.debugFunc("[Entry] All %d entities blacklisted (tracked=%d, blacklisted=%d)", len(allEntities), tracked, blacklisted)
-		return constants.EntryScanIntervalHighSpeed
-	}
 
-	// Sort by priority (higher first) then by Y coordinate (lower on screen first)
-	SortEntitiesByPriority(validEntities)
+		// Nothing is clickable: back off instead of scanning at full speed,
+		// and let old blacklist entries expire so they can be retried
+		expired := b.entryTracker.ExpireBlacklist(constants.BlacklistExpiry)
+		b.logFunc(fmt.Sprintf("[Entry] No clickable entities (blacklisted or vetoed), cooling down %v (%d expired)", constants.BlacklistCooldownInterval, expired))
+		return nil, constants.BlacklistCooldownInterval
+	}
 
 	b.debugFunc("[Entry] Detected %d entities (%d valid after blacklist filter), sorted order:",
 		len(allEntities), len(validEntities))
@@ -384,9 +1006,47 @@ func (b *GlobalBot) handleEntryState() time.Duration {
 		b.debugFunc("  [%d] %s (pri=%d) at (%d, %d) clicks=%d", i, e.TemplateName, e.Priority, e.Position.X, e.Position.Y, clicks)
 	}
 
-	// Click the highest priority entity
+	// The highest priority entity is the one to click
 	entity := validEntities[0]
-	return b.clickAndVerifyEntry(screenImg, entity)
+	return &entity, 0
+}
+
+// logDetections logs every entity of an entry scan at Info level when verbose
+// detections are on, with why it was or wasn't chosen. valid is sorted best first
+// (valid[0] is the one clicked); the rest of all were blacklisted or vetoed.
+func (b *GlobalBot) logDetections(all, valid []DetectedEntity) {
+	b.mu.Lock()
+	verbose := b.cfg.VerboseDetections
+	b.mu.Unlock()
+	if !verbose {
+		return
+	}
+
+	b.logFunc(fmt.Sprintf("[Detect] %d entities (%d clickable)", len(all), len(valid)))
+	for i, e := range valid {
+		reason := "lower priority"
+		if i == 0 {
+			reason = "chosen"
+		}
+		b.logFunc(fmt.Sprintf("[Detect]   %s (pri=%d) at (%d, %d): %s", e.TemplateName, e.Priority, e.Position.X, e.Position.Y, reason))
+	}
+	for _, e := range all {
+		if containsEntity(valid, e) {
+			continue
+		}
+		reason := "vetoed by anti-template"
+		if b.entryTracker.IsBlacklisted(e) {
+			reason = "blacklisted"
+		}
+		b.logFunc(fmt.Sprintf("[Detect]   %s (pri=%d) at (%d, %d): %s", e.TemplateName, e.Priority, e.Position.X, e.Position.Y, reason))
+	}
+}
+
+// SetVerboseDetections turns Info logging of every entry detection on or off
+func (b *GlobalBot) SetVerboseDetections(enabled bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg.VerboseDetections = enabled
 }
 
 // clickAndVerifyEntry performs click on entity and verifies success using two-step verification
@@ -396,193 +1056,283 @@ func (b *GlobalBot) clickAndVerifyEntry(screenImg image.Image, entity DetectedEn
 
 	b.debugFunc("[Entry] Clicking: %s at center (%d, %d) (click #%d)",
 		entity.TemplateName, center.X, center.Y, clicks+1)
-	b.performClick(entity.TemplateName, entity.Position.X, entity.Position.Y, entity.TemplateSize.X, entity.TemplateSize.Y)
+	if r := entity.Region; !r.Empty() {
+		// Partial match: a plain click on the visible part (no sidecar actions)
+		b.performClick(entity.TemplateName, r.Min.X, r.Min.Y, r.Dx(), r.Dy())
+	} else if target := b.getTargetByName(entity.TemplateName); target != nil {
+		b.clickTarget(*target, entity.Position.X, entity.Position.Y)
+	} else {
+		b.performClick(entity.TemplateName, entity.Position.X, entity.Position.Y, entity.TemplateSize.X, entity.TemplateSize.Y)
+	}
+
+	b.emit(BotEvent{Type: EventEntityClicked, Entity: entity})
 
 	// Record click and update ROI for next iteration
 	blacklisted := b.entryTracker.RecordClick(entity)
 	b.entryTracker.SetLastHighPriority(entity) // Update ROI
 
 	if blacklisted {
-		b.logFunc(fmt.Sprintf("[Entry] Entity %s at (%d,%d) blacklisted after 7 clicks",
-			entity.TemplateName, entity.Position.X, entity.Position.Y))
+		b.emit(BotEvent{Type: EventEntityBlacklisted, Entity: entity})
+		if b.onBlacklisted(screenImg, entity, clicks+1) {
+			return 0
+		}
 	}
 
-	// Two-step verification:
-	// Step 1 (Fast): Check if finding.png disappeared (left entry screen)
-	// Step 2 (Slow): Check for lobby.png, skill.png, or exit.png
+	// Verification runs in StateEntryVerify, one attempt per tick, so Stop stays responsive
+	b.entryVerify = entryVerify{entity: entity, before: screenImg}
+	b.setState(StateEntryVerify, fmt.Sprintf("clicked [%s]", entity.TemplateName))
+	return b.tick.EntryClickWait.D()
+}
 
-	time.Sleep(constants.VerifyPreWait)
+// handleEntryVerifyState runs one attempt of the two-step entry click verification:
+// Step 1 (Fast): Check if finding.png disappeared (left entry screen)
+// Step 2 (Slow): Check for lobby.png, skill.png, or exit.png
+func (b *GlobalBot) handleEntryVerifyState() time.Duration {
+	v := &b.entryVerify
+	v.attempt++
+	attempt := v.attempt
+	entity := v.entity
+	b.setStatus(fmt.Sprintf("Status: Verifying entry click... (%d/%d)", attempt, constants.EntryVerifyAttempts))
+
+	// Each attempt gets a fresh frame (the loop invalidates it every tick)
+	newScreenImg, err := b.captureFrame()
+	if err != nil {
+		b.debugFunc("[Entry] Verify attempt %d: CaptureScreen failed: %v", attempt, err)
+		return b.entryVerifyRetry(constants.VerifyRetryWait)
+	}
 
-	leftEntryScreen := false // Track if we actually left the entry screen
+	// Fast verification: Is finding.png still visible?
+	entryScreenVisible := false
+	if len(b.targetsFinding) == 0 {
+		// No finding.png to look for: the click worked if the clicked region changed
+		region := image.Rectangle{Min: entity.Position, Max: entity.Position.Add(entity.TemplateSize)}.Inset(-constants.RegionChangeMargin)
+		change := screen.RegionChange(v.before, newScreenImg, region, b.tick.Tolerance)
+		b.debugFunc("[Entry] Verify attempt %d: clicked region changed %.0f%%", attempt, change*100)
+		entryScreenVisible = change < constants.RegionChangeThreshold
+	}
+	if _, _, found := b.findAny(newScreenImg, b.targetsFinding, b.tick.Tolerance); found {
+		entryScreenVisible = true
+	}
 
-	// Try verification up to 5 times over ~1.5 seconds
-	for attempt := 1; attempt <= 5; attempt++ {
-		newScreenImg, err := b.searcher.CaptureScreen()
-		if err != nil {
-			b.debugFunc("[Entry] Verify attempt %d: CaptureScreen failed: %v", attempt, err)
-			time.Sleep(constants.VerifyRetryWait)
-			continue
-		}
+	if entryScreenVisible {
+		// Still on entry screen - click didn't work yet
+		b.debugFunc("[Entry] Verify attempt %d: still on entry screen", attempt)
+		return b.entryVerifyRetry(constants.VerifyRetryWait)
+	}
 
-		// Fast verification: Is finding.png still visible?
-		entryScreenVisible := false
-		for _, target := range b.targetsFinding {
-			_, _, found := b.searcher.FindTemplate(newScreenImg, target.Image, constants.DefaultTolerance)
-			if found {
-				entryScreenVisible = true
-				break
-			}
-		}
+	// Entry screen disappeared!
+	v.leftEntryScreen = true
+	b.debugFunc("[Entry] Verify attempt %d: left entry screen", attempt)
 
-		if entryScreenVisible {
-			// Still on entry screen - click didn't work yet
-			b.debugFunc("[Entry] Verify attempt %d: still on entry screen (finding.png visible)", attempt)
-			time.Sleep(constants.VerifyRetryWait)
-			continue
-		}
+	// The lobby/game/exit indicators are only searched inside the verify region (if set)
+	newScreenImg = b.scanRegion("verify", newScreenImg)
 
-		// Entry screen disappeared!
-		leftEntryScreen = true
-		b.debugFunc("[Entry] Verify attempt %d: left entry screen (finding.png gone)", attempt)
+	// Check for lobby.png (waiting in lobby)
+	if target, _, found := b.findAny(newScreenImg, b.targetsLobby, b.tick.Tolerance); found {
+		b.logFunc(fmt.Sprintf("Entered lobby [%s]. Waiting for game to start...", target.Name))
+		b.entryTracker.Reset()
+		b.lobbyWait.Start(time.Now())
+		b.setState(StateEntryWaiting, fmt.Sprintf("lobby [%s] after entry click", target.Name))
+		return b.lobbyWait.Poll
+	}
 
-		// Check for lobby.png (waiting in lobby)
-		for _, target := range b.targetsLobby {
-			_, _, found := b.searcher.FindTemplate(newScreenImg, target.Image, constants.DefaultTolerance)
-			if found {
-				b.logFunc(fmt.Sprintf("Entered lobby [%s]. Waiting for game to start...", target.Name))
-				b.entryTracker.Reset()
-				b.entryWaitCount = 0
-				b.setState(StateEntryWaiting)
-				return 5 * time.Second
-			}
-		}
+	// Check for skill.png (already in game)
+	if target, _, found := b.findAny(newScreenImg, b.targetsSkill, b.tick.Tolerance); found {
+		b.logFunc(fmt.Sprintf("In game! [%s] detected. Entering InGame state...", target.Name))
+		b.entryTracker.Reset()
+		b.setState(StateInGame, fmt.Sprintf("skill [%s] after entry click", target.Name))
+		return b.tick.InGameScanInterval.D()
+	}
 
-		// Check for skill.png (already in game)
-		for _, target := range b.targetsSkill {
-			_, _, found := b.searcher.FindTemplate(newScreenImg, target.Image, constants.DefaultTolerance)
-			if found {
-				b.logFunc(fmt.Sprintf("In game! [%s] detected. Entering InGame state...", target.Name))
-				b.entryTracker.Reset()
-				b.setState(StateInGame)
-				return constants.InGameScanInterval
-			}
-		}
+	// Check for exit.png (game already finished?)
+	if _, _, found := b.findAny(newScreenImg, b.targetsExit, b.tick.Tolerance); found {
+		b.logFunc("Exit button detected. Game already finished?")
+		b.entryTracker.Reset()
+		b.setState(StateExitStep1, "exit button after entry click")
+		return 0
+	}
 
-		// Check for exit.png (game already finished?)
-		for _, target := range b.targetsExit {
-			_, _, found := b.searcher.FindTemplate(newScreenImg, target.Image, constants.DefaultTolerance)
-			if found {
-				b.logFunc("Exit button detected. Game already finished?")
-				b.entryTracker.Reset()
-				b.setState(StateExitStep1)
-				return 0
-			}
-		}
+	// Left entry screen but nothing recognized yet - might be loading, try again
+	b.debugFunc("[Entry] Verify attempt %d: no recognizable state, might be loading...", attempt)
+	return b.entryVerifyRetry(constants.VerifyLoadingWait)
+}
 
-		// Left entry screen but nothing recognized yet - might be loading, try again
-		b.debugFunc("[Entry] Verify attempt %d: no recognizable state, might be loading...", attempt)
-		time.Sleep(constants.VerifyLoadingWait)
+// entryVerifyRetry schedules the next verification attempt after wait,
+// or gives up once all attempts are used
+func (b *GlobalBot) entryVerifyRetry(wait time.Duration) time.Duration {
+	if b.entryVerify.attempt < constants.EntryVerifyAttempts {
+		return wait
 	}
 
 	// Only assume InGame if we actually left the entry screen
-	if leftEntryScreen {
+	if b.entryVerify.leftEntryScreen {
 		b.logFunc("Left entry screen, assuming InGame state...")
 		b.entryTracker.Reset()
-		b.setState(StateInGame)
-		return constants.InGameScanInterval
+		b.setState(StateInGame, "left entry screen")
+		return b.tick.InGameScanInterval.D()
 	}
 
-	// Still on entry screen after 5 attempts - click failed, continue scanning
+	// Still on entry screen after all attempts - click failed, continue scanning
 	b.debugFunc("[Entry] Click verification failed - still on entry screen")
+	b.setState(StateEntry, "entry click not verified")
 	return 0 // Retry immediately
 }
 
 // handleEntryWaitingState waits in lobby for game to start
-// Checks every 5 seconds if lobby.png disappears (game started)
-// After 10 checks (50 seconds), clicks return.png to exit and re-search
+// Polls (default every 5 seconds) until lobby.png disappears (game started)
+// After the lobby timeout (default 50 seconds), clicks return.png to exit and re-search
 func (b *GlobalBot) handleEntryWaitingState() time.Duration {
-	b.entryWaitCount++
-	b.statusFunc(fmt.Sprintf("Status: Waiting in lobby... (%d/10)", b.entryWaitCount))
+	now := time.Now()
+	b.setStatus(fmt.Sprintf("Status: Waiting in lobby... (%ds/%ds)",
+		int(b.lobbyWait.Elapsed(now).Seconds()), int(b.lobbyWait.Timeout.Seconds())))
 
-	screenImg, err := b.searcher.CaptureScreen()
+	screenImg, err := b.captureFrame()
 	if err != nil {
-		return 5 * time.Second
+		return b.lobbyWait.Poll
 	}
 
 	// Check if lobby.png is still visible
-	lobbyVisible := false
-	for _, target := range b.targetsLobby {
-		_, _, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
-		if found {
-			lobbyVisible = true
-			break
-		}
-	}
+	_, _, lobbyVisible := b.findAny(screenImg, b.targetsLobby, b.tick.Tolerance)
 
-	if !lobbyVisible {
+	switch b.lobbyWait.Check(lobbyVisible, now) {
+	case WaitGone:
 		// Lobby disappeared - verify with skill.png that we're in game
-		for _, target := range b.targetsSkill {
-			_, _, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
-			if found {
-				b.logFunc(fmt.Sprintf("Game started! [%s] detected. Switching to InGame state.", target.Name))
-				b.entryWaitCount = 0
-				b.setState(StateInGame)
-				return constants.InGameScanInterval
-			}
+		if target, _, found := b.findAny(screenImg, b.targetsSkill, b.tick.Tolerance); found {
+			b.logFunc(fmt.Sprintf("Game started! [%s] detected. Switching to InGame state.", target.Name))
+			b.setState(StateInGame, fmt.Sprintf("game started [%s]", target.Name))
+			return b.tick.InGameScanInterval.D()
 		}
 		// No skill detected but lobby gone - assume in game anyway
 		b.logFunc("Lobby disappeared, switching to InGame state.")
-		b.entryWaitCount = 0
-		b.setState(StateInGame)
-		return constants.InGameScanInterval
-	}
+		b.setState(StateInGame, "lobby disappeared")
+		return b.tick.InGameScanInterval.D()
 
-	// Still in lobby - check if we've waited too long
-	if b.entryWaitCount >= 10 {
-		b.logFunc("Waited too long in lobby (50s). Exiting to re-search...")
+	case WaitTimedOut:
+		b.logFunc(fmt.Sprintf("Waited too long in lobby (%v). Exiting to re-search...", b.lobbyWait.Timeout))
+		b.stats.Timeouts++
 
 		// Click return.png to exit lobby
-		for _, target := range b.targetsChannelReturn {
-			fx, fy, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
-			if found {
-				b.performClick(target.Name, fx, fy, target.Image.Bounds().Dx(), target.Image.Bounds().Dy())
-				b.logFunc(fmt.Sprintf("Clicked [%s]. Returning to channel selection.", target.Name))
-				break
-			}
+		if target, p, found := b.findAny(screenImg, b.targetsChannelReturn, b.tick.Tolerance); found {
+			b.clickTarget(target, p.X, p.Y)
+			b.logFunc(fmt.Sprintf("Clicked [%s]. Returning to channel selection.", target.Name))
 		}
 
-		b.entryWaitCount = 0
-		b.setState(StateSearchOpen)
-		return constants.SearchScanInterval
+		b.setState(StateSearchOpen, "lobby wait timed out")
+		return b.tick.SearchScanInterval.D()
 	}
 
-	b.debugFunc("[Waiting] lobby.png still visible, wait count=%d", b.entryWaitCount)
-	return 5 * time.Second // Check again in 5 seconds
+	b.debugFunc("[Waiting] lobby.png still visible, waited %v", b.lobbyWait.Elapsed(now))
+
+	// Nothing moving on screen: poll less often, but never past the timeout.
+	// Only this state idles: it is the one long wait on a screen that can sit still.
+	// Entry and the search steps must react within a scan, and InGame already scans
+	// slowly (in_game_scan_interval) on a screen that keeps moving.
+	idle, toggled := b.lobbyIdle.Observe(screenImg)
+	if toggled && idle {
+		b.logFunc("[Waiting] Screen unchanged, entering idle (slower polling).")
+	} else if toggled {
+		b.logFunc("[Waiting] Screen changed, leaving idle.")
+	}
+	interval := b.lobbyIdle.Interval(b.lobbyWait.Poll)
+	if limit := max(b.lobbyWait.Timeout-b.lobbyWait.Elapsed(now), b.lobbyWait.Poll); interval > limit {
+		interval = limit
+	}
+	return interval // Check again after the poll interval
 }
 
 // handleInGameState waits for the game to finish (exit button to appear)
 // Scans at low frequency (30s) since games last 10-20 minutes
 func (b *GlobalBot) handleInGameState() time.Duration {
-	b.statusFunc("Status: In Game (waiting for exit)...")
+	b.setStatus("Status: In Game (waiting for exit)...")
 
-	screenImg, err := b.searcher.CaptureScreen()
+	screenImg, err := b.captureFrame()
 	if err != nil {
-		return constants.InGameScanInterval
+		return b.tick.InGameScanInterval.D()
 	}
 
 	// Check for exit button
-	for _, target := range b.targetsExit {
-		_, _, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
-		if found {
-			b.logFunc("Game finished! Exit button detected.")
-			b.setState(StateExitStep1)
-			return 0
-		}
+	if _, _, found := b.findAny(screenImg, b.targetsExit, b.tick.Tolerance); found {
+		b.logFunc("Game finished! Exit button detected.")
+		b.setState(StateExitStep1, "exit button visible")
+		return 0
 	}
 
 	// Still in game
 	b.debugFunc("[InGame] Exit button not found, continuing to wait...")
-	return constants.InGameScanInterval
+	return b.tick.InGameScanInterval.D()
+}
+
+// scrollEntryList turns the mouse wheel over the entry list to bring off-screen
+// entries into view. It scrolls at the center of the configured scroll region,
+// else of the entry scan region (screenImg), which defaults to the whole display.
+// reason starts the log line.
+func (b *GlobalBot) scrollEntryList(screenImg image.Image, reason string) {
+	area := screenImg.Bounds()
+	if r := b.tick.EntryScrollRegion; r != (config.Region{}) {
+		area = r.Rect()
+	}
+	center := image.Point{X: (area.Min.X + area.Max.X) / 2, Y: (area.Min.Y + area.Max.Y) / 2}
+
+	lines, direction := b.tick.EntryScrollLines, "down"
+	if b.tick.EntryScrollDirection == "up" {
+		lines, direction = -lines, "up"
+	}
+	b.logFunc(fmt.Sprintf("[Entry] %s, scrolling %s %d lines at (%d, %d)",
+		reason, direction, b.tick.EntryScrollLines, center.X, center.Y))
+	if !b.confirmInput("entry list", center.X, center.Y) {
+		return
+	}
+	b.searcher.InvalidateFrame() // The screen is about to change
+	b.clicker().Scroll("entry list", center.X, center.Y, lines)
+	b.entryTracker.Reset() // Positions are stale after scrolling
+}
+
+// findEntry runs the full entry scan of one template through its tolerance tiers.
+// With incremental scanning the strict tier only rescans what changed; looser tiers
+// (rarely reached) scan the whole frame.
+func (b *GlobalBot) findEntry(screenImg image.Image, target Target, tiers []float64) ([]image.Point, int) {
+	if b.incremental == nil {
+		return b.searcher.FindAllTemplatesTiered(screenImg, target.Image, tiers)
+	}
+	if points := b.incremental.FindAll(target.Image, tiers[0]); len(points) > 0 {
+		return points, 0
+	}
+	points, tier := b.searcher.FindAllTemplatesTiered(screenImg, target.Image, tiers[1:])
+	if tier < 0 {
+		return nil, -1
+	}
+	return points, tier + 1
+}
+
+// scanOrder returns targets in the order a first-hit-wins check tries them: as
+// loaded (click priority), or largest first with config.LargestFirstScan
+func (b *GlobalBot) scanOrder(targets []Target) []Target {
+	if !b.tick.LargestFirstScan {
+		return targets
+	}
+	images := make([]image.Image, len(targets))
+	for i, t := range targets {
+		images[i] = t.Image
+	}
+	ordered := make([]Target, len(targets))
+	for i, j := range screen.LargestFirst(images) {
+		ordered[i] = targets[j]
+	}
+	return ordered
+}
+
+// findAny returns the first of targets found on screenImg (in scanOrder) and its top-left
+func (b *GlobalBot) findAny(screenImg image.Image, targets []Target, tolerance float64) (Target, image.Point, bool) {
+	targets = b.scanOrder(targets)
+	images := make([]image.Image, len(targets))
+	for i, t := range targets {
+		images[i] = t.Image
+	}
+	i, p, found := b.searcher.FindAny(screenImg, images, tolerance)
+	if !found {
+		return Target{}, p, false
+	}
+	return targets[i], p, true
 }
 
 // getTargetByName finds a target by its name
@@ -596,59 +1346,199 @@ func (b *GlobalBot) getTargetByName(name string) *Target {
 }
 
 func (b *GlobalBot) handleExitState() time.Duration {
-	b.statusFunc("Status: Clicking Exit...")
+	b.setStatus("Status: Clicking Exit...")
 
-	screenImg, err := b.searcher.CaptureScreen()
-	if err != nil { return 10 * time.Second }
+	screenImg, err := b.captureFrame()
+	if err != nil { return constants.ExitCaptureRetryInterval }
+	screenImg = b.scanRegion("exit", screenImg)
 
-	for _, target := range b.targetsExit {
-		fx, fy, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
-		if found {
-			b.performClick(target.Name, fx, fy, target.Image.Bounds().Dx(), target.Image.Bounds().Dy())
-			time.Sleep(constants.WaitAfterClickNormal)
-			b.logFunc("Clicked exit. Waiting for out.png...")
-			b.setState(StateExitStep2)
-			return constants.WaitAfterClickNormal
-		}
+	if target, p, found := b.findAny(screenImg, b.targetsExit, b.tolerance("exit")); found {
+		b.clickTarget(target, p.X, p.Y)
+		b.logFunc("Clicked exit. Waiting for out.png...")
+		b.setState(StateExitStep2, "clicked exit")
+		return b.tick.ExitClickWait.D()
 	}
-	return 5 * time.Second
+	return constants.ExitRetryInterval
 }
 
 // handleExitStep2State waits for out.png to appear and clicks it to return to search flow
 func (b *GlobalBot) handleExitStep2State() time.Duration {
-	b.statusFunc("Status: Waiting for out.png...")
+	b.setStatus("Status: Waiting for out.png...")
 
-	screenImg, err := b.searcher.CaptureScreen()
+	screenImg, err := b.captureFrame()
 	if err != nil { return constants.SearchRetryInterval }
+	screenImg = b.scanRegion("channel", screenImg)
 
-	for _, target := range b.targetsChannelReturn {
-		fx, fy, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
-		if found {
-			b.performClick(target.Name, fx, fy, target.Image.Bounds().Dx(), target.Image.Bounds().Dy())
-			time.Sleep(constants.WaitAfterClickNormal)
-			b.logFunc("Clicked out.png. Switching to Search Flow.")
-			b.setState(StateSearchOpen)
-			return constants.SearchScanInterval
-		}
+	if target, p, found := b.findAny(screenImg, b.targetsChannelReturn, b.tolerance("exit")); found {
+		b.clickTarget(target, p.X, p.Y)
+		b.logFunc("Clicked out.png. Switching to Search Flow.")
+		b.setState(StateSearchOpen, "clicked return")
+		return b.tick.SearchClickWait.D() + b.tick.SearchScanInterval.D()
 	}
 
 	b.debugFunc("[ExitStep2] out.png not found, waiting...")
 	return constants.SearchRetryInterval
 }
 
+// scanRegion confines matching for a feature to its configured active scan region.
+// The returned SubImage keeps frame coordinates, so match results are already in
+// global frame coordinates and can be clicked as-is.
+func (b *GlobalBot) scanRegion(feature string, img image.Image) image.Image {
+	r, ok := b.tick.ScanRegions[feature]
+	if !ok {
+		return img
+	}
+	sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return img
+	}
+	area := r.Rect().Intersect(img.Bounds())
+	if area.Empty() {
+		b.debugFunc("[Region] %s region %v is off-screen, scanning full frame", feature, r.Rect())
+		return img
+	}
+	return sub.SubImage(area)
+}
+
+// tolerance returns the match tolerance for a feature (see config.ToleranceFeatures),
+// falling back to the global tolerance when the feature has none of its own
+func (b *GlobalBot) tolerance(feature string) float64 {
+	if tol, ok := b.tick.FeatureTolerances[feature]; ok {
+		return tol
+	}
+	return b.tick.Tolerance
+}
+
+// SetFeatureTolerance sets (or, with tolerance 0, clears) a feature's own tolerance
+func (b *GlobalBot) SetFeatureTolerance(feature string, tolerance float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tolerances := make(map[string]float64, len(b.cfg.FeatureTolerances)+1)
+	for k, v := range b.cfg.FeatureTolerances {
+		tolerances[k] = v
+	}
+	if tolerance <= 0 {
+		delete(tolerances, feature)
+	} else {
+		tolerances[feature] = tolerance
+	}
+	b.cfg.FeatureTolerances = tolerances
+}
+
+// SetTolerance sets the global tolerance, used by every feature without its own
+func (b *GlobalBot) SetTolerance(tolerance float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg.Tolerance = tolerance
+}
+
+// SetColorCalibration sets (or, with the identity, clears) the color correction applied
+// to captured frames; it takes effect on the next capture
+func (b *GlobalBot) SetColorCalibration(n screen.Normalization) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cfg.ColorCalibration = config.NewColorCalibration(n)
+	b.searcher.SetNormalization(n)
+}
+
+// SetScanRegion sets (or, with an empty rectangle, clears) a feature's active scan region
+func (b *GlobalBot) SetScanRegion(feature string, r image.Rectangle) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	regions := make(map[string]config.Region, len(b.cfg.ScanRegions)+1)
+	for k, v := range b.cfg.ScanRegions {
+		regions[k] = v
+	}
+	if r.Empty() {
+		delete(regions, feature)
+	} else {
+		regions[feature] = config.NewRegion(r)
+	}
+	b.cfg.ScanRegions = regions
+}
+
+// hashHit checks whether target is still exactly where it was last found, by comparing
+// the AverageHash of that region with the template's. Much cheaper than any scan.
+func (b *GlobalBot) hashHit(screenImg image.Image, target Target) (image.Point, bool) {
+	last, ok := b.searchPositions[target.Name]
+	if !ok || !target.Hashable {
+		return image.Point{}, false
+	}
+	region := image.Rectangle{Min: last, Max: last.Add(target.Image.Bounds().Size())}
+	hash, ok := screen.AverageHash(screenImg, region)
+	if !ok {
+		return image.Point{}, false
+	}
+	if dist := screen.HammingDistance(hash, target.Hash); dist > constants.HashMaxDistance {
+		b.debugFunc("[Hash] %s moved or changed (distance %d)", target.Name, dist)
+		return image.Point{}, false
+	}
+	return last, true
+}
+
+// findFixed looks for a target that usually stays put (auto-detect state markers):
+// hash check at its last position first, full-screen scan otherwise
+func (b *GlobalBot) findFixed(screenImg image.Image, target Target) (int, int, bool) {
+	if p, ok := b.hashHit(screenImg, target); ok {
+		return p.X, p.Y, true
+	}
+	fx, fy, found := b.findFromLast(screenImg, target, b.tick.Tolerance)
+	if found {
+		b.searchPositions[target.Name] = image.Point{X: fx, Y: fy}
+	}
+	return fx, fy, found
+}
+
+// findFromLast scans the whole frame for target: center-out from its last position
+// when enabled (config.CenterOutScan) and known, top to bottom otherwise
+func (b *GlobalBot) findFromLast(screenImg image.Image, target Target, tolerance float64) (int, int, bool) {
+	if last, ok := b.searchPositions[target.Name]; ok && b.tick.CenterOutScan {
+		p, found := b.searcher.FindFirstNear(screenImg, target.Image, screenImg.Bounds(), last, tolerance)
+		return p.X, p.Y, found
+	}
+	return b.searcher.FindTemplate(screenImg, target.Image, tolerance)
+}
+
+// findNearLast looks for a search-step target at its last position (hash check),
+// then in a small ROI around it (like the entry ROI fast path), falling back to a full-screen scan
+func (b *GlobalBot) findNearLast(screenImg image.Image, target Target, tolerance float64) (int, int, bool) {
+	if p, ok := b.hashHit(screenImg, target); ok {
+		b.debugFunc("[Search] Hash hit: %s at (%d, %d)", target.Name, p.X, p.Y)
+		return p.X, p.Y, true
+	}
+	if last, ok := b.searchPositions[target.Name]; ok {
+		size := image.Point{X: target.Image.Bounds().Dx(), Y: target.Image.Bounds().Dy()}
+		roi := image.Rectangle{Min: last, Max: last.Add(size)}.Inset(-constants.SearchROIMargin)
+		if points := b.searcher.FindAllTemplatesInROI(screenImg, target.Image, roi, tolerance); len(points) > 0 {
+			b.debugFunc("[Search] ROI hit: %s at (%d, %d)", target.Name, points[0].X, points[0].Y)
+			b.searchPositions[target.Name] = points[0]
+			return points[0].X, points[0].Y, true
+		}
+		b.debugFunc("[Search] ROI miss: %s, falling back to full screen", target.Name)
+	}
+
+	fx, fy, found := b.findFromLast(screenImg, target, tolerance)
+	if found {
+		b.searchPositions[target.Name] = image.Point{X: fx, Y: fy}
+	}
+	return fx, fy, found
+}
+
 func (b *GlobalBot) handleSearchOpenState() time.Duration {
-	b.statusFunc(fmt.Sprintf("Status: Searching [Open List]... (%d/%d)", b.searchRetryCount, constants.SearchMaxRetries))
-	screenImg, err := b.searcher.CaptureScreen()
+	b.setStatus(fmt.Sprintf("Status: Searching [Open List]... (%d/%d)", b.searchRetryCount, constants.SearchMaxRetries))
+	screenImg, err := b.captureFrame()
 	if err != nil { return constants.SearchRetryInterval }
+	screenImg = b.scanRegion("channel", screenImg)
 
 	for _, target := range b.targetsChannelOpen {
-		fx, fy, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
+		fx, fy, found := b.findNearLast(screenImg, target, b.tolerance("search_open"))
 		if found {
-			b.performClick(target.Name, fx, fy, target.Image.Bounds().Dx(), target.Image.Bounds().Dy())
-			time.Sleep(constants.WaitAfterClickNormal)
+			b.clickTarget(target, fx, fy)
 			b.searchRetryCount = 0 // Reset counter on success
-			b.setState(StateSearchSelect)
-			return constants.WaitAfterClickNormal
+			b.setState(StateSearchSelect, "clicked open")
+			return b.tick.SearchClickWait.D()
 		}
 	}
 
@@ -656,25 +1546,26 @@ func (b *GlobalBot) handleSearchOpenState() time.Duration {
 	if b.searchRetryCount >= constants.SearchMaxRetries {
 		b.logFunc("SearchOpen: Max retries reached. Falling back to AutoDetect.")
 		b.searchRetryCount = 0
-		b.setState(StateAutoDetect)
+		b.searchPositions = make(map[string]image.Point) // Layout may have changed
+		b.setState(StateAutoDetect, "open not found after max retries")
 		return constants.SearchRetryInterval
 	}
 	return constants.SearchRetryInterval
 }
 
 func (b *GlobalBot) handleSearchSelectState() time.Duration {
-	b.statusFunc(fmt.Sprintf("Status: Searching [Target Channel]... (%d/%d)", b.searchRetryCount, constants.SearchMaxRetries))
-	screenImg, err := b.searcher.CaptureScreen()
+	b.setStatus(fmt.Sprintf("Status: Searching [Target Channel]... (%d/%d)", b.searchRetryCount, constants.SearchMaxRetries))
+	screenImg, err := b.captureFrame()
 	if err != nil { return constants.SearchRetryInterval }
+	screenImg = b.scanRegion("channel", screenImg)
 
 	for _, target := range b.targetsChannelSelect {
-		fx, fy, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
+		fx, fy, found := b.findNearLast(screenImg, target, b.tolerance("search_select"))
 		if found {
-			b.performClick(target.Name, fx, fy, target.Image.Bounds().Dx(), target.Image.Bounds().Dy())
-			time.Sleep(constants.WaitAfterClickNormal)
+			b.clickTarget(target, fx, fy)
 			b.searchRetryCount = 0 // Reset counter on success
-			b.setState(StateSearchVerify)
-			return constants.WaitAfterClickNormal
+			b.setState(StateSearchVerify, "clicked select")
+			return b.tick.SearchClickWait.D()
 		}
 	}
 
@@ -682,26 +1573,27 @@ func (b *GlobalBot) handleSearchSelectState() time.Duration {
 	if b.searchRetryCount >= constants.SearchMaxRetries {
 		b.logFunc("SearchSelect: Max retries reached. Falling back to AutoDetect.")
 		b.searchRetryCount = 0
-		b.setState(StateAutoDetect)
+		b.searchPositions = make(map[string]image.Point) // Layout may have changed
+		b.setState(StateAutoDetect, "select not found after max retries")
 		return constants.SearchRetryInterval
 	}
 	return constants.SearchRetryInterval
 }
 
 func (b *GlobalBot) handleSearchVerifyState() time.Duration {
-	b.statusFunc(fmt.Sprintf("Status: Verifying Highlight... (%d/%d)", b.searchRetryCount, constants.SearchMaxRetries))
-	screenImg, err := b.searcher.CaptureScreen()
+	b.setStatus(fmt.Sprintf("Status: Verifying Highlight... (%d/%d)", b.searchRetryCount, constants.SearchMaxRetries))
+	screenImg, err := b.captureFrame()
 	if err != nil { return constants.SearchRetryInterval }
 
 	for _, target := range b.targetsFinding {
-		_, _, found := b.searcher.FindTemplate(screenImg, target.Image, constants.DefaultTolerance)
+		_, _, found := b.findNearLast(screenImg, target, b.tolerance("search_verify"))
 		if found {
 			b.logFunc(fmt.Sprintf("Verified Highlight [%s]. Cycle Complete.", target.Name))
 			b.searchRetryCount = 0 // Reset counter on success
+			b.verifyFailures = 0
 			b.entryTracker.Reset() // Reset tracker for new entry cycle
-			time.Sleep(constants.WaitAfterClickNormal)
-			b.setState(StateEntry)
-			return 0 // Start entry scanning immediately
+			b.setState(StateEntry, fmt.Sprintf("verified highlight [%s]", target.Name))
+			return b.tick.SearchClickWait.D()
 		}
 	}
 
@@ -709,25 +1601,244 @@ func (b *GlobalBot) handleSearchVerifyState() time.Duration {
 	if b.searchRetryCount >= constants.SearchMaxRetries {
 		b.logFunc("SearchVerify: Max retries reached. Falling back to AutoDetect.")
 		b.searchRetryCount = 0
-		b.setState(StateAutoDetect)
+		b.searchPositions = make(map[string]image.Point) // Layout may have changed
+		if b.verifyFailed() {
+			return constants.SearchRetryInterval // Stopping
+		}
+		b.setState(StateAutoDetect, "finding not found after max retries")
 		return constants.SearchRetryInterval
 	}
 	return constants.SearchRetryInterval
 }
 
+// verifyFailed counts a search cycle whose highlight was never verified and escalates
+// once VerifyFailThreshold cycles fail in a row (e.g. a wrong channel template would
+// otherwise loop search -> select -> verify forever). Returns true if the bot is stopping.
+func (b *GlobalBot) verifyFailed() bool {
+	b.verifyFailures++
+	if b.tick.VerifyFailThreshold == 0 || b.verifyFailures < b.tick.VerifyFailThreshold {
+		return false
+	}
+
+	msg := fmt.Sprintf("Search verify failed %d cycles in a row: check the channel and finding templates", b.verifyFailures)
+	b.verifyFailures = 0
+	if b.errorFunc != nil {
+		b.errorFunc(msg)
+	} else {
+		b.logFunc(msg)
+	}
+	if b.notifyFunc != nil {
+		b.notifyFunc("Search not progressing", msg)
+	}
+	if b.tick.StopOnVerifyFailure {
+		b.logFunc("Auto-stopping due to search verify failures.")
+		b.autoStop()
+		return true
+	}
+	return false
+}
+
 func (b *GlobalBot) performClick(name string, x, y, w, h int) {
-	centerX := x + w/2
-	centerY := y + h/2
-	globalX := centerX + b.displayOffsetX
-	globalY := centerY + b.displayOffsetY
-	
-	b.debugFunc(fmt.Sprintf("Clicking [%s] Center(%d, %d) [Global: %d, %d]", name, centerX, centerY, globalX, globalY))
-	robotgo.MoveMouse(globalX, globalY)
-	robotgo.Click("left")
+	if !b.confirmInput(name, x+w/2, y+h/2) {
+		return
+	}
+	b.searcher.InvalidateFrame() // The screen is about to change
+	b.clicker().Click(name, x, y, w, h)
+}
+
+// clicker builds the shared click path from the current display offset and config
+func (b *GlobalBot) clicker() *input.Clicker {
+	return &input.Clicker{
+		Actions:   b.actions,
+		OffsetX:   b.displayOffsetX,
+		OffsetY:   b.displayOffsetY,
+		DryRun:    b.tick.DryRun,
+		LogFunc:   b.logFunc,
+		DebugFunc: b.debugFunc,
+		Display:   b.searcher.DisplayIndex(),
+		Scale:     b.displayScale,
+		Gate:      b.gate,
+		Record: func(r input.ClickRecord) {
+			if err := b.clickLog.Record(r); err != nil {
+				b.debugFunc("Failed to write %s: %v", ClickLogPath, err)
+			}
+			b.reportClick(r.Name, r.Global)
+		},
+	}
+}
+
+// clickTarget clicks a matched target at (x, y) and runs its click sequence, if any.
+// Keyboard-action targets press their key instead; hold and drag targets (sidecar)
+// press and hold or drag instead of clicking.
+func (b *GlobalBot) clickTarget(target Target, x, y int) {
+	if !b.confirmInput(target.Name, x+target.Image.Bounds().Dx()/2, y+target.Image.Bounds().Dy()/2) {
+		return
+	}
+	if target.Key != "" {
+		b.performKeyTap(target.Name, target.Key)
+		return
+	}
+	if target.Drag != nil {
+		b.searcher.InvalidateFrame() // The screen is about to change
+		b.clicker().Drag(target.Name, x, y, target.Image.Bounds().Dx(), target.Image.Bounds().Dy(), target.Drag.X, target.Drag.Y)
+	} else if target.Hold > 0 || target.Hover > 0 {
+		b.searcher.InvalidateFrame() // The screen is about to change
+		c := b.clicker()
+		c.Hold = target.Hold
+		c.Hover = target.Hover
+		if target.HoverVerify {
+			c.StillThere = func() bool { return b.stillThere(target, x, y) }
+		}
+		c.Click(target.Name, x, y, target.Image.Bounds().Dx(), target.Image.Bounds().Dy())
+	} else {
+		b.performClick(target.Name, x, y, target.Image.Bounds().Dx(), target.Image.Bounds().Dy())
+	}
+	b.runSequence(target, x, y)
+}
+
+// stillThere captures a fresh frame and reports whether target is still at (x, y),
+// give or take constants.HoverVerifyMargin (e.g. after a hover moved the UI)
+func (b *GlobalBot) stillThere(target Target, x, y int) bool {
+	frame, err := b.searcher.CaptureScreen()
+	if err != nil {
+		b.debugFunc("[Hover] Re-verify capture failed: %v", err)
+		return false
+	}
+	area := image.Rectangle{Min: image.Pt(x, y), Max: image.Pt(x, y).Add(target.Image.Bounds().Size())}.Inset(-constants.HoverVerifyMargin)
+	return len(b.searcher.FindAllTemplatesInROI(frame, target.Image, area, b.tick.Tolerance)) > 0
+}
+
+func (b *GlobalBot) performKeyTap(name, key string) {
+	b.debugFunc("Pressing key [%s] for [%s]", key, name)
+	b.searcher.InvalidateFrame() // The screen is about to change
+	if b.tick.DryRun {
+		b.logFunc(fmt.Sprintf("[DryRun] Would press [%s] for [%s]", key, name))
+		return
+	}
+	if b.gate.Held() {
+		b.logFunc(fmt.Sprintf("[Paused] Skipped key [%s] for [%s]", key, name))
+		return
+	}
+	b.actions.KeyTap(key)
+}
+
+// runSequence performs the target's extra click steps, relative to its center
+func (b *GlobalBot) runSequence(target Target, x, y int) {
+	if len(target.Sequence) == 0 {
+		return
+	}
+
+	w, h := target.Image.Bounds().Dx(), target.Image.Bounds().Dy()
+	for i, step := range target.Sequence {
+		if !b.wait(step.Wait.D()) {
+			return
+		}
+		b.debugFunc("[Sequence] %s step %d/%d: offset (%d, %d)", target.Name, i+1, len(target.Sequence), step.OffsetX, step.OffsetY)
+		// A 0x0 size makes performClick click exactly at the offset point
+		b.performClick(fmt.Sprintf("%s#%d", target.Name, i+1), x+w/2+step.OffsetX, y+h/2+step.OffsetY, 0, 0)
+	}
+}
+
+// LocateGameDisplay captures every display and returns the first one showing any of
+// the state marker templates (entry screen, lobby, in game, channel buttons).
+// The bot must be stopped; the caller selects the returned display.
+func (b *GlobalBot) LocateGameDisplay() (int, error) {
+	b.mu.Lock()
+	if b.State != StateStopped {
+		b.mu.Unlock()
+		return 0, fmt.Errorf("stop the bot first")
+	}
+	if err := b.loadAllAssets(); err != nil {
+		b.mu.Unlock()
+		return 0, err
+	}
+	var markers []Target
+	for _, targets := range [][]Target{
+		b.targetsFinding, b.targetsLobby, b.targetsSkill, b.targetsExit,
+		b.targetsChannelReturn, b.targetsChannelOpen, b.targetsChannelSelect, b.targetsGames,
+	} {
+		markers = append(markers, targets...)
+	}
+	tolerance := b.cfg.Tolerance
+	b.mu.Unlock()
+
+	images := make([]image.Image, len(markers))
+	for i, t := range markers {
+		images[i] = t.Image
+	}
+	displays := screen.ActiveDisplays()
+	frames := make([]image.Image, len(displays))
+	for i := range displays {
+		img, err := screen.CaptureDisplay(i)
+		if err != nil {
+			b.debugFunc("LocateGameDisplay: %v", err)
+			continue
+		}
+		frames[i] = img
+	}
+
+	d, t, found := b.searcher.LocateDisplay(frames, images, tolerance)
+	if !found {
+		return 0, fmt.Errorf("no game template found on %d displays", len(displays))
+	}
+	b.logFunc(fmt.Sprintf("Game found on display %d ([%s] matched)", d, markers[t].Name))
+	return d, nil
+}
+
+// warnResolutionMismatch logs the templates that were cropped on a display of a
+// different size than the one being captured; those rarely match ("it worked yesterday")
+func (b *GlobalBot) warnResolutionMismatch() {
+	displays := screen.ActiveDisplays()
+	display := b.searcher.DisplayIndex()
+	if display >= len(displays) {
+		return
+	}
+	current := displays[display].Size()
+
+	mismatched := make(map[image.Point][]string) // Source size -> template names
+	for _, targets := range b.targetGroups() {
+		for _, t := range targets {
+			if t.Source != (image.Point{}) && t.Source != current {
+				mismatched[t.Source] = append(mismatched[t.Source], t.Name)
+			}
+		}
+	}
+	for source, names := range mismatched {
+		b.logFunc(fmt.Sprintf("Warning: display %d is %s but these templates were cropped at %s and may not match: %s",
+			display, FormatResolution(current), FormatResolution(source), strings.Join(names, ", ")))
+	}
+}
+
+// warnUnmatchableTemplates names every template that is empty or larger than the
+// capture of the current display; the matcher skips those, so they would never match
+func (b *GlobalBot) warnUnmatchableTemplates() {
+	displays := screen.ActiveDisplays()
+	display := b.searcher.DisplayIndex()
+	if display >= len(displays) {
+		return
+	}
+	current := displays[display].Size()
+
+	for _, targets := range b.targetGroups() {
+		for _, t := range targets {
+			if err := screen.CheckTemplateSize(t.Image, current); err != nil {
+				b.logFunc(fmt.Sprintf("Warning: skipping template %s on display %d: %v", t.Name, display, err))
+			}
+		}
+	}
+}
+
+// targetGroups returns every loaded target list
+func (b *GlobalBot) targetGroups() [][]Target {
+	return [][]Target{
+		b.targetsGames, b.targetsFinding, b.targetsAnti, b.targetsLobby, b.targetsSkill, b.targetsExit,
+		b.targetsChannelReturn, b.targetsChannelOpen, b.targetsChannelSelect,
+	}
 }
 
 func (b *GlobalBot) loadAllAssets() error {
 	var err error
+	b.corruptAssets = 0
 
 	// find_game/
 	b.targetsGames, err = b.loadTargets("find_game/games")
@@ -736,6 +1847,8 @@ func (b *GlobalBot) loadAllAssets() error {
 	b.targetsFinding, err = b.loadSpecificTarget("find_game", "finding.png")
 	if err != nil { b.debugFunc("Warning: No finding.png target found.") }
 
+	b.targetsAnti, _ = b.loadTargets("find_game/anti") // Optional
+
 	// waiting/
 	b.targetsLobby, err = b.loadSpecificTarget("waiting", "lobby.png")
 	if err != nil { b.debugFunc("Warning: No lobby.png target found.") }
@@ -757,41 +1870,150 @@ func (b *GlobalBot) loadAllAssets() error {
 	b.targetsChannelSelect, err = b.loadSpecificTarget("channel", "select.png")
 	if err != nil { b.debugFunc("Warning: No select.png target found.") }
 
-	b.logFunc(fmt.Sprintf("Loaded Assets: Games=%d, Finding=%d, Lobby=%d, Skill=%d, Exit=%d, Channel(return/open/select)=%d/%d/%d",
-		len(b.targetsGames), len(b.targetsFinding), len(b.targetsLobby),
+	b.logFunc(fmt.Sprintf("Loaded Assets: Games=%d, Finding=%d, Anti=%d, Lobby=%d, Skill=%d, Exit=%d, Channel(return/open/select)=%d/%d/%d, Corrupt (skipped)=%d",
+		len(b.targetsGames), len(b.targetsFinding), len(b.targetsAnti), len(b.targetsLobby),
 		len(b.targetsSkill), len(b.targetsExit),
-		len(b.targetsChannelReturn), len(b.targetsChannelOpen), len(b.targetsChannelSelect)))
+		len(b.targetsChannelReturn), len(b.targetsChannelOpen), len(b.targetsChannelSelect),
+		b.corruptAssets))
 	return nil
 }
 
-// loadSpecificTarget loads a specific file from a subdirectory
+// SetEmbeddedAssets replaces the fallback templates used where AssetsDir lacks one
+// (laid out like AssetsDir; nil = none). Applied on the next Start.
+func (b *GlobalBot) SetEmbeddedAssets(fsys fs.FS) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.embedded = fsys
+}
+
+// loadImage decodes a template, reporting files that exist but cannot be decoded
+// (truncated or not a PNG) as errors, since they would otherwise silently drop out
+func (b *GlobalBot) loadImage(file assets.File) (image.Image, error) {
+	img, err := b.decodeTemplate(file)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		b.corruptAssets++
+		msg := fmt.Sprintf("Skipping corrupt template: %v", err)
+		if b.errorFunc != nil {
+			b.errorFunc(msg)
+		} else {
+			b.logFunc(msg)
+		}
+	}
+	return img, err
+}
+
+// decodeTemplate reads and decodes a template from disk or the embedded set
+func (b *GlobalBot) decodeTemplate(file assets.File) (image.Image, error) {
+	if !file.Embedded() {
+		return b.searcher.LoadImage(file.Path)
+	}
+	r, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return b.searcher.DecodeImage(r, "embedded "+file.Path)
+}
+
+// loadSpecificTarget loads a specific file from a subdirectory (embedded if not on disk)
 func (b *GlobalBot) loadSpecificTarget(subDir, filename string) ([]Target, error) {
-	path := filepath.Join(b.AssetsDir, subDir, filename)
-	img, err := b.searcher.LoadImage(path)
+	file, err := assets.Find(b.AssetsDir, b.embedded, subDir, filename)
+	if err != nil {
+		return nil, err
+	}
+	img, err := b.loadImage(file)
 	if err != nil {
 		return nil, err
 	}
-	return []Target{{Name: filename, Image: img}}, nil
+	return []Target{b.newTarget(file, img)}, nil
 }
 
+// loadTargets loads every template of a subdirectory, on disk and embedded (a file
+// on disk overrides the embedded one of the same name)
 func (b *GlobalBot) loadTargets(subDir string) ([]Target, error) {
-	path := filepath.Join(b.AssetsDir, subDir, "*.png")
-	files, err := filepath.Glob(path)
+	files, err := assets.Glob(b.AssetsDir, b.embedded, subDir, "*.png")
 	if err != nil { return nil, err }
 
-	// Sort games by priority (higher number first)
-	if subDir == "find_game/games" {
-		sort.Sort(sort.Reverse(sort.StringSlice(files)))
-	} else {
-		sort.Strings(files)
+	// Sort games by priority (higher number first, unless LowestFirst)
+	if subDir == "find_game/games" && b.entryOrder == HighestFirst {
+		sort.SliceStable(files, func(i, j int) bool { return files[i].Name > files[j].Name })
 	}
 	
 	var targets []Target
+	var disabled []string
+	embedded := 0
 	for _, file := range files {
-		img, err := b.searcher.LoadImage(file)
+		if !ParseTargetName(file.Name).Enabled {
+			disabled = append(disabled, file.Name)
+			continue
+		}
+		img, err := b.loadImage(file)
 		if err != nil { continue }
-		name := filepath.Base(file)
-		targets = append(targets, Target{Name: name, Image: img})
+		targets = append(targets, b.newTarget(file, img))
+		if file.Embedded() {
+			embedded++
+		}
+	}
+	if embedded > 0 {
+		b.debugFunc("Loaded %d embedded default template(s) for %s", embedded, subDir)
+	}
+	if len(disabled) > 0 {
+		b.logFunc(fmt.Sprintf("Skipped disabled templates in %s: %s", subDir, strings.Join(disabled, ", ")))
 	}
 	return targets, nil
 }
+
+// newTarget builds a Target from a loaded template, applying its optional sidecar
+// (click sequence, chroma key, hold, drag) and keyboard-action filename
+func (b *GlobalBot) newTarget(file assets.File, img image.Image) Target {
+	name := file.Name
+	target := Target{Name: name, Image: img, Key: b.keyAction(name)}
+
+	sc, err := loadFileSidecar(file)
+	if err != nil {
+		b.logFunc(fmt.Sprintf("Warning: ignoring sidecar for %s: %v", name, err))
+		sc = Sidecar{}
+	}
+
+	if len(sc.Steps) > 0 {
+		target.Sequence = sc.Steps
+		b.debugFunc("Loaded %d-step click sequence for %s", len(sc.Steps), name)
+	}
+	if sc.Hold > 0 {
+		target.Hold = sc.Hold.D()
+		b.debugFunc("Loaded %v hold for %s", target.Hold, name)
+	}
+	if sc.SourceResolution != "" {
+		target.Source, _ = ParseResolution(sc.SourceResolution) // Validated by LoadSidecar
+	}
+	if sc.Hover > 0 {
+		target.Hover, target.HoverVerify = sc.Hover.D(), sc.HoverVerify
+		b.debugFunc("Loaded %v hover for %s (re-verify: %v)", target.Hover, name, target.HoverVerify)
+	}
+	if sc.MatchFraction > 0 {
+		target.MatchFraction = sc.MatchFraction
+		b.debugFunc("Loaded partial match fraction %.2f for %s", target.MatchFraction, name)
+	}
+	if sc.Drag != nil {
+		target.Drag = &image.Point{X: sc.Drag.OffsetX, Y: sc.Drag.OffsetY}
+		b.debugFunc("Loaded drag (%d, %d) for %s", sc.Drag.OffsetX, sc.Drag.OffsetY, name)
+	}
+	if sc.ChromaKey != "" {
+		key, _ := ParseHexColor(sc.ChromaKey) // Validated by LoadSidecar
+		target.Image = screen.ApplyChromaKey(img, key, constants.ChromaKeyTolerance)
+		b.debugFunc("Applied chroma key %s to %s", sc.ChromaKey, name)
+	}
+
+	target.Hash, target.Hashable = screen.TemplateHash(target.Image)
+	return target
+}
+
+// keyAction returns the key for a keyboard-action template, warning about unknown key names
+func (b *GlobalBot) keyAction(filename string) string {
+	key := ParseTargetName(filename).Key
+	if key != "" && !IsSupportedKey(key) {
+		b.logFunc(fmt.Sprintf("Warning: %s uses unsupported key %q, it will be clicked instead", filename, key))
+		return ""
+	}
+	return key
+}
@@ -0,0 +1,554 @@
+package global
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/config"
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/input"
+)
+
+var background = color.RGBA{50, 50, 50, 255}
+
+// newScreen returns a w x h frame filled with the background color
+func newScreen(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetRGBA(x, y, background)
+		}
+	}
+	return img
+}
+
+// newTemplate returns an opaque patterned template whose red channel is raised by shift.
+// Every pixel differs from the background by more than the default tolerance.
+func newTemplate(w, h, shift int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(150 + shift), uint8(x * 10), uint8(y * 10), 255})
+		}
+	}
+	return img
+}
+
+// paste copies the opaque pixels of tpl onto scr with the top-left corner at (x, y)
+func paste(scr *image.RGBA, tpl *image.NRGBA, x, y int) {
+	b := tpl.Bounds()
+	for ty := b.Min.Y; ty < b.Max.Y; ty++ {
+		for tx := b.Min.X; tx < b.Max.X; tx++ {
+			if c := tpl.NRGBAAt(tx, ty); c.A != 0 {
+				scr.Set(x+tx-b.Min.X, y+ty-b.Min.Y, c)
+			}
+		}
+	}
+}
+
+// frameSource stands in for the display: every capture returns frame
+type frameSource struct {
+	frame    image.Image
+	captures int
+}
+
+func (s *frameSource) capture(int) (image.Image, error) {
+	s.captures++
+	return s.frame, nil
+}
+
+// recordedActions records where clicks and scrolls land instead of performing them
+type recordedActions struct {
+	at      image.Point
+	clicks  []image.Point
+	scrolls []image.Point
+}
+
+func (a *recordedActions) MoveMouse(x, y int)    { a.at = image.Point{X: x, Y: y} }
+func (a *recordedActions) MoveSmooth(x, y int)   { a.at = image.Point{X: x, Y: y} }
+func (a *recordedActions) Click(string)          { a.clicks = append(a.clicks, a.at) }
+func (a *recordedActions) Toggle(string, string) {}
+func (a *recordedActions) Scroll(int, int)       { a.scrolls = append(a.scrolls, a.at) }
+func (a *recordedActions) KeyTap(string)         {}
+
+// newFrameBot returns a test bot that captures from a frameSource showing frame and
+// records its clicks. Set config through b.cfg; runTick takes a snapshot like the loop.
+func newFrameBot(t *testing.T, frame image.Image) (*GlobalBot, *frameSource, *recordedActions) {
+	t.Helper()
+	b := newTestBot()
+	src := &frameSource{frame: frame}
+	b.searcher.SetCaptureRetry(0, 0)
+	b.searcher.SetCapturer(src.capture)
+	actions := &recordedActions{}
+	b.SetActions(actions)
+	b.clickLog = input.NewClickLog(filepath.Join(t.TempDir(), "clicks.csv"))
+	b.snapshot()
+	return b, src, actions
+}
+
+// runTick runs one tick of the current state as the loop does and returns its interval
+func runTick(b *GlobalBot) time.Duration {
+	b.snapshot()
+	b.searcher.InvalidateFrame()
+	return b.processState()
+}
+
+func TestCaptureFailureEscalation(t *testing.T) {
+	b := newTestBot()
+	var logs, errs, notes []string
+	b.logFunc = func(msg string) { logs = append(logs, msg) }
+	b.SetAlertFuncs(
+		func(msg string) { errs = append(errs, msg) },
+		func(title, msg string) { notes = append(notes, title) },
+		nil,
+	)
+	b.tick = b.cfg
+	b.tick.CaptureFailThreshold = 3
+	b.tick.StopOnCaptureFailure = true
+	startTestStep(b, StateEntry) // autoStop then ends the step instead of stopping a loop
+
+	failing := true
+	b.searcher.SetCaptureRetry(0, 0)
+	b.searcher.SetCapturer(func(int) (image.Image, error) {
+		if failing {
+			return nil, errors.New("permission denied")
+		}
+		return image.NewRGBA(image.Rect(0, 0, 10, 10)), nil
+	})
+
+	for i := 1; i <= 2; i++ {
+		if _, err := b.captureFrame(); err == nil {
+			t.Fatalf("capture %d succeeded", i)
+		}
+	}
+	if len(errs) != 0 || len(notes) != 0 || b.stopping {
+		t.Fatalf("escalated before the threshold: errors %q, notifications %q, stopping %v", errs, notes, b.stopping)
+	}
+
+	// The third failure in a row reaches the threshold
+	b.captureFrame()
+	if len(errs) != 1 || !strings.Contains(errs[0], "3 times") {
+		t.Errorf("errors = %q, want one about 3 failures", errs)
+	}
+	if len(notes) != 1 {
+		t.Errorf("notifications = %q, want one", notes)
+	}
+	if !b.stopping {
+		t.Error("StopOnCaptureFailure did not stop the bot")
+	}
+
+	// Further failures don't repeat the alert
+	b.captureFrame()
+	if len(errs) != 1 || len(notes) != 1 {
+		t.Errorf("alerted again past the threshold: errors %q, notifications %q", errs, notes)
+	}
+
+	// A good capture resets the count
+	failing = false
+	if _, err := b.captureFrame(); err != nil {
+		t.Fatalf("capture after recovery: %v", err)
+	}
+	if b.captureFailures != 0 {
+		t.Errorf("captureFailures = %d after a good capture, want 0", b.captureFailures)
+	}
+	if len(logs) == 0 || !strings.Contains(logs[len(logs)-1], "recovered after 4 failures") {
+		t.Errorf("logs = %q, want a recovery message", logs)
+	}
+}
+
+func TestEntryCooldownWhenAllBlacklisted(t *testing.T) {
+	button := newTemplate(20, 20, 0)
+	frame := newScreen(200, 200)
+	paste(frame, button, 60, 80)
+	entity := DetectedEntity{TemplateName: "20.png", Priority: 20, Position: image.Point{X: 60, Y: 80}, TemplateSize: image.Point{X: 20, Y: 20}}
+
+	b, _, actions := newFrameBot(t, frame)
+	b.targetsGames = []Target{{Name: "20.png", Image: button}}
+	b.State = StateEntry
+	now := time.Now()
+	b.entryTracker.SetClock(func() time.Time { return now })
+	for !b.entryTracker.RecordClick(entity) {
+	}
+
+	if got := runTick(b); got != constants.BlacklistCooldownInterval {
+		t.Errorf("interval with every entity blacklisted = %v, want the %v cooldown", got, constants.BlacklistCooldownInterval)
+	}
+	if normal := b.tick.EntryScanInterval.D(); normal >= constants.BlacklistCooldownInterval {
+		t.Errorf("cooldown %v is not longer than the normal entry scan %v", constants.BlacklistCooldownInterval, normal)
+	}
+	if len(actions.clicks) != 0 || b.State != StateEntry {
+		t.Errorf("clicked %v (state %s), want no click on a blacklisted entity", actions.clicks, b.State)
+	}
+
+	// The cooldown also sweeps old blacklist entries, so the entity is retried next
+	now = now.Add(constants.BlacklistExpiry + time.Second)
+	runTick(b)
+	if _, blacklisted := b.entryTracker.Stats(); blacklisted != 0 {
+		t.Errorf("%d entries still blacklisted after the expiry", blacklisted)
+	}
+	if got := runTick(b); got == constants.BlacklistCooldownInterval || len(actions.clicks) != 1 {
+		t.Errorf("after the expiry: interval %v, clicks %v; want the entity clicked", got, actions.clicks)
+	}
+}
+
+func TestOneCapturePerTick(t *testing.T) {
+	button := newTemplate(20, 20, 0)
+	frame := newScreen(200, 200)
+	paste(frame, button, 60, 80)
+	b, src, actions := newFrameBot(t, frame)
+	absent := []Target{{Name: "1.png", Image: newTemplate(20, 20, 80)}}
+	b.targetsSkill, b.targetsExit, b.targetsLobby, b.targetsFinding = absent, absent, absent, absent
+	b.targetsGames = []Target{{Name: "20.png", Image: button}}
+
+	// Auto-detect checks every template group against one frame
+	b.State = StateAutoDetect
+	runTick(b)
+	if src.captures != 1 || b.State != StateEntry {
+		t.Fatalf("auto-detect tick: %d captures, state %s; want 1 capture and Entry", src.captures, b.State)
+	}
+
+	// The entry tick checks exit, finding and the games on one frame, then clicks
+	src.captures = 0
+	runTick(b)
+	if src.captures != 1 || len(actions.clicks) != 1 {
+		t.Fatalf("entry tick: %d captures, clicks %v; want 1 capture and the game clicked", src.captures, actions.clicks)
+	}
+	// The click invalidated the frame, so verification sees a new one
+	if b.searcher.Frame(); src.captures != 2 {
+		t.Errorf("%d captures after the click, want a fresh frame", src.captures)
+	}
+	if b.searcher.Frame(); src.captures != 2 {
+		t.Errorf("%d captures, want the fresh frame reused until invalidated", src.captures)
+	}
+}
+
+func TestAutoDetectMinMatches(t *testing.T) {
+	skill, bar := newTemplate(20, 20, 0), newTemplate(20, 20, 100)
+	noise := newScreen(200, 200) // A spurious match of one in-game template
+	paste(noise, skill, 60, 80)
+	inGame := newScreen(200, 200)
+	paste(inGame, skill, 60, 80)
+	paste(inGame, bar, 120, 80)
+
+	tests := []struct {
+		name     string
+		required int
+		frame    image.Image
+		want     BotState
+		rejected bool
+	}{
+		{"one match by default", 1, noise, StateInGame, false},
+		{"noise below the threshold", 2, noise, StateAutoDetect, true},
+		{"threshold met", 2, inGame, StateInGame, false},
+		{"threshold capped at the group size", 5, inGame, StateInGame, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _, _ := newFrameBot(t, tt.frame)
+			var logs []string
+			b.logFunc = func(msg string) { logs = append(logs, msg) }
+			b.targetsSkill = []Target{{Name: "skill.png", Image: skill}, {Name: "bar.png", Image: bar}}
+			b.cfg.AutoDetectMinMatches = tt.required
+			b.State = StateAutoDetect
+
+			runTick(b)
+			if b.State != tt.want {
+				t.Errorf("state = %s, want %s", b.State, tt.want)
+			}
+			rejected := false
+			for _, msg := range logs {
+				if strings.Contains(msg, "Rejected InGame(skill), only 1/2 templates matched") {
+					rejected = true
+				}
+			}
+			if rejected != tt.rejected {
+				t.Errorf("logs %q, want rejection logged %v", logs, tt.rejected)
+			}
+		})
+	}
+}
+
+func TestSearchROIFastPath(t *testing.T) {
+	button := newTemplate(20, 20, 0)
+	target := Target{Name: "open.png", Image: button}
+	b := newTestBot()
+	var debug []string
+	b.debugFunc = func(format string, args ...interface{}) { debug = append(debug, fmt.Sprintf(format, args...)) }
+	hits := func() int {
+		n := 0
+		for _, msg := range debug {
+			if strings.HasPrefix(msg, "[Search] ROI hit") {
+				n++
+			}
+		}
+		return n
+	}
+
+	// First seen: full-screen scan, position remembered
+	frame := newScreen(300, 200)
+	paste(frame, button, 200, 120)
+	if x, y, found := b.findNearLast(frame, target, constants.DefaultTolerance); !found || x != 200 || y != 120 || hits() != 0 {
+		t.Fatalf("first scan = (%d, %d) %v with %d ROI hits, want (200, 120) by full scan", x, y, found, hits())
+	}
+
+	// Nudged a few pixels, with a copy earlier in scan order: the ROI around the
+	// last position is checked first, so the full scan never reaches the copy
+	frame = newScreen(300, 200)
+	paste(frame, button, 10, 10)
+	paste(frame, button, 205, 124)
+	if x, y, found := b.findNearLast(frame, target, constants.DefaultTolerance); !found || x != 205 || y != 124 || hits() != 1 {
+		t.Fatalf("second scan = (%d, %d) %v with %d ROI hits, want (205, 124) from the ROI", x, y, found, hits())
+	}
+
+	// Moved far away: the ROI misses and the full scan finds it
+	frame = newScreen(300, 200)
+	paste(frame, button, 20, 150)
+	if x, y, found := b.findNearLast(frame, target, constants.DefaultTolerance); !found || x != 20 || y != 150 || hits() != 1 {
+		t.Errorf("third scan = (%d, %d) %v with %d ROI hits, want (20, 150) by full scan", x, y, found, hits())
+	}
+	if b.searchPositions[target.Name] != (image.Point{X: 20, Y: 150}) {
+		t.Errorf("remembered %v, want the new position", b.searchPositions[target.Name])
+	}
+}
+
+// failSearchVerify runs one search cycle whose highlight is never found, up to the
+// fallback after constants.SearchMaxRetries attempts
+func failSearchVerify(b *GlobalBot) {
+	b.State = StateSearchVerify
+	for i := 0; i < constants.SearchMaxRetries && b.State == StateSearchVerify && !b.stopping; i++ {
+		runTick(b)
+	}
+}
+
+func TestVerifyFailures(t *testing.T) {
+	for _, stop := range []bool{false, true} {
+		name := "fallback"
+		if stop {
+			name = "stop"
+		}
+		t.Run(name, func(t *testing.T) {
+			finding := newTemplate(20, 20, 0)
+			b, src, _ := newFrameBot(t, newScreen(200, 200)) // The highlight never shows
+			var errs []string
+			b.SetAlertFuncs(func(msg string) { errs = append(errs, msg) }, nil, nil)
+			b.targetsFinding = []Target{{Name: "finding.png", Image: finding}}
+			b.cfg.VerifyFailThreshold = 3
+			b.cfg.StopOnVerifyFailure = stop
+			startTestStep(b, StateSearchVerify) // autoStop then ends the step instead of stopping a loop
+
+			// Below the threshold each failed cycle falls back to auto-detect quietly
+			for cycle := 1; cycle < 3; cycle++ {
+				failSearchVerify(b)
+				if b.State != StateAutoDetect || len(errs) != 0 {
+					t.Fatalf("cycle %d: state %s, errors %q; want a quiet fallback to AutoDetect", cycle, b.State, errs)
+				}
+			}
+
+			// A verified cycle resets the count
+			withFinding := newScreen(200, 200)
+			paste(withFinding, finding, 40, 40)
+			src.frame = withFinding
+			b.State = StateSearchVerify
+			runTick(b)
+			if b.State != StateEntry || b.verifyFailures != 0 {
+				t.Fatalf("after a verified cycle: state %s, failures %d; want Entry, 0", b.State, b.verifyFailures)
+			}
+
+			src.frame = newScreen(200, 200)
+			for cycle := 1; cycle <= 3; cycle++ {
+				failSearchVerify(b)
+			}
+			if len(errs) != 1 {
+				t.Errorf("errors = %q, want one after 3 failed cycles in a row", errs)
+			}
+			if b.stopping != stop {
+				t.Errorf("stopping = %v, want %v", b.stopping, stop)
+			}
+			if !stop && b.State != StateAutoDetect {
+				t.Errorf("state %s, want the fallback to AutoDetect", b.State)
+			}
+		})
+	}
+}
+
+// startTestLoop runs the bot loop in state as Start does, without loading templates
+func startTestLoop(b *GlobalBot, state BotState) {
+	b.State = state
+	b.stopChan = make(chan struct{})
+	b.wg.Add(1)
+	go b.loop()
+}
+
+// clickSignal is an input.Actions that reports each click on clicked
+type clickSignal struct {
+	recordedActions
+	clicked chan struct{}
+}
+
+func (a *clickSignal) Click(button string) {
+	a.recordedActions.Click(button)
+	select {
+	case a.clicked <- struct{}{}:
+	default:
+	}
+}
+
+func TestStopDuringLongWait(t *testing.T) {
+	t.Chdir(t.TempDir()) // Stop appends the run summary under logs/
+
+	stopWithin := func(t *testing.T, b *GlobalBot, d time.Duration) {
+		t.Helper()
+		done := make(chan struct{})
+		go func() {
+			b.Stop()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(d):
+			t.Fatal("Stop waited for the handler's wait")
+		}
+		if b.Running() {
+			t.Error("bot still running after Stop")
+		}
+	}
+
+	t.Run("interval", func(t *testing.T) {
+		// InGame returns a long scan interval as the loop's next wait
+		b, _, _ := newFrameBot(t, newScreen(200, 200))
+		b.cfg.WarmUp = 0
+		b.cfg.InGameScanInterval = config.Duration(time.Hour)
+		captured := make(chan struct{}, 1)
+		b.searcher.SetCapturer(func(int) (image.Image, error) {
+			select {
+			case captured <- struct{}{}:
+			default:
+			}
+			return newScreen(200, 200), nil
+		})
+		startTestLoop(b, StateInGame)
+		<-captured // The first tick ran and asked for an hour
+		stopWithin(t, b, 2*time.Second)
+	})
+
+	t.Run("click sequence", func(t *testing.T) {
+		// A sidecar step waiting an hour inside the entry click
+		button := newTemplate(20, 20, 0)
+		frame := newScreen(200, 200)
+		paste(frame, button, 60, 80)
+		b, _, _ := newFrameBot(t, frame)
+		actions := &clickSignal{clicked: make(chan struct{}, 1)}
+		b.SetActions(actions)
+		b.cfg.WarmUp = 0
+		b.targetsGames = []Target{{Name: "20.png", Image: button, Sequence: []ClickStep{{Wait: config.Duration(time.Hour)}}}}
+		startTestLoop(b, StateEntry)
+		<-actions.clicked // The button is clicked, the sequence now waits
+		stopWithin(t, b, 2*time.Second)
+	})
+}
+
+func TestWarmUpDelaysFirstScan(t *testing.T) {
+	t.Chdir(t.TempDir()) // Stop appends the run summary under logs/
+
+	b, _, _ := newFrameBot(t, newScreen(200, 200))
+	const warmUp = 300 * time.Millisecond
+	b.cfg.WarmUp = config.Duration(warmUp)
+	b.cfg.InGameScanInterval = config.Duration(time.Hour)
+	statuses := make(chan string, 10)
+	b.statusFunc = func(msg string) {
+		select {
+		case statuses <- msg:
+		default:
+		}
+	}
+	scanned := make(chan time.Time, 1)
+	b.searcher.SetCapturer(func(int) (image.Image, error) {
+		select {
+		case scanned <- time.Now():
+		default:
+		}
+		return newScreen(200, 200), nil
+	})
+
+	start := time.Now()
+	startTestLoop(b, StateInGame)
+	defer b.Stop()
+
+	if msg := <-statuses; !strings.Contains(msg, "Starting in 1s") {
+		t.Errorf("first status %q, want the warm-up countdown", msg)
+	}
+	select {
+	case at := <-scanned:
+		if d := at.Sub(start); d < warmUp {
+			t.Errorf("first scan after %v, want at least the %v warm-up", d, warmUp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no scan after the warm-up")
+	}
+}
+
+// toleranceMatcher records the tolerances it is asked to match with (and never matches)
+type toleranceMatcher struct {
+	seen map[float64]bool
+}
+
+func (m *toleranceMatcher) Match(_, _ image.Image, _ image.Point, tolerance float64) (bool, float64) {
+	m.seen[tolerance] = true
+	return false, 0
+}
+
+func TestFeatureTolerances(t *testing.T) {
+	t.Chdir(t.TempDir()) // An empty entry scan saves a debug screenshot
+
+	tpl := []Target{{Name: "t.png", Image: newTemplate(20, 20, 0)}}
+	tests := []struct {
+		state   BotState
+		feature string
+		targets func(b *GlobalBot) *[]Target
+	}{
+		{StateEntry, "entry", func(b *GlobalBot) *[]Target { return &b.targetsGames }},
+		{StateExitStep1, "exit", func(b *GlobalBot) *[]Target { return &b.targetsExit }},
+		{StateExitStep2, "exit", func(b *GlobalBot) *[]Target { return &b.targetsChannelReturn }},
+		{StateSearchOpen, "search_open", func(b *GlobalBot) *[]Target { return &b.targetsChannelOpen }},
+		{StateSearchSelect, "search_select", func(b *GlobalBot) *[]Target { return &b.targetsChannelSelect }},
+		{StateSearchVerify, "search_verify", func(b *GlobalBot) *[]Target { return &b.targetsFinding }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.state.String(), func(t *testing.T) {
+			b, _, _ := newFrameBot(t, newScreen(60, 60))
+			m := &toleranceMatcher{seen: make(map[float64]bool)}
+			b.searcher.SetMatcher(m)
+			*tt.targets(b) = tpl
+			b.SetTolerance(60)
+			b.SetFeatureTolerance(tt.feature, 11)
+			b.State = tt.state
+
+			runTick(b)
+			if !m.seen[11] || len(m.seen) != 1 {
+				t.Errorf("matched with tolerances %v, want only the %s tolerance 11", m.seen, tt.feature)
+			}
+
+			// A change mid-tick waits for the next tick's snapshot
+			b.State = tt.state
+			b.SetFeatureTolerance(tt.feature, 22)
+			clear(m.seen)
+			b.searcher.InvalidateFrame()
+			b.processState()
+			if !m.seen[11] || m.seen[22] {
+				t.Errorf("within the tick: tolerances %v, want the snapshot's 11", m.seen)
+			}
+			b.State = tt.state
+			clear(m.seen)
+			runTick(b)
+			if !m.seen[22] || m.seen[11] {
+				t.Errorf("next tick: tolerances %v, want the new 22", m.seen)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package global
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Pause holds the bot until resume is called: the loop idles and no input is sent
+// (an action already under way finishes). Pauses nest, e.g. one per open dialog; the
+// bot continues when the last one is resumed. A pause taken while stopped also holds
+// the next run. resume may be called more than once.
+func (b *GlobalBot) Pause(reason string) (resume func()) {
+	release := b.gate.Hold()
+	b.mu.Lock()
+	b.pauseReason = reason
+	running := b.State != StateStopped
+	b.mu.Unlock()
+	if running {
+		b.logFunc(fmt.Sprintf("Paused: %s", reason))
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			release()
+			if !b.gate.Held() && b.Running() {
+				b.logFunc("Resumed")
+			}
+		})
+	}
+}
+
+// Paused reports whether any pause is active
+func (b *GlobalBot) Paused() bool {
+	return b.gate.Held()
+}
+
+// pausedStatus shows why the loop is idling
+func (b *GlobalBot) pausedStatus() {
+	b.mu.Lock()
+	reason := b.pauseReason
+	b.mu.Unlock()
+	b.setStatus(fmt.Sprintf("Status: Paused (%s)", reason))
+}
@@ -0,0 +1,96 @@
+package global
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ROIFunc receives the frame of every entry scan with the tracked ROI (empty when the
+// fast path is off) and whether the ROI scan found the entity (hit) or fell back to
+// the full screen. It runs on the bot goroutine.
+type ROIFunc func(frame image.Image, roi image.Rectangle, hit bool)
+
+// SetROIFunc sets the callback that observes the ROI fast path (nil = off)
+func (b *GlobalBot) SetROIFunc(f ROIFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roiFunc = f
+}
+
+// reportROI passes an entry scan's ROI outcome to the ROI observer, if any
+func (b *GlobalBot) reportROI(frame image.Image, roi image.Rectangle, hit bool) {
+	b.mu.Lock()
+	f := b.roiFunc
+	b.mu.Unlock()
+	if f != nil {
+		f(frame, roi, hit)
+	}
+}
+
+// DrawROI returns a copy of frame with roi outlined: green when the ROI scan hit,
+// orange when it fell back to the full screen. An empty roi leaves the frame unmarked.
+func DrawROI(frame image.Image, roi image.Rectangle, hit bool) *image.RGBA {
+	out := image.NewRGBA(frame.Bounds())
+	draw.Draw(out, out.Bounds(), frame, frame.Bounds().Min, draw.Src)
+
+	r := roi.Intersect(out.Bounds())
+	if r.Empty() {
+		return out
+	}
+	c := color.RGBA{R: 255, G: 140, A: 255} // Fell back
+	if hit {
+		c = color.RGBA{G: 220, A: 255}
+	}
+	for t := 0; t < 3; t++ { // 3px border
+		for x := r.Min.X; x < r.Max.X; x++ {
+			out.Set(x, r.Min.Y+t, c)
+			out.Set(x, r.Max.Y-1-t, c)
+		}
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			out.Set(r.Min.X+t, y, c)
+			out.Set(r.Max.X-1-t, y, c)
+		}
+	}
+	return out
+}
+
+// showROIOverlay opens a debug window that redraws each entry scan with the tracked
+// ROI outlined. Frames are only copied while the window is open.
+func showROIOverlay(bot *GlobalBot) {
+	w := fyne.CurrentApp().NewWindow("ROI Overlay")
+	w.Resize(fyne.NewSize(800, 500))
+
+	raster := canvas.NewImageFromImage(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	raster.FillMode = canvas.ImageFillContain
+	raster.ScaleMode = canvas.ImageScalePixels
+	info := widget.NewLabel("等待入口扫描... (Waiting for an entry scan)")
+
+	bot.SetROIFunc(func(frame image.Image, roi image.Rectangle, hit bool) {
+		marked := DrawROI(frame, roi, hit)
+		var msg string
+		switch {
+		case roi.Empty():
+			msg = "No ROI: full-screen scan"
+		case hit:
+			msg = fmt.Sprintf("ROI hit %v", roi)
+		default:
+			msg = fmt.Sprintf("ROI %v empty: fell back to full screen", roi)
+		}
+		fyne.Do(func() {
+			raster.Image = marked
+			raster.Refresh()
+			info.SetText(msg)
+		})
+	})
+	w.SetOnClosed(func() { bot.SetROIFunc(nil) })
+
+	w.SetContent(container.NewBorder(info, nil, nil, nil, raster))
+	w.Show()
+}
@@ -0,0 +1,52 @@
+package global
+
+import (
+	"sync"
+	"time"
+)
+
+// ScanRate tracks scan completions over a rolling window
+type ScanRate struct {
+	mu     sync.Mutex
+	window time.Duration
+	scans  []time.Time
+}
+
+// NewScanRate creates a tracker averaging over the given window
+func NewScanRate(window time.Duration) *ScanRate {
+	return &ScanRate{window: window}
+}
+
+// Record registers a completed scan
+func (r *ScanRate) Record(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scans = append(r.scans, now)
+	r.prune(now)
+}
+
+// PerSecond returns the scans per second within the window
+func (r *ScanRate) PerSecond(now time.Time) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune(now)
+	if len(r.scans) < 2 {
+		return 0
+	}
+	// Measure over the span actually covered, so the rate is right before the window fills
+	span := now.Sub(r.scans[0])
+	if span <= 0 {
+		return 0
+	}
+	return float64(len(r.scans)-1) / span.Seconds()
+}
+
+// prune drops scans older than the window
+func (r *ScanRate) prune(now time.Time) {
+	cutoff := now.Add(-r.window)
+	i := 0
+	for i < len(r.scans) && r.scans[i].Before(cutoff) {
+		i++
+	}
+	r.scans = r.scans[i:]
+}
@@ -0,0 +1,171 @@
+package global
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/ConserveLee/gui-idle/assets"
+	"github.com/ConserveLee/gui-idle/internal/config"
+)
+
+// DragOffset is where a drag target is dragged to, relative to its center
+type DragOffset struct {
+	OffsetX int `json:"offset_x"`
+	OffsetY int `json:"offset_y"`
+}
+
+// ClickStep is one extra click performed after the target itself was clicked.
+// The offset is relative to the target's center, e.g. a confirmation button
+// that always appears 120px to the right.
+type ClickStep struct {
+	Wait    config.Duration `json:"wait"`     // Delay before this click
+	OffsetX int             `json:"offset_x"` // Relative to target center
+	OffsetY int             `json:"offset_y"` // Relative to target center
+}
+
+// Sidecar holds optional per-target settings, stored as JSON next to the PNG
+// ("20.png" -> "20.json"):
+//
+//	{
+//	  "steps": [{"wait": "500ms", "offset_x": 120, "offset_y": 40}],
+//	  "chroma_key": "#00ff00",
+//	  "hold": "800ms",
+//	  "drag": {"offset_x": 0, "offset_y": -300},
+//	  "hover": "400ms",
+//	  "hover_verify": true,
+//	  "match_fraction": 0.7,
+//	  "source_resolution": "1920x1080"
+//	}
+type Sidecar struct {
+	Steps     []ClickStep     `json:"steps,omitempty"`      // Extra clicks after the target
+	ChromaKey string          `json:"chroma_key,omitempty"` // Template color treated as transparent ("#RRGGBB")
+	Hold      config.Duration `json:"hold,omitempty"`       // Press and hold the target this long instead of clicking
+	Drag      *DragOffset     `json:"drag,omitempty"`       // Drag from the target center instead of clicking
+
+	Hover       config.Duration `json:"hover,omitempty"`        // Rest the mouse on the target this long before clicking
+	HoverVerify bool            `json:"hover_verify,omitempty"` // After hovering, click only if the target is still there
+
+	MatchFraction float64 `json:"match_fraction,omitempty"` // Accept a partly covered entry button when this fraction of its pixels match (0 = off)
+
+	SourceResolution string `json:"source_resolution,omitempty"` // Display size the template was cropped on ("WxH"), set by the tools tab
+}
+
+// SidecarPath returns the JSON sidecar path for a template PNG
+func SidecarPath(pngPath string) string {
+	return strings.TrimSuffix(pngPath, ".png") + ".json"
+}
+
+// LoadSidecar reads the sidecar for a template.
+// Returns an empty Sidecar (no error) if the template has none.
+func LoadSidecar(pngPath string) (Sidecar, error) {
+	data, err := os.ReadFile(SidecarPath(pngPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Sidecar{}, nil
+		}
+		return Sidecar{}, err
+	}
+	return ParseSidecar(data)
+}
+
+// loadFileSidecar reads the sidecar of a template on disk or embedded (see LoadSidecar)
+func loadFileSidecar(file assets.File) (Sidecar, error) {
+	if !file.Embedded() {
+		return LoadSidecar(file.Path)
+	}
+	data, err := file.ReadSibling(".json")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Sidecar{}, nil
+		}
+		return Sidecar{}, err
+	}
+	return ParseSidecar(data)
+}
+
+// SaveSidecar writes the sidecar for a template
+func SaveSidecar(pngPath string, sc Sidecar) error {
+	data, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(SidecarPath(pngPath), data, 0644)
+}
+
+// ParseSidecar decodes and validates a sidecar
+func ParseSidecar(data []byte) (Sidecar, error) {
+	var sc Sidecar
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return Sidecar{}, fmt.Errorf("invalid sidecar: %w", err)
+	}
+	for i, step := range sc.Steps {
+		if step.Wait < 0 {
+			return Sidecar{}, fmt.Errorf("invalid sidecar: step %d has negative wait", i+1)
+		}
+	}
+	if sc.Hold < 0 {
+		return Sidecar{}, fmt.Errorf("invalid sidecar: negative hold")
+	}
+	if sc.Drag != nil && sc.Hold > 0 {
+		return Sidecar{}, fmt.Errorf("invalid sidecar: hold and drag are exclusive")
+	}
+	if sc.Hover < 0 {
+		return Sidecar{}, fmt.Errorf("invalid sidecar: negative hover")
+	}
+	if sc.Drag != nil && sc.Hover > 0 {
+		return Sidecar{}, fmt.Errorf("invalid sidecar: hover and drag are exclusive")
+	}
+	if sc.MatchFraction < 0 || sc.MatchFraction > 1 {
+		return Sidecar{}, fmt.Errorf("invalid sidecar: match_fraction %g is outside 0-1", sc.MatchFraction)
+	}
+	if sc.ChromaKey != "" {
+		if _, err := ParseHexColor(sc.ChromaKey); err != nil {
+			return Sidecar{}, fmt.Errorf("invalid sidecar: %w", err)
+		}
+	}
+	if sc.SourceResolution != "" {
+		if _, err := ParseResolution(sc.SourceResolution); err != nil {
+			return Sidecar{}, fmt.Errorf("invalid sidecar: %w", err)
+		}
+	}
+	return sc, nil
+}
+
+// FormatResolution formats a display size as "WxH"
+func FormatResolution(size image.Point) string {
+	return fmt.Sprintf("%dx%d", size.X, size.Y)
+}
+
+// ParseResolution parses "WxH"
+func ParseResolution(s string) (image.Point, error) {
+	var size image.Point
+	if _, err := fmt.Sscanf(s, "%dx%d", &size.X, &size.Y); err != nil || size.X <= 0 || size.Y <= 0 {
+		return image.Point{}, fmt.Errorf("invalid resolution %q (want WxH)", s)
+	}
+	return size, nil
+}
+
+// ParseHexColor parses "#RRGGBB" (the leading # is optional)
+func ParseHexColor(s string) (color.RGBA, error) {
+	var r, g, b uint8
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color %q (want #RRGGBB)", s)
+	}
+	if _, err := fmt.Sscanf(hex, "%02x%02x%02x", &r, &g, &b); err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color %q (want #RRGGBB)", s)
+	}
+	return color.RGBA{R: r, G: g, B: b, A: 255}, nil
+}
+
+// HexColor formats a color as "#RRGGBB"
+func HexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
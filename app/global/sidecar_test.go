@@ -0,0 +1,125 @@
+package global
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/input"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+)
+
+func TestParseSidecarSteps(t *testing.T) {
+	sc, err := ParseSidecar([]byte(`{"steps": [{"wait": "50ms", "offset_x": 120, "offset_y": 40}]}`))
+	if err != nil {
+		t.Fatalf("ParseSidecar = %v", err)
+	}
+	if len(sc.Steps) != 1 || sc.Steps[0].Wait.D() != 50*time.Millisecond || sc.Steps[0].OffsetX != 120 || sc.Steps[0].OffsetY != 40 {
+		t.Errorf("steps = %+v, want one 50ms step at (120, 40)", sc.Steps)
+	}
+
+	for _, bad := range []string{
+		`{"steps": [{"wait": "-1s"}]}`,
+		`{"steps": [{"wait": "soon"}]}`,
+		`{"steps": {}}`,
+	} {
+		if _, err := ParseSidecar([]byte(bad)); err == nil {
+			t.Errorf("ParseSidecar(%s) accepted an invalid sequence", bad)
+		}
+	}
+}
+
+func TestClickSequence(t *testing.T) {
+	sc, err := ParseSidecar([]byte(`{"steps": [{"wait": "50ms", "offset_x": 120, "offset_y": 40}]}`))
+	if err != nil {
+		t.Fatalf("ParseSidecar = %v", err)
+	}
+	b, _, _ := newFrameBot(t, newScreen(400, 300))
+	rec := &input.Recorder{}
+	b.SetActions(rec)
+	b.stopChan = make(chan struct{})
+
+	// Detected at (100, 80): click its center, wait, then click the confirmation offset
+	target := Target{Name: "20.png", Image: newTemplate(40, 20, 0), Sequence: sc.Steps}
+	b.clickTarget(target, 100, 80)
+
+	var moves []image.Point
+	var clicks []time.Time
+	for _, c := range rec.Calls {
+		switch c.Op {
+		case "move":
+			moves = append(moves, image.Point{X: c.X, Y: c.Y})
+		case "click":
+			clicks = append(clicks, c.At)
+		}
+	}
+	if want := []image.Point{{120, 90}, {240, 130}}; len(moves) != 2 || moves[0] != want[0] || moves[1] != want[1] || len(clicks) != 2 {
+		t.Fatalf("moved to %v with %d clicks, want %v with 2", moves, len(clicks), want)
+	}
+	if gap := clicks[1].Sub(clicks[0]); gap < 50*time.Millisecond {
+		t.Errorf("second click %v after the first, want the 50ms wait", gap)
+	}
+
+	// Stopping during the wait skips the rest of the sequence
+	rec.Calls = nil
+	close(b.stopChan)
+	b.clickTarget(target, 100, 80)
+	if n := len(rec.Calls); n != 2 {
+		t.Errorf("%d calls after a stop, want just the target's move and click", n)
+	}
+}
+
+func TestChromaKeySidecar(t *testing.T) {
+	// A 20x20 button cut with a 4px green margin, keyed out by its sidecar
+	button := newTemplate(20, 20, 0)
+	tpl := image.NewNRGBA(image.Rect(0, 0, 28, 28))
+	for y := 0; y < 28; y++ {
+		for x := 0; x < 28; x++ {
+			tpl.SetNRGBA(x, y, color.NRGBA{0, 255, 0, 255})
+		}
+	}
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 20; x++ {
+			tpl.SetNRGBA(x+4, y+4, button.NRGBAAt(x, y))
+		}
+	}
+	dir := filepath.Join(t.TempDir(), "find_game", "games")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := screen.SaveImage(filepath.Join(dir, "20.png"), tpl); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveSidecar(filepath.Join(dir, "20.png"), Sidecar{ChromaKey: "#00ff00"}); err != nil {
+		t.Fatal(err)
+	}
+
+	b := newTestBot()
+	b.AssetsDir = filepath.Dir(filepath.Dir(dir))
+	targets, err := b.loadTargets("find_game/games")
+	if err != nil || len(targets) != 1 {
+		t.Fatalf("loadTargets = %d targets, %v; want 1", len(targets), err)
+	}
+	keyed := targets[0].Image
+	if _, _, _, a := keyed.At(0, 0).RGBA(); a != 0 {
+		t.Error("keyed margin pixel is not transparent")
+	}
+	if _, _, _, a := keyed.At(14, 14).RGBA(); a == 0 {
+		t.Error("button pixel was keyed out")
+	}
+
+	// On screen the button sits on the gray background, not green
+	frame := newScreen(100, 100)
+	paste(frame, button, 40, 30)
+	s := screen.NewSearcher()
+	if got := s.FindAllTemplates(frame, tpl, constants.DefaultTolerance); len(got) != 0 {
+		t.Errorf("unkeyed template matched %v, want nothing", got)
+	}
+	if got := s.FindAllTemplates(frame, keyed, constants.DefaultTolerance); len(got) != 1 || got[0] != (image.Point{X: 36, Y: 26}) {
+		t.Errorf("keyed template matched %v, want (36, 26)", got)
+	}
+}
@@ -0,0 +1,99 @@
+package global
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/engine/input"
+)
+
+// Step runs a single tick of the state machine (one processState) on the current
+// screen while the bot is stopped, without starting the loop: for diagnosing state
+// transitions one at a time. Clicks are performed as in a run (none in dry-run).
+// Consecutive steps continue from the state the previous step left; the first step
+// after a run (or ever) starts at AutoDetect like Start. It logs the state it ran,
+// the state it left and the matches found. Stop, or an auto-stop inside the tick,
+// ends the step early and the next one starts over.
+func (b *GlobalBot) Step() error {
+	b.mu.Lock()
+	if b.stepping {
+		b.mu.Unlock()
+		return fmt.Errorf("a step is already running")
+	}
+	if b.State != StateStopped {
+		b.mu.Unlock()
+		return fmt.Errorf("stop the bot first")
+	}
+	if err := b.loadAllAssets(); err != nil {
+		b.mu.Unlock()
+		return err
+	}
+	if b.stepState == StateStopped {
+		// A fresh sequence of steps, set up like a run
+		b.recordTransition(StateAutoDetect, "step")
+		b.stepState = StateAutoDetect
+		b.stateEntered = time.Now()
+		b.pending = nil
+		b.inputConfirmed = false
+		b.captureFailures = 0
+		b.verifyFailures = 0
+		b.searchPositions = make(map[string]image.Point)
+		if b.incremental != nil {
+			b.incremental.Reset()
+		}
+		b.stats = RunStats{Started: time.Now()}
+		b.rng, _ = input.NewRand(b.cfg.Seed)
+	}
+	b.State = b.stepState
+	b.stepping = true
+	b.stopChan = make(chan struct{}) // The last Stop closed it; waits inside the tick need an open one
+	b.mu.Unlock()
+	b.snapshot()
+
+	from := b.State
+	if wait, ok := b.applyPending(); !ok {
+		b.logFunc(fmt.Sprintf("[Step] %s: a held-back transition waits %v more for its dwell, nothing run", from, wait.Round(time.Millisecond)))
+	} else {
+		b.searcher.InvalidateFrame() // New tick, new frame
+		start := time.Now()
+		next := b.processState()
+		b.stats.Scans++
+		b.stats.ScanTime += time.Since(start)
+		b.recordScan()
+		b.logFunc(fmt.Sprintf("[Step] %s -> %s: %d matches in %v, the loop would scan again in %v",
+			from, b.State, b.lastMatches, time.Since(start).Round(time.Millisecond), next))
+	}
+
+	b.endStep()
+	return nil
+}
+
+// stopStep makes the running step end stopped and releases its waits, e.g. the
+// first-click confirmation. Requires b.mu.
+func (b *GlobalBot) stopStep() {
+	if !b.stopping {
+		b.stopping = true
+		close(b.stopChan)
+	}
+}
+
+// endStep closes a step. The next step continues from the state this one left,
+// unless it was stopped: then it starts over at AutoDetect.
+func (b *GlobalBot) endStep() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stepping = false
+	if b.stopping {
+		b.stopping = false
+		b.stepState = StateStopped
+		b.recordTransition(StateStopped, "stopped")
+		b.State = StateStopped
+		b.logFunc("[Step] Stopped.")
+		b.statusFunc("Status: Stopped")
+		return
+	}
+	b.stepState = b.State
+	b.State = StateStopped // Not a run: no transition recorded
+	b.statusFunc(fmt.Sprintf("Status: Stepped (%s)", b.stepState))
+}
@@ -0,0 +1,64 @@
+package global
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestBot() *GlobalBot {
+	return NewGlobalBot(func(string) {}, func(string) {}, func(string, ...interface{}) {})
+}
+
+// startTestStep puts b in the middle of a step, as Step does before running the tick
+func startTestStep(b *GlobalBot, state BotState) {
+	b.State, b.stepState, b.stepping = state, state, true
+	b.stopChan = make(chan struct{})
+}
+
+func TestStepContinues(t *testing.T) {
+	b := newTestBot()
+	startTestStep(b, StateEntry)
+	b.endStep()
+	if b.State != StateStopped || b.stepState != StateEntry || b.stepping {
+		t.Errorf("after a step: State %s, stepState %s, stepping %v; want Stopped, Entry, false", b.State, b.stepState, b.stepping)
+	}
+}
+
+func TestStopDuringStep(t *testing.T) {
+	tests := []struct {
+		name string
+		stop func(b *GlobalBot)
+	}{
+		{"Stop", (*GlobalBot).Stop},
+		{"autoStop", (*GlobalBot).autoStop},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBot()
+			startTestStep(b, StateEntry)
+
+			done := make(chan struct{})
+			go func() {
+				tt.stop(b)
+				tt.stop(b) // A second stop is ignored
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("stop blocked during a step")
+			}
+			select {
+			case <-b.stopChan:
+			default:
+				t.Fatal("stop did not release the step's waits")
+			}
+
+			b.endStep()
+			if b.State != StateStopped || b.stepState != StateStopped || b.stopping || b.stepping {
+				t.Errorf("after a stopped step: State %s, stepState %s, stopping %v, stepping %v; want a fresh start",
+					b.State, b.stepState, b.stopping, b.stepping)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package global
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SummaryLogPath is where each run's summary is appended, for comparison across runs
+var SummaryLogPath = filepath.Join("logs", "summary.log")
+
+// ClickLogPath is the CSV every click is appended to (target box, center, offset,
+// global point, display and scale), to spot systematic click offsets after a run
+var ClickLogPath = filepath.Join("logs", "clicks.csv")
+
+// RunStats counts what happened during one run (Start to Stop)
+type RunStats struct {
+	Started     time.Time
+	Cycles      int           // Full game cycles (see EventCycleCompleted)
+	Clicks      int           // Entry clicks
+	Blacklisted int           // Entities blacklisted
+	Timeouts    int           // Lobby waits that timed out
+	Scans       int           // Loop ticks
+	ScanTime    time.Duration // Total time spent in state handlers
+}
+
+// count updates the counters for an emitted event
+func (s *RunStats) count(e BotEvent) {
+	switch e.Type {
+	case EventCycleCompleted:
+		s.Cycles++
+	case EventEntityClicked:
+		s.Clicks++
+	case EventEntityBlacklisted:
+		s.Blacklisted++
+	}
+}
+
+// AvgScanTime returns the mean handler time per tick
+func (s RunStats) AvgScanTime() time.Duration {
+	if s.Scans == 0 {
+		return 0
+	}
+	return s.ScanTime / time.Duration(s.Scans)
+}
+
+// Summary formats the stats as one line per figure, with runtime measured up to now
+func (s RunStats) Summary(now time.Time) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Run summary (%s)\n", s.Started.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(&sb, "  Runtime:         %v\n", now.Sub(s.Started).Round(time.Second))
+	fmt.Fprintf(&sb, "  Cycles:          %d\n", s.Cycles)
+	fmt.Fprintf(&sb, "  Entries clicked: %d\n", s.Clicks)
+	fmt.Fprintf(&sb, "  Blacklisted:     %d\n", s.Blacklisted)
+	fmt.Fprintf(&sb, "  Lobby timeouts:  %d\n", s.Timeouts)
+	fmt.Fprintf(&sb, "  Avg scan time:   %v (%d scans)", s.AvgScanTime().Round(time.Microsecond), s.Scans)
+	return sb.String()
+}
+
+// RunSummary returns the summary of the current (or last) run
+func (b *GlobalBot) RunSummary() string {
+	return b.stats.Summary(time.Now())
+}
+
+// appendSummary appends a run summary to the summary log, creating it if needed
+func appendSummary(path, summary string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, "%s\n\n", summary); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,50 @@
+package global
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// TargetSpec is what a template filename says about its target. The conventions:
+//
+//   - "_20.png": disabled (DisabledPrefix), skipped on load
+//   - "20.png", "20-1.png", "20_full.png": priority 20 (entries; anti-templates pair by it)
+//   - "skip_key=space.png", "key=esc.png": press the key instead of clicking
+//
+// Everything else about a target (click sequence, hold, drag, ...) is in its sidecar.
+type TargetSpec struct {
+	Name        string // Base filename ("_20-1_key=space.png")
+	Stem        string // Name without the disabled prefix and extension ("20-1_key=space")
+	Enabled     bool   // False for a DisabledPrefix name
+	Priority    int    // Leading number of Stem (0 if none)
+	HasPriority bool   // Stem starts with a number
+	Key         string // Keyboard action, lowercase ("" = click); may be unsupported (see IsSupportedKey)
+}
+
+// ParseTargetName parses a template filename or path (see TargetSpec)
+func ParseTargetName(filename string) TargetSpec {
+	spec := TargetSpec{Name: filepath.Base(filename), Enabled: true}
+	stem := strings.TrimSuffix(spec.Name, filepath.Ext(spec.Name))
+	if strings.HasPrefix(stem, DisabledPrefix) {
+		stem = strings.TrimPrefix(stem, DisabledPrefix)
+		spec.Enabled = false
+	}
+	spec.Stem = stem
+
+	digits := 0
+	for digits < len(stem) && stem[digits] >= '0' && stem[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 {
+		if n, err := strconv.Atoi(stem[:digits]); err == nil {
+			spec.Priority, spec.HasPriority = n, true
+		}
+	}
+
+	// "key=" starts the stem or follows an underscore
+	if idx := strings.LastIndex(stem, "key="); idx == 0 || (idx > 0 && stem[idx-1] == '_') {
+		spec.Key = strings.ToLower(stem[idx+len("key="):])
+	}
+	return spec
+}
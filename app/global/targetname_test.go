@@ -0,0 +1,36 @@
+package global
+
+import "testing"
+
+func TestParseTargetName(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     TargetSpec
+	}{
+		{"20.png", TargetSpec{Name: "20.png", Stem: "20", Enabled: true, Priority: 20, HasPriority: true}},
+		{"20-1.png", TargetSpec{Name: "20-1.png", Stem: "20-1", Enabled: true, Priority: 20, HasPriority: true}},
+		{"20_full.png", TargetSpec{Name: "20_full.png", Stem: "20_full", Enabled: true, Priority: 20, HasPriority: true}},
+		{"_20.png", TargetSpec{Name: "_20.png", Stem: "20", Priority: 20, HasPriority: true}},
+		{"key=esc.png", TargetSpec{Name: "key=esc.png", Stem: "key=esc", Enabled: true, Key: "esc"}},
+		{"skip_key=space.png", TargetSpec{Name: "skip_key=space.png", Stem: "skip_key=space", Enabled: true, Key: "space"}},
+		{"_20-1_key=Space.png", TargetSpec{Name: "_20-1_key=Space.png", Stem: "20-1_key=Space", Priority: 20, HasPriority: true, Key: "space"}},
+		{"assets/games/30.png", TargetSpec{Name: "30.png", Stem: "30", Enabled: true, Priority: 30, HasPriority: true}},
+		{"exit.png", TargetSpec{Name: "exit.png", Stem: "exit", Enabled: true}},
+		{"007.png", TargetSpec{Name: "007.png", Stem: "007", Enabled: true, Priority: 7, HasPriority: true}},
+
+		// Malformed names
+		{"", TargetSpec{Name: ".", Stem: "", Enabled: true}},
+		{".png", TargetSpec{Name: ".png", Stem: "", Enabled: true}},
+		{"_.png", TargetSpec{Name: "_.png", Stem: ""}},
+		{"20", TargetSpec{Name: "20", Stem: "20", Enabled: true, Priority: 20, HasPriority: true}},
+		{"hotkey=f5.png", TargetSpec{Name: "hotkey=f5.png", Stem: "hotkey=f5", Enabled: true}},
+		{"key=.png", TargetSpec{Name: "key=.png", Stem: "key=", Enabled: true}},
+		{"99999999999999999999.png", TargetSpec{Name: "99999999999999999999.png", Stem: "99999999999999999999", Enabled: true}},
+		{"-5.png", TargetSpec{Name: "-5.png", Stem: "-5", Enabled: true}},
+	}
+	for _, tt := range tests {
+		if got := ParseTargetName(tt.filename); got != tt.want {
+			t.Errorf("ParseTargetName(%q) = %+v, want %+v", tt.filename, got, tt.want)
+		}
+	}
+}
@@ -2,18 +2,52 @@ package global
 
 import (
 	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"github.com/ConserveLee/gui-idle/app/modal"
+	"github.com/ConserveLee/gui-idle/internal/config"
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
 	"github.com/ConserveLee/gui-idle/internal/logger"
-
-	"github.com/kbinani/screenshot"
+	"github.com/ConserveLee/gui-idle/internal/window"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/widget"
 )
 
-// NewGlobalExpeditionPanel creates the UI panel for Global Expedition AFK
-func NewGlobalExpeditionPanel() fyne.CanvasObject {
+// PanelControl lets main shut the panel down cleanly when the window closes
+type PanelControl struct {
+	Running  func() bool // Whether the bot is still running
+	Shutdown func()      // Stops the bot, saves the config and closes the log file (idempotent)
+
+	// SetScanRegion sets a feature's active scan region (empty rectangle clears it) and saves the config
+	SetScanRegion func(feature string, r image.Rectangle)
+
+	// SetColorCalibration sets the capture color correction (identity clears it) and saves the config
+	SetColorCalibration func(n screen.Normalization)
+
+	// Pause holds the bot until resume is called (see GlobalBot.Pause), e.g. while a dialog is open
+	Pause func(reason string) (resume func())
+
+	// AssetsRoot returns the active profile's template root (see config.Profile)
+	AssetsRoot func() string
+
+	// Tolerance returns the configured color tolerance
+	Tolerance func() float64
+}
+
+// NewGlobalExpeditionPanel creates the UI panel for Global Expedition AFK.
+// cfg is the startup config of profile; changes made in the UI are written back to the
+// active profile's config file. win hosts the confirm-first-click dialog.
+func NewGlobalExpeditionPanel(win fyne.Window, cfg config.Config, profile config.Profile) (fyne.CanvasObject, PanelControl) {
 	// --- Data Binding ---
 	logData := binding.NewStringList()
 	statusData := binding.NewString()
@@ -28,36 +62,388 @@ func NewGlobalExpeditionPanel() fyne.CanvasObject {
 
 	// Use specific GlobalBot instead of generic engine.Bot
 	gameBot := NewGlobalBot(logCallback, statusCallback, debugCallback)
+	gameBot.SetConfig(cfg)
 
-	// --- UI Components ---
+	// Keep the config file in sync with UI changes
+	saveConfig := func() {
+		if err := config.Save(profile.ConfigPath, cfg); err != nil {
+			appLogger.Error("Failed to save config: %v", err)
+		}
+	}
 
 	// --- UI Components ---
 	
 	// 1. Screen Selector
-	numDisplays := screenshot.NumActiveDisplays()
-	var displayOptions []string
-	for i := 0; i < numDisplays; i++ {
-		bounds := screenshot.GetDisplayBounds(i)
-		displayOptions = append(displayOptions, fmt.Sprintf("Display %d (%dx%d)", i, bounds.Dx(), bounds.Dy()))
+	// No displays usually means screen recording permission is missing: Start stays
+	// disabled until a refresh finds some
+	displayLabels := func(displays []image.Rectangle) []string {
+		var options []string
+		for i, bounds := range displays {
+			options = append(options, fmt.Sprintf("Display %d (%dx%d @ %d,%d)", i, bounds.Dx(), bounds.Dy(), bounds.Min.X, bounds.Min.Y))
+		}
+		return options
 	}
-	if len(displayOptions) == 0 {
-		displayOptions = []string{"Display 0 (Default)"}
+	displays := screen.ActiveDisplays()
+	displayOptions := displayLabels(displays)
+	// The saved monitor may have a different index now
+	if index, ok := screen.ResolveDisplay(cfg.DisplayID, displays); ok {
+		cfg.Display = index
 	}
 	
 	displaySelect := widget.NewSelect(displayOptions, func(selected string) {
 		var id int
 		_, err := fmt.Sscanf(selected, "Display %d", &id)
 		if err != nil { id = 0 }
-		gameBot.SetDisplayID(id)
+		if err := gameBot.SetDisplayID(id); err != nil {
+			appLogger.Error("Cannot switch display: %v", err)
+			return
+		}
 		appLogger.Info("Switched to Display %d", id)
+		displayID := cfg.DisplayID
+		if id < len(displays) {
+			displayID = screen.DisplayID(displays[id])
+		}
+		if cfg.Display != id || cfg.DisplayID != displayID {
+			cfg.Display = id
+			cfg.DisplayID = displayID
+			saveConfig()
+		}
 	})
-	if len(displayOptions) > 0 {
+	displaySelect.PlaceHolder = "No displays detected"
+	if cfg.Display < len(displayOptions) {
+		displaySelect.SetSelected(displayOptions[cfg.Display])
+	} else if len(displayOptions) > 0 {
 		displaySelect.SetSelected(displayOptions[0])
 	}
 	if displaySelect.Selected != "" {
 		var id int
 		fmt.Sscanf(displaySelect.Selected, "Display %d", &id)
-		gameBot.SetDisplayID(id)
+		if err := gameBot.SetDisplayID(id); err != nil {
+			appLogger.Error("Cannot use display %d: %v", id, err)
+		}
+	}
+
+	// Re-read the displays (e.g. after granting screen recording permission)
+	var refreshDisplays func()
+	refreshBtn := widget.NewButton("刷新 (Refresh)", func() { refreshDisplays() })
+
+	// Find the display the game is on by matching the state templates on every display
+	var locateBtn *widget.Button
+	locateBtn = widget.NewButton("自动检测游戏屏幕 (Auto-detect)", func() {
+		locateBtn.Disable()
+		go func() {
+			d, err := gameBot.LocateGameDisplay()
+			fyne.Do(func() {
+				if !gameBot.Running() {
+					locateBtn.Enable()
+				}
+				if err != nil {
+					appLogger.Error("Auto-detect game display failed: %v", err)
+					return
+				}
+				if d < len(displayOptions) {
+					displaySelect.SetSelected(displayOptions[d]) // Switches display and saves its id
+				}
+			})
+		}()
+	})
+
+	// Lobby waits in seconds: the max time in the lobby before giving up, and how often
+	// lobby.png is checked meanwhile (so the number of checks is the first over the second)
+	newLobbySecondsEntry := func(name string, current *config.Duration, apply func(time.Duration)) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetText(config.FormatSeconds(*current))
+		entry.OnSubmitted = func(text string) {
+			d, err := config.ParseSeconds(text)
+			if err == nil {
+				old := *current
+				*current = d
+				if err = cfg.Validate(); err != nil {
+					*current = old
+				}
+			}
+			if err != nil {
+				appLogger.Error("Invalid %s %q: %v", name, text, err)
+				entry.SetText(config.FormatSeconds(*current))
+				return
+			}
+			apply(d.D())
+			saveConfig()
+			appLogger.Info("%s set to %ss", name, config.FormatSeconds(d))
+		}
+		return entry
+	}
+	lobbyTimeoutEntry := newLobbySecondsEntry("max lobby wait", &cfg.LobbyTimeout, gameBot.SetLobbyTimeout)
+	lobbyPollEntry := newLobbySecondsEntry("lobby poll interval", &cfg.LobbyPollInterval, gameBot.SetLobbyPollInterval)
+
+	// Warm-up before the first scan (e.g. "2s", "0s"): time to switch to the game
+	warmUpEntry := widget.NewEntry()
+	warmUpEntry.SetText(cfg.WarmUp.D().String())
+	warmUpEntry.OnSubmitted = func(text string) {
+		d, err := time.ParseDuration(text)
+		if err != nil || d < 0 {
+			appLogger.Error("Invalid warm-up %q (use e.g. 2s, 0s)", text)
+			warmUpEntry.SetText(cfg.WarmUp.D().String())
+			return
+		}
+		gameBot.SetWarmUp(d)
+		cfg.WarmUp = config.Duration(d)
+		saveConfig()
+		appLogger.Info("Warm-up set to %v", d)
+	}
+
+	// Global tolerance as a similarity percentage (see screen.ToleranceFromSimilarity).
+	// 100% would allow no difference at all, so the slider stops at 99%.
+	similarityLabel := widget.NewLabel("")
+	showSimilarityLabel := func(percent, tol float64) {
+		similarityLabel.SetText(fmt.Sprintf("Similarity: %.0f%% (tolerance %.1f)", percent, tol))
+	}
+	similaritySlider := widget.NewSlider(0, 99)
+	similaritySlider.Step = 1
+	similaritySlider.OnChanged = func(percent float64) {
+		showSimilarityLabel(percent, screen.ToleranceFromSimilarity(percent))
+	}
+	showSimilarity := func() {
+		similaritySlider.SetValue(math.Round(screen.SimilarityFromTolerance(cfg.Tolerance)))
+		showSimilarityLabel(screen.SimilarityFromTolerance(cfg.Tolerance), cfg.Tolerance)
+	}
+	showSimilarity()
+
+	// Advanced: per-feature tolerances (empty = the global tolerance)
+	toleranceForm := widget.NewForm()
+	toleranceEntries := make(map[string]*widget.Entry)
+	showTolerance := func(feature string) {
+		entry := toleranceEntries[feature]
+		entry.SetPlaceHolder(fmt.Sprintf("%.0f (global)", cfg.Tolerance))
+		entry.SetText("")
+		if tol, ok := cfg.FeatureTolerances[feature]; ok {
+			entry.SetText(strconv.FormatFloat(tol, 'f', -1, 64))
+		}
+	}
+	for _, feature := range config.ToleranceFeatures {
+		feature := feature
+		entry := widget.NewEntry()
+		toleranceEntries[feature] = entry
+		showTolerance(feature)
+		entry.OnSubmitted = func(text string) {
+			tol := 0.0 // Cleared: fall back to the global tolerance
+			if text = strings.TrimSpace(text); text != "" {
+				v, err := strconv.ParseFloat(text, 64)
+				if err != nil || v <= 0 || v > config.MaxTolerance {
+					appLogger.Error("Invalid %s tolerance %q (use a positive number up to %.0f, or empty for the global tolerance)", feature, text, config.MaxTolerance)
+					return
+				}
+				tol = v
+			}
+			gameBot.SetFeatureTolerance(feature, tol)
+			tolerances := make(map[string]float64, len(cfg.FeatureTolerances)+1)
+			for k, v := range cfg.FeatureTolerances {
+				tolerances[k] = v
+			}
+			if tol == 0 {
+				delete(tolerances, feature)
+				appLogger.Info("%s tolerance reset to the global tolerance", feature)
+			} else {
+				tolerances[feature] = tol
+				appLogger.Info("%s tolerance set to %v", feature, tol)
+			}
+			cfg.FeatureTolerances = tolerances
+			saveConfig()
+		}
+		toleranceForm.Append(feature, entry)
+	}
+	advanced := widget.NewAccordion(widget.NewAccordionItem("高级 (Advanced): Tolerances", toleranceForm))
+
+	similaritySlider.OnChangeEnded = func(percent float64) {
+		tol := screen.ToleranceFromSimilarity(percent)
+		gameBot.SetTolerance(tol)
+		cfg.Tolerance = tol
+		cfg.Similarity = percent
+		saveConfig()
+		for _, feature := range config.ToleranceFeatures {
+			showTolerance(feature) // Placeholders show the global tolerance
+		}
+		appLogger.Info("Similarity set to %.0f%% (tolerance %.1f)", percent, tol)
+	}
+
+	// Post-click waits (e.g. "200ms", "1s"): settle time before the next capture
+	newClickWaitEntry := func(name string, current *config.Duration, apply func(time.Duration)) *widget.Entry {
+		entry := widget.NewEntry()
+		entry.SetText(current.D().String())
+		entry.OnSubmitted = func(text string) {
+			d, err := time.ParseDuration(text)
+			if err != nil || d < 0 {
+				appLogger.Error("Invalid %s click wait %q (use e.g. 200ms, 1s)", name, text)
+				entry.SetText(current.D().String())
+				return
+			}
+			apply(d)
+			*current = config.Duration(d)
+			saveConfig()
+			appLogger.Info("%s click wait set to %v", name, d)
+		}
+		return entry
+	}
+	entryWaitEntry := newClickWaitEntry("Entry", &cfg.EntryClickWait, gameBot.SetEntryClickWait)
+	searchWaitEntry := newClickWaitEntry("Search", &cfg.SearchClickWait, gameBot.SetSearchClickWait)
+
+	// Entry ROI margin in px: "100" (all sides) or "100,200" (sides/below, above)
+	roiMarginText := func() string {
+		if cfg.EntryROIMarginUp == cfg.EntryROIMargin {
+			return fmt.Sprintf("%d", cfg.EntryROIMargin)
+		}
+		return fmt.Sprintf("%d,%d", cfg.EntryROIMargin, cfg.EntryROIMarginUp)
+	}
+	roiMarginEntry := widget.NewEntry()
+	roiMarginEntry.SetText(roiMarginText())
+	roiMarginEntry.OnSubmitted = func(text string) {
+		var margin, up int
+		n, _ := fmt.Sscanf(text, "%d,%d", &margin, &up)
+		if n == 1 {
+			up = margin
+		}
+		if n == 0 || margin < 0 || up < 0 {
+			appLogger.Error("Invalid ROI margin %q (use e.g. 100 or 100,200)", text)
+			roiMarginEntry.SetText(roiMarginText())
+			return
+		}
+		gameBot.SetROIMargin(margin, up)
+		cfg.EntryROIMargin = margin
+		cfg.EntryROIMarginUp = up
+		saveConfig()
+		appLogger.Info("Entry ROI margin set to %dpx (up %dpx)", margin, up)
+	}
+
+	// Entry sort direction (applied on next Start)
+	lowestFirstCheck := widget.NewCheck("优先低分入口 (Lowest First)", func(checked bool) {
+		order := HighestFirst
+		if checked {
+			order = LowestFirst
+		}
+		gameBot.SetEntrySortOrder(order)
+		if cfg.EntrySortOrder != order.String() {
+			cfg.EntrySortOrder = order.String()
+			saveConfig()
+		}
+	})
+	lowestFirstCheck.SetChecked(cfg.EntrySortOrder == LowestFirst.String())
+
+	// Power saver: scan less often (e.g. on battery), can be toggled during a run
+	powerSaverLabel := func() string { return fmt.Sprintf("省电模式 (Power Saver, intervals x%g)", cfg.PowerSaverFactor) }
+	powerSaverCheck := widget.NewCheck(powerSaverLabel(), func(checked bool) {
+		gameBot.SetPowerSaver(checked)
+		if cfg.PowerSaver != checked {
+			cfg.PowerSaver = checked
+			saveConfig()
+		}
+	})
+	powerSaverCheck.SetChecked(cfg.PowerSaver)
+
+	// Log every entry detection of each scan (why a target was or wasn't chosen)
+	verboseCheck := widget.NewCheck("详细检测日志 (Log All Detections)", func(checked bool) {
+		gameBot.SetVerboseDetections(checked)
+		if cfg.VerboseDetections != checked {
+			cfg.VerboseDetections = checked
+			saveConfig()
+		}
+	})
+	verboseCheck.SetChecked(cfg.VerboseDetections)
+
+	// Confirm the first real click of each run (shows where it will click)
+	confirmCheck := widget.NewCheck("首次点击前确认 (Confirm First Click)", func(checked bool) {
+		gameBot.SetConfirmFirstClick(checked)
+		if cfg.ConfirmFirstClick != checked {
+			cfg.ConfirmFirstClick = checked
+			saveConfig()
+		}
+	})
+	confirmCheck.SetChecked(cfg.ConfirmFirstClick)
+
+	// Flash a marker where each click lands (click-through, fades after a second)
+	marker := newClickMarker(func() {
+		appLogger.Error("Click marker is not supported on this platform")
+	})
+	markerCheck := widget.NewCheck("点击标记 (Show Click Marker)", func(checked bool) {
+		if checked {
+			gameBot.SetClickFunc(func(_ string, at image.Point) { marker.Flash(at) })
+		} else {
+			gameBot.SetClickFunc(nil)
+			marker.Close()
+		}
+		if cfg.ClickMarker != checked {
+			cfg.ClickMarker = checked
+			saveConfig()
+		}
+	})
+	markerCheck.SetChecked(cfg.ClickMarker)
+	// Keep the control window above the game. The native window only exists once the
+	// app has started, so the saved setting is applied then.
+	windowShown := false
+	applyOnTop := func(onTop bool) {
+		if !window.SetAlwaysOnTop(win, onTop) && onTop {
+			appLogger.Error("Always on top is not supported on this platform")
+		}
+	}
+	onTopCheck := widget.NewCheck("窗口置顶 (Always on Top)", func(checked bool) {
+		if windowShown {
+			applyOnTop(checked)
+		}
+		if cfg.AlwaysOnTop != checked {
+			cfg.AlwaysOnTop = checked
+			saveConfig()
+		}
+	})
+	onTopCheck.SetChecked(cfg.AlwaysOnTop)
+	fyne.CurrentApp().Lifecycle().SetOnStarted(func() {
+		windowShown = true
+		if cfg.AlwaysOnTop {
+			applyOnTop(true)
+		}
+	})
+
+	gameBot.SetConfirmFunc(func(name string, at image.Point, preview image.Image, reply func(bool)) {
+		fyne.Do(func() {
+			var content fyne.CanvasObject = widget.NewLabel(fmt.Sprintf("即将点击 [%s] (%d, %d), 继续?\nAbout to click here - proceed?", name, at.X, at.Y))
+			if preview != nil {
+				img := canvas.NewImageFromImage(preview)
+				img.FillMode = canvas.ImageFillOriginal
+				content = container.NewVBox(content, widget.NewLabel("点击位置在预览中心 (center of the preview)"), img)
+			}
+			// Not a pausing modal: the bot is waiting on this answer to click
+			dialog.ShowCustomConfirm("确认首次点击", "继续 (Proceed)", "停止 (Stop)", content, reply, win)
+		})
+	})
+
+	// Diagnostics bundle for bug reports (logs, settings, a capture and a report)
+	diagBtn := widget.NewButton("生成诊断包 (Diagnostics)", func() {
+		d := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				modal.ShowError(err, win)
+				return
+			}
+			if writer == nil {
+				return // Cancelled
+			}
+			defer writer.Close()
+
+			files, err := gameBot.WriteDiagnostics(writer, profile.ConfigPath)
+			if err != nil {
+				modal.ShowError(err, win)
+				return
+			}
+			appLogger.Info("Diagnostics saved to %s (%d files)", writer.URI().Path(), len(files))
+			modal.ShowInformation("成功", fmt.Sprintf("已生成诊断包: %s\n%s", writer.URI().Path(), strings.Join(files, "\n")), win)
+		}, win)
+		d.SetFileName(fmt.Sprintf("diagnostics_%s.zip", time.Now().Format("20060102_150405")))
+		modal.Show(d)
+	})
+
+	// Debug: watch where the entry ROI fast path is looking (debug flag only)
+	roiBtn := widget.NewButton("ROI 叠加 (ROI Overlay)", func() {
+		showROIOverlay(gameBot)
+	})
+	if !cfg.Debug {
+		roiBtn.Hide()
 	}
 
 	// 2. Status & Logs
@@ -76,37 +462,345 @@ func NewGlobalExpeditionPanel() fyne.CanvasObject {
 		if len(list) > 0 { logList.ScrollToBottom() }
 	}))
 
+	// State transition history (bounded, newest at the bottom)
+	historyData := binding.NewStringList()
+	historyList := widget.NewListWithData(
+		historyData,
+		func() fyne.CanvasObject { return widget.NewLabel("History entry template") },
+		func(i binding.DataItem, o fyne.CanvasObject) { o.(*widget.Label).Bind(i.(binding.String)) },
+	)
+	gameBot.SetTransitionFunc(func(t StateTransition) {
+		historyData.Append(t.String())
+		list, _ := historyData.Get()
+		if len(list) > constants.StateHistorySize {
+			historyData.Set(list[len(list)-constants.StateHistorySize:])
+		}
+	})
+	historyData.AddListener(binding.NewDataListener(func() {
+		list, _ := historyData.Get()
+		if len(list) > 0 { historyList.ScrollToBottom() }
+	}))
+
+	// Profile selector (see switchProfile below)
+	profileSelect := widget.NewSelect(nil, nil)
+	newProfileBtn := widget.NewButton("新建 (New)", nil)
+
 	// 3. Buttons
 	startBtn := widget.NewButton("Start AFK", nil)
 	stopBtn := widget.NewButton("Stop", nil)
 	stopBtn.Disable()
 
+	// Compact mode: shrink the window to the status line (scan rate included), Pause and
+	// Stop, for watching a run over the game. The full UI is restored as it was.
+	compactStatus := widget.NewLabelWithData(statusData)
+	compactStatus.TextStyle = fyne.TextStyle{Bold: true}
+	compactStatus.Truncation = fyne.TextTruncateEllipsis
+	compactStopBtn := widget.NewButton("Stop", func() { gameBot.Stop() })
+	compactStopBtn.Disable()
+	// Pause holds the run like an open dialog does (see GlobalBot.Pause) until Resume
+	var resumeRun func() // nil = not paused from here
+	compactPauseBtn := widget.NewButton("暂停 (Pause)", nil)
+	compactPauseBtn.OnTapped = func() {
+		if resumeRun == nil {
+			resumeRun = gameBot.Pause("paused by user")
+			compactPauseBtn.SetText("继续 (Resume)")
+			return
+		}
+		resumeRun()
+		resumeRun = nil
+		compactPauseBtn.SetText("暂停 (Pause)")
+	}
+	compactPauseBtn.Disable()
+	var fullContent fyne.CanvasObject
+	var fullSize fyne.Size
+	restoreBtn := widget.NewButton("展开 (Full UI)", func() {
+		win.SetContent(fullContent)
+		win.Resize(fullSize)
+	})
+	compactView := container.NewBorder(nil, nil, nil, container.NewHBox(compactPauseBtn, compactStopBtn, restoreBtn), compactStatus)
+	compactBtn := widget.NewButton("精简模式 (Compact)", func() {
+		fullContent, fullSize = win.Content(), win.Canvas().Size()
+		win.SetContent(compactView)
+		win.Resize(fyne.NewSize(constants.CompactWindowWidth, compactView.MinSize().Height))
+	})
+
+	stepBtn := widget.NewButton("单步 (Step Once)", nil)
+
+	stopBtn.OnTapped = func() {
+		gameBot.Stop()
+	}
+
+	// Enable Start only when there is a display to capture (call while stopped)
+	updateDisplayState := func() {
+		if len(displays) == 0 {
+			startBtn.Disable()
+			stepBtn.Disable()
+			locateBtn.Disable()
+			statusData.Set("Status: No displays detected - check screen recording permissions")
+			appLogger.Error("No displays detected - check screen recording permissions, then refresh")
+			return
+		}
+		startBtn.Enable()
+		stepBtn.Enable()
+		locateBtn.Enable()
+	}
+
+	// Restore the stopped-state controls (call on the main goroutine)
+	resetControls := func() {
+		stopBtn.Disable()
+		compactStopBtn.Disable()
+		if resumeRun != nil { // A pause must not hold the next run
+			resumeRun()
+			resumeRun = nil
+			compactPauseBtn.SetText("暂停 (Pause)")
+		}
+		compactPauseBtn.Disable()
+		profileSelect.Enable()
+		newProfileBtn.Enable()
+		displaySelect.Enable()
+		refreshBtn.Enable()
+		updateDisplayState()
+		lobbyTimeoutEntry.Enable()
+		lobbyPollEntry.Enable()
+		lowestFirstCheck.Enable()
+	}
+
 	startBtn.OnTapped = func() {
 		statusData.Set("Status: Running")
 		startBtn.Disable()
+		stepBtn.Disable()
 		stopBtn.Enable()
+		compactStopBtn.Enable()
+		compactPauseBtn.Enable()
+		profileSelect.Disable()
+		newProfileBtn.Disable()
 		displaySelect.Disable()
-		gameBot.Start()
+		refreshBtn.Disable()
+		locateBtn.Disable()
+		lobbyTimeoutEntry.Disable()
+		lobbyPollEntry.Disable()
+		lowestFirstCheck.Disable()
+		// A failed start never runs the loop, so the stopped callback won't fire
+		if err := gameBot.Start(); err != nil {
+			statusData.Set("Status: Startup failed")
+			resetControls()
+		}
 	}
 
-	stopBtn.OnTapped = func() {
-		gameBot.Stop()
-		stopBtn.Disable()
-		startBtn.Enable()
-		displaySelect.Enable()
+	// Debug: run one tick on the current screen while stopped (see GlobalBot.Step)
+	stepBtn.OnTapped = func() {
+		startBtn.Disable()
+		stepBtn.Disable()
+		stopBtn.Enable() // Stop ends a step blocked in a wait
+		compactStopBtn.Enable()
+		go func() {
+			err := gameBot.Step()
+			fyne.Do(func() {
+				stopBtn.Disable()
+				compactStopBtn.Disable()
+				if err != nil {
+					appLogger.Error("Step failed: %v", err)
+				}
+				updateDisplayState()
+			})
+		}()
 	}
 
+	refreshDisplays = func() {
+		if gameBot.Running() {
+			return
+		}
+		displays = screen.ActiveDisplays()
+		displayOptions = displayLabels(displays)
+		displaySelect.SetOptions(displayOptions)
+		if index, ok := screen.ResolveDisplay(cfg.DisplayID, displays); ok {
+			cfg.Display = index
+		}
+		if len(displayOptions) == 0 {
+			displaySelect.ClearSelected()
+		} else if cfg.Display < len(displayOptions) {
+			displaySelect.SetSelected(displayOptions[cfg.Display])
+		} else {
+			displaySelect.SetSelected(displayOptions[0])
+		}
+		appLogger.Info("Found %d display(s)", len(displays))
+		if len(displays) > 0 {
+			statusData.Set("Status: Ready")
+		}
+		updateDisplayState()
+	}
+	updateDisplayState()
+
+	// Profile: one set of settings and templates per game (see config.Profile).
+	// Templates are loaded on Start, so switching while stopped needs no restart.
+	showConfig := func() {
+		lobbyTimeoutEntry.SetText(config.FormatSeconds(cfg.LobbyTimeout))
+		lobbyPollEntry.SetText(config.FormatSeconds(cfg.LobbyPollInterval))
+		warmUpEntry.SetText(cfg.WarmUp.D().String())
+		for _, feature := range config.ToleranceFeatures {
+			showTolerance(feature)
+		}
+		showSimilarity()
+		entryWaitEntry.SetText(cfg.EntryClickWait.D().String())
+		searchWaitEntry.SetText(cfg.SearchClickWait.D().String())
+		roiMarginEntry.SetText(roiMarginText())
+		lowestFirstCheck.SetChecked(cfg.EntrySortOrder == LowestFirst.String())
+		powerSaverCheck.Text = powerSaverLabel()
+		powerSaverCheck.SetChecked(cfg.PowerSaver)
+		powerSaverCheck.Refresh()
+		verboseCheck.SetChecked(cfg.VerboseDetections)
+		confirmCheck.SetChecked(cfg.ConfirmFirstClick)
+		markerCheck.SetChecked(cfg.ClickMarker)
+		onTopCheck.SetChecked(cfg.AlwaysOnTop)
+		refreshDisplays() // Selects the profile's display
+	}
+	listProfiles := func() {
+		names, err := config.ListProfiles()
+		if err != nil {
+			appLogger.Error("Cannot list profiles: %v", err)
+		}
+		profileSelect.SetOptions(names)
+	}
+	switchProfile := func(name string) {
+		if name == profile.Name {
+			return
+		}
+		if gameBot.Running() {
+			appLogger.Error("Stop the bot before switching profiles")
+			profileSelect.SetSelected(profile.Name)
+			return
+		}
+		p, err := config.ResolveProfile(name)
+		if err == nil {
+			var loaded config.Config
+			if loaded, err = config.LoadProfile(p); err == nil {
+				saveConfig() // The outgoing profile
+				profile, cfg = p, loaded
+			}
+		}
+		if err != nil {
+			appLogger.Error("Cannot switch to profile %s: %v", name, err)
+			profileSelect.SetSelected(profile.Name)
+			return
+		}
+		gameBot.SetConfig(cfg)
+		showConfig()
+		if err := config.SaveActiveProfile(profile.Name); err != nil {
+			appLogger.Error("Failed to save the active profile: %v", err)
+		}
+		appLogger.Info("Switched to profile %s (%s)", profile.Name, cfg.AssetsDir)
+	}
+	listProfiles()
+	profileSelect.SetSelected(profile.Name)
+	profileSelect.OnChanged = switchProfile
+
+	newProfileBtn.OnTapped = func() {
+		nameEntry := widget.NewEntry()
+		d := dialog.NewForm("新建配置 (New Profile)", "创建 (Create)", "取消 (Cancel)",
+			[]*widget.FormItem{widget.NewFormItem("Name", nameEntry)},
+			func(ok bool) {
+				if !ok {
+					return
+				}
+				p, err := config.CreateProfile(nameEntry.Text)
+				if err != nil {
+					modal.ShowError(err, win)
+					return
+				}
+				appLogger.Info("Created profile %s: add its templates under %s", p.Name, p.AssetsRoot)
+				listProfiles()
+				profileSelect.SetSelected(p.Name) // Switches to it
+			}, win)
+		modal.Show(d)
+	}
+
+	// Reset the controls whenever the bot stops (manual stop or auto-stop)
+	gameBot.SetAlertFuncs(
+		func(msg string) { appLogger.Error("%s", msg) },
+		func(title, msg string) { fyne.CurrentApp().SendNotification(fyne.NewNotification(title, msg)) },
+		func() { fyne.Do(resetControls) },
+	)
+
 	// --- Layout ---
 	controls := container.NewVBox(
 		widget.NewLabel("环球远征挂机配置:"),
-		container.NewHBox(widget.NewLabel("Screen:"), displaySelect),
+		container.NewBorder(nil, nil, widget.NewLabel("Profile:"), newProfileBtn, profileSelect),
+		container.NewHBox(widget.NewLabel("Screen:"), displaySelect, refreshBtn, locateBtn),
+		container.NewGridWithColumns(2,
+			container.NewBorder(nil, nil, widget.NewLabel("Max Lobby Wait (s):"), nil, lobbyTimeoutEntry),
+			container.NewBorder(nil, nil, widget.NewLabel("Lobby Poll (s):"), nil, lobbyPollEntry),
+		),
+		container.NewBorder(nil, nil, widget.NewLabel("ROI Margin:"), nil, roiMarginEntry),
+		container.NewBorder(nil, nil, widget.NewLabel("Warm-up:"), nil, warmUpEntry),
+		container.NewBorder(nil, nil, similarityLabel, nil, similaritySlider),
+		container.NewGridWithColumns(2,
+			container.NewBorder(nil, nil, widget.NewLabel("Entry Wait:"), nil, entryWaitEntry),
+			container.NewBorder(nil, nil, widget.NewLabel("Search Wait:"), nil, searchWaitEntry),
+		),
+		advanced,
+		lowestFirstCheck,
+		powerSaverCheck,
+		verboseCheck,
+		confirmCheck,
+		markerCheck,
+		onTopCheck,
 		statusLabel,
-		container.NewHBox(startBtn, stopBtn),
+		container.NewHBox(startBtn, stopBtn, stepBtn, compactBtn, diagBtn, roiBtn),
 		widget.NewSeparator(),
-		widget.NewLabel("运行日志:"),
 	)
 
-	return container.NewBorder(controls, nil, nil, nil, logList)
+	logTabs := container.NewAppTabs(
+		container.NewTabItem("运行日志", logList),
+		container.NewTabItem("状态历史", historyList),
+	)
+
+	var shutdownOnce sync.Once
+	control := PanelControl{
+		Running: gameBot.Running,
+		Pause:   gameBot.Pause,
+		AssetsRoot: func() string {
+			return profile.AssetsRoot
+		},
+		Tolerance: func() float64 {
+			return cfg.Tolerance
+		},
+		Shutdown: func() {
+			shutdownOnce.Do(func() {
+				gameBot.Stop()
+				saveConfig()
+				appLogger.Info("Shutting down")
+				appLogger.Close()
+			})
+		},
+		SetScanRegion: func(feature string, r image.Rectangle) {
+			gameBot.SetScanRegion(feature, r)
+			regions := make(map[string]config.Region, len(cfg.ScanRegions)+1)
+			for k, v := range cfg.ScanRegions {
+				regions[k] = v
+			}
+			if r.Empty() {
+				delete(regions, feature)
+				appLogger.Info("Cleared %s scan region", feature)
+			} else {
+				regions[feature] = config.NewRegion(r)
+				appLogger.Info("Set %s scan region to %v", feature, r)
+			}
+			cfg.ScanRegions = regions
+			saveConfig()
+		},
+		SetColorCalibration: func(n screen.Normalization) {
+			gameBot.SetColorCalibration(n)
+			cfg.ColorCalibration = config.NewColorCalibration(n)
+			if cfg.ColorCalibration == nil {
+				appLogger.Info("Cleared color calibration")
+			} else {
+				appLogger.Info("Color calibration set: gain %.3f, offset %.1f", n.Gain, n.Offset)
+			}
+			saveConfig()
+		},
+	}
+
+	return container.NewBorder(controls, nil, nil, nil, logTabs), control
 }
 
 /*
@@ -0,0 +1,68 @@
+// Package modal shows the app's dialogs and pauses the bot while any of them is
+// open, so it never clicks behind (or into) a dialog the user is working in.
+package modal
+
+import (
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+)
+
+// Reason is the pause reason reported while a dialog is open
+const Reason = "dialog open"
+
+var (
+	mu    sync.Mutex
+	pause func(reason string) (resume func())
+)
+
+// SetPauseFunc sets how an open dialog pauses the bot; the returned func resumes it
+// (nil = dialogs do not pause)
+func SetPauseFunc(f func(reason string) (resume func())) {
+	mu.Lock()
+	defer mu.Unlock()
+	pause = f
+}
+
+// Dialog is anything Show can pause for (dialog.Dialog, *dialog.FileDialog)
+type Dialog interface {
+	Show()
+	SetOnClosed(closed func())
+}
+
+// Show shows d and pauses the bot until it is closed
+func Show(d Dialog) {
+	mu.Lock()
+	f := pause
+	mu.Unlock()
+	if f != nil {
+		d.SetOnClosed(f(Reason))
+	}
+	d.Show()
+}
+
+// ShowError is dialog.ShowError, pausing the bot while open
+func ShowError(err error, parent fyne.Window) {
+	Show(dialog.NewError(err, parent))
+}
+
+// ShowInformation is dialog.ShowInformation, pausing the bot while open
+func ShowInformation(title, message string, parent fyne.Window) {
+	Show(dialog.NewInformation(title, message, parent))
+}
+
+// ShowConfirm is dialog.ShowConfirm, pausing the bot while open
+func ShowConfirm(title, message string, callback func(bool), parent fyne.Window) {
+	Show(dialog.NewConfirm(title, message, callback, parent))
+}
+
+// ShowCustomConfirm is dialog.ShowCustomConfirm, pausing the bot while open
+func ShowCustomConfirm(title, confirm, dismiss string, content fyne.CanvasObject, callback func(bool), parent fyne.Window) {
+	Show(dialog.NewCustomConfirm(title, confirm, dismiss, content, callback, parent))
+}
+
+// ShowFolderOpen is dialog.ShowFolderOpen, pausing the bot while open
+func ShowFolderOpen(callback func(fyne.ListableURI, error), parent fyne.Window) {
+	Show(dialog.NewFolderOpen(callback, parent))
+}
@@ -1,18 +1,84 @@
 package normal
 
 import (
+	"fmt"
+	"math"
+
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/widget"
+
+	"github.com/ConserveLee/gui-idle/internal/engine"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+	"github.com/ConserveLee/gui-idle/internal/logger"
 )
 
-// NewNormalLevelPanel creates the UI panel for Normal Level AFK (Placeholder)
+// NewNormalLevelPanel creates the UI panel for Normal Level AFK, driving a generic
+// engine.Bot that clicks the templates in its assets directory
 func NewNormalLevelPanel() fyne.CanvasObject {
-	return container.NewCenter(
-		container.NewVBox(
-			widget.NewLabel("普通关卡挂机功能开发中..."),
-			widget.NewIcon(nil), // Placeholder icon
-			widget.NewButton("敬请期待 (TODO)", func() {}),
-		),
+	// --- Data Binding ---
+	logData := binding.NewStringList()
+	statusData := binding.NewString()
+	statusData.Set("Status: Ready")
+
+	appLogger := logger.NewAppLogger(logData)
+
+	// --- Bot Initialization ---
+	bot := engine.NewBot(
+		func(msg string) { appLogger.Info(msg) },
+		func(msg string) { statusData.Set(msg) },
+		func(format string, args ...interface{}) { appLogger.Debug(format, args...) },
+	)
+
+	// --- UI Components ---
+
+	// Tolerance as a similarity percentage, like the global panel's slider.
+	// 100% would allow no difference at all, so the slider stops at 99%.
+	similarityLabel := widget.NewLabel("")
+	showSimilarityLabel := func(percent, tol float64) {
+		similarityLabel.SetText(fmt.Sprintf("Similarity: %.0f%% (tolerance %.1f)", percent, tol))
+	}
+	similaritySlider := widget.NewSlider(0, 99)
+	similaritySlider.Step = 1
+	similaritySlider.SetValue(math.Round(screen.SimilarityFromTolerance(bot.Config.Tolerance)))
+	showSimilarityLabel(screen.SimilarityFromTolerance(bot.Config.Tolerance), bot.Config.Tolerance)
+	similaritySlider.OnChanged = func(percent float64) {
+		showSimilarityLabel(percent, screen.ToleranceFromSimilarity(percent))
+	}
+	// Applied when the drag ends; a running bot picks it up on its next scan
+	similaritySlider.OnChangeEnded = func(percent float64) {
+		tol := screen.ToleranceFromSimilarity(percent)
+		bot.SetTolerance(tol)
+		appLogger.Info("Similarity set to %.0f%% (tolerance %.1f)", percent, tol)
+	}
+
+	var startBtn, stopBtn *widget.Button
+	startBtn = widget.NewButton("开始 (Start)", func() {
+		bot.Start()
+		if bot.Running() { // Start logs and returns early if no assets load
+			startBtn.Disable()
+			stopBtn.Enable()
+		}
+	})
+	stopBtn = widget.NewButton("停止 (Stop)", func() {
+		bot.Stop()
+		startBtn.Enable()
+		stopBtn.Disable()
+	})
+	stopBtn.Disable()
+
+	logList := widget.NewListWithData(
+		logData,
+		func() fyne.CanvasObject { return widget.NewLabel("Log entry template") },
+		func(i binding.DataItem, o fyne.CanvasObject) { o.(*widget.Label).Bind(i.(binding.String)) },
+	)
+
+	top := container.NewVBox(
+		widget.NewLabel(fmt.Sprintf("Clicks the templates in %s", bot.Config.AssetsDir)),
+		container.NewBorder(nil, nil, similarityLabel, nil, similaritySlider),
+		container.NewGridWithColumns(2, startBtn, stopBtn),
+		widget.NewLabelWithData(statusData),
 	)
-}
\ No newline at end of file
+	return container.NewBorder(top, nil, nil, nil, logList)
+}
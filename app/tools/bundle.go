@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// bundleDirs are the asset subdirectories included in a template bundle
+var bundleDirs = []string{"global_targets", "normal_targets"}
+
+// ConflictPolicy decides what to do when an imported file already exists
+type ConflictPolicy int
+
+const (
+	ConflictSkip      ConflictPolicy = iota // Keep the existing file
+	ConflictOverwrite                       // Replace the existing file
+	ConflictRename                          // Save as "name_1.png", "name_2.png", ...
+)
+
+// ImportResult summarizes an ImportBundle call
+type ImportResult struct {
+	Written []string // Paths written (relative to the assets dir)
+	Skipped []string // Paths skipped because they already existed
+	Renamed int      // Files saved under a new name
+}
+
+// ExportBundle zips all template directories under assetsDir into w,
+// preserving the directory structure (e.g. "global_targets/find_game/games/20.png").
+func ExportBundle(assetsDir string, w io.Writer) (int, error) {
+	zw := zip.NewWriter(w)
+	count := 0
+
+	for _, dir := range bundleDirs {
+		root := filepath.Join(assetsDir, dir)
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(assetsDir, p)
+			if err != nil {
+				return err
+			}
+
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			entry, err := zw.Create(filepath.ToSlash(rel))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(entry, f); err != nil {
+				return err
+			}
+			count++
+			return nil
+		})
+		if err != nil {
+			zw.Close()
+			return count, err
+		}
+	}
+
+	return count, zw.Close()
+}
+
+// ImportBundle extracts a template bundle into assetsDir.
+// Only entries under the known template directories are extracted.
+func ImportBundle(r io.ReaderAt, size int64, assetsDir string, policy ConflictPolicy) (ImportResult, error) {
+	var result ImportResult
+
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return result, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		rel, err := bundleEntryPath(file.Name)
+		if err != nil {
+			return result, err
+		}
+
+		target := filepath.Join(assetsDir, filepath.FromSlash(rel))
+		if _, err := os.Stat(target); err == nil {
+			switch policy {
+			case ConflictSkip:
+				result.Skipped = append(result.Skipped, rel)
+				continue
+			case ConflictRename:
+				target = uniquePath(target)
+				result.Renamed++
+			}
+		}
+
+		if err := extractZipFile(file, target); err != nil {
+			return result, err
+		}
+		written, _ := filepath.Rel(assetsDir, target)
+		result.Written = append(result.Written, filepath.ToSlash(written))
+	}
+
+	return result, nil
+}
+
+// bundleEntryPath validates a zip entry name and returns it cleaned.
+// Rejects absolute paths, ".." escapes and entries outside the template directories.
+func bundleEntryPath(name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid bundle entry %q", name)
+	}
+	for _, dir := range bundleDirs {
+		if strings.HasPrefix(clean, dir+"/") {
+			return clean, nil
+		}
+	}
+	return "", fmt.Errorf("bundle entry %q is outside %v", name, bundleDirs)
+}
+
+// uniquePath appends _1, _2, ... before the extension until the path is free
+func uniquePath(p string) string {
+	ext := filepath.Ext(p)
+	base := strings.TrimSuffix(p, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s_%d%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+func extractZipFile(file *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
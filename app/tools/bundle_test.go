@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeAssets creates files (relative path -> content) under dir
+func writeAssets(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// readAsset returns the content of a file under dir, or "" if it is missing
+func readAsset(t *testing.T, dir, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"global_targets/find_game/games/20.png":  "game 20",
+		"global_targets/find_game/games/20.json": `{"hold": "1s"}`,
+		"global_targets/in_game/1.png":           "in game",
+		"normal_targets/ok.png":                  "ok",
+	}
+	writeAssets(t, src, files)
+	writeAssets(t, src, map[string]string{"config.yaml": "not a template"})
+
+	var buf bytes.Buffer
+	count, err := ExportBundle(src, &buf)
+	if err != nil || count != len(files) {
+		t.Fatalf("ExportBundle = %d, %v; want %d files", count, err, len(files))
+	}
+
+	dst := t.TempDir()
+	result, err := ImportBundle(bytes.NewReader(buf.Bytes()), int64(buf.Len()), dst, ConflictSkip)
+	if err != nil {
+		t.Fatalf("ImportBundle = %v", err)
+	}
+	if len(result.Written) != len(files) || len(result.Skipped) != 0 {
+		t.Errorf("imported %v, skipped %v; want all %d files", result.Written, result.Skipped, len(files))
+	}
+	for rel, content := range files {
+		if got := readAsset(t, dst, rel); got != content {
+			t.Errorf("%s = %q, want %q", rel, got, content)
+		}
+	}
+	if got := readAsset(t, dst, "config.yaml"); got != "" {
+		t.Error("bundle included a file outside the template directories")
+	}
+}
+
+func TestImportBundleConflicts(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"global_targets/in_game/1.png": "new",
+		"global_targets/in_game/2.png": "fresh",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		policy  ConflictPolicy
+		written []string
+		skipped []string
+		files   map[string]string
+	}{
+		{
+			policy:  ConflictSkip,
+			written: []string{"global_targets/in_game/2.png"},
+			skipped: []string{"global_targets/in_game/1.png"},
+			files:   map[string]string{"global_targets/in_game/1.png": "old", "global_targets/in_game/2.png": "fresh"},
+		},
+		{
+			policy:  ConflictOverwrite,
+			written: []string{"global_targets/in_game/1.png", "global_targets/in_game/2.png"},
+			files:   map[string]string{"global_targets/in_game/1.png": "new", "global_targets/in_game/2.png": "fresh"},
+		},
+		{
+			// 1_1.png is taken too, so the import moves on to 1_2.png
+			policy:  ConflictRename,
+			written: []string{"global_targets/in_game/1_2.png", "global_targets/in_game/2.png"},
+			files: map[string]string{
+				"global_targets/in_game/1.png":   "old",
+				"global_targets/in_game/1_1.png": "older",
+				"global_targets/in_game/1_2.png": "new",
+				"global_targets/in_game/2.png":   "fresh",
+			},
+		},
+	}
+	for _, tt := range tests {
+		dst := t.TempDir()
+		writeAssets(t, dst, map[string]string{"global_targets/in_game/1.png": "old", "global_targets/in_game/1_1.png": "older"})
+
+		result, err := ImportBundle(bytes.NewReader(buf.Bytes()), int64(buf.Len()), dst, tt.policy)
+		if err != nil {
+			t.Fatalf("policy %d: ImportBundle = %v", tt.policy, err)
+		}
+		slices.Sort(result.Written)
+		if !slices.Equal(result.Written, tt.written) || !slices.Equal(result.Skipped, tt.skipped) {
+			t.Errorf("policy %d: wrote %v, skipped %v; want %v, %v", tt.policy, result.Written, result.Skipped, tt.written, tt.skipped)
+		}
+		for rel, content := range tt.files {
+			if got := readAsset(t, dst, rel); got != content {
+				t.Errorf("policy %d: %s = %q, want %q", tt.policy, rel, got, content)
+			}
+		}
+	}
+}
+
+func TestImportBundleRejectsEscapes(t *testing.T) {
+	for _, name := range []string{"../evil.png", "/etc/evil.png", "global_targets/../../evil.png", "other/evil.png"} {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		if _, err := zw.Create(name); err != nil {
+			t.Fatal(err)
+		}
+		zw.Close()
+
+		dst := t.TempDir()
+		if _, err := ImportBundle(bytes.NewReader(buf.Bytes()), int64(buf.Len()), dst, ConflictOverwrite); err == nil {
+			t.Errorf("ImportBundle accepted the entry %q", name)
+		}
+	}
+}
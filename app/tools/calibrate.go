@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/ConserveLee/gui-idle/app/modal"
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+	"github.com/kbinani/screenshot"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// showColorCalibration picks a reference swatch (a PNG with the true colors, e.g. a
+// template cut on a machine where matching works), finds it on the current screen and
+// fits the per-channel color correction that maps the capture back onto it.
+// Applying saves the correction to the settings through setCalibration.
+func showColorCalibration(win fyne.Window, displayID int, setCalibration func(screen.Normalization)) {
+	d := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		refPath := reader.URI().Path()
+		reader.Close()
+
+		searcher := screen.NewSearcher()
+		ref, err := searcher.LoadImage(refPath)
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		frame, err := screenshot.CaptureRect(screenshot.GetDisplayBounds(displayID))
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+
+		progress := dialog.NewCustomWithoutButtons("颜色校准", canvas.NewText("查找参考色块... (Locating swatch)", nil), win)
+		modal.Show(progress)
+		go func() {
+			norm, at, fitErr := searcher.CalibrateNormalization(frame, ref, constants.CalibrationTolerance)
+			fyne.Do(func() {
+				progress.Hide()
+				if fitErr != nil {
+					modal.ShowError(fitErr, win)
+					return
+				}
+				msg := fmt.Sprintf("参考色块位于 (%d, %d)\nSwatch found at (%d, %d)\n\nGain (R, G, B): %.3f\nOffset (R, G, B): %.1f\n\n应用此校准? (Apply to captures)",
+					at.X, at.Y, at.X, at.Y, norm.Gain, norm.Offset)
+				modal.ShowConfirm("颜色校准 (Color Calibration)", msg, func(apply bool) {
+					if apply && setCalibration != nil {
+						setCalibration(norm)
+					}
+				}, win)
+			})
+		}()
+	}, win)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".png"}))
+	modal.Show(d)
+}
@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"image"
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/widget"
+)
+
+// gridMinCell is the smallest on-screen pixel size (in fyne units) that gets grid lines
+const gridMinCell = 4
+
+var gridColor = color.NRGBA{R: 128, G: 128, B: 128, A: 160}
+
+// ColorPickImage displays an image and reports the pixel color under a tap.
+// Used to pick a template's chroma key (background color to ignore).
+// It can be zoomed to a whole number of screen units per pixel and overlay a pixel grid.
+type ColorPickImage struct {
+	widget.BaseWidget
+
+	img     image.Image
+	raster  *canvas.Image
+	minSize fyne.Size
+	fitSize fyne.Size // minSize when not zoomed
+	grid    bool
+
+	OnPicked func(c color.Color)
+}
+
+func NewColorPickImage(img image.Image, minSize fyne.Size, onPicked func(color.Color)) *ColorPickImage {
+	p := &ColorPickImage{img: img, minSize: minSize, fitSize: minSize, OnPicked: onPicked}
+	p.ExtendBaseWidget(p)
+
+	p.raster = canvas.NewImageFromImage(img)
+	p.raster.FillMode = canvas.ImageFillContain
+	p.raster.ScaleMode = canvas.ImageScalePixels
+	return p
+}
+
+// SetZoom shows each image pixel as zoom x zoom units (0 = fit the default size)
+func (p *ColorPickImage) SetZoom(zoom int) {
+	if zoom <= 0 {
+		p.minSize = p.fitSize
+	} else {
+		b := p.img.Bounds()
+		p.minSize = fyne.NewSize(float32(b.Dx()*zoom), float32(b.Dy()*zoom))
+	}
+	p.Refresh()
+}
+
+// SetGrid turns the pixel grid overlay on or off.
+// Lines are only drawn once pixels are at least gridMinCell units wide.
+func (p *ColorPickImage) SetGrid(on bool) {
+	p.grid = on
+	p.Refresh()
+}
+
+func (p *ColorPickImage) CreateRenderer() fyne.WidgetRenderer {
+	return &colorPickRenderer{picker: p}
+}
+
+// layout returns the image scale and letterbox offset for the widget size (ImageFillContain)
+func (p *ColorPickImage) layout(size fyne.Size) (scale, offX, offY float32, ok bool) {
+	bounds := p.img.Bounds()
+	imgW, imgH := float32(bounds.Dx()), float32(bounds.Dy())
+	if size.Width == 0 || size.Height == 0 || imgW == 0 || imgH == 0 {
+		return 0, 0, 0, false
+	}
+	scale = size.Width / imgW
+	if s := size.Height / imgH; s < scale {
+		scale = s
+	}
+	offX = (size.Width - imgW*scale) / 2
+	offY = (size.Height - imgH*scale) / 2
+	return scale, offX, offY, true
+}
+
+func (p *ColorPickImage) Tapped(e *fyne.PointEvent) {
+	if p.OnPicked == nil {
+		return
+	}
+
+	// Map the tap to an image pixel (the image is letterboxed by ImageFillContain)
+	scale, offX, offY, ok := p.layout(p.Size())
+	if !ok {
+		return
+	}
+
+	bounds := p.img.Bounds()
+	x := bounds.Min.X + int((e.Position.X-offX)/scale)
+	y := bounds.Min.Y + int((e.Position.Y-offY)/scale)
+	if !(image.Point{X: x, Y: y}.In(bounds)) {
+		return
+	}
+	p.OnPicked(p.img.At(x, y))
+}
+
+// Cursor
+func (p *ColorPickImage) Cursor() desktop.Cursor {
+	return desktop.CrosshairCursor
+}
+
+type colorPickRenderer struct {
+	picker *ColorPickImage
+	lines  []fyne.CanvasObject // Pixel grid overlay
+}
+
+func (r *colorPickRenderer) Layout(s fyne.Size) {
+	r.picker.raster.Resize(s)
+	r.picker.raster.Move(fyne.NewPos(0, 0))
+	r.layoutGrid(s)
+}
+
+// layoutGrid rebuilds the grid lines on the pixel boundaries of the letterboxed image
+func (r *colorPickRenderer) layoutGrid(s fyne.Size) {
+	r.lines = r.lines[:0]
+	scale, offX, offY, ok := r.picker.layout(s)
+	if !r.picker.grid || !ok || scale < gridMinCell {
+		return
+	}
+
+	b := r.picker.img.Bounds()
+	w, h := float32(b.Dx())*scale, float32(b.Dy())*scale
+	for i := 0; i <= b.Dx(); i++ {
+		x := offX + float32(i)*scale
+		r.lines = append(r.lines, gridLine(fyne.NewPos(x, offY), fyne.NewPos(x, offY+h)))
+	}
+	for j := 0; j <= b.Dy(); j++ {
+		y := offY + float32(j)*scale
+		r.lines = append(r.lines, gridLine(fyne.NewPos(offX, y), fyne.NewPos(offX+w, y)))
+	}
+}
+
+func gridLine(from, to fyne.Position) *canvas.Line {
+	l := canvas.NewLine(gridColor)
+	l.StrokeWidth = 1
+	l.Position1 = from
+	l.Position2 = to
+	return l
+}
+
+func (r *colorPickRenderer) MinSize() fyne.Size {
+	return r.picker.minSize
+}
+
+func (r *colorPickRenderer) Refresh() {
+	r.layoutGrid(r.picker.Size())
+	canvas.Refresh(r.picker.raster)
+}
+
+func (r *colorPickRenderer) Objects() []fyne.CanvasObject {
+	return append([]fyne.CanvasObject{r.picker.raster}, r.lines...)
+}
+
+func (r *colorPickRenderer) Destroy() {}
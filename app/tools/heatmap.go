@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/app/modal"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+	"github.com/kbinani/screenshot"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// showMatchHeatmap picks a template, scores it at every position of the current
+// screen and shows (and saves to logs/) the heatmap: bright = close match.
+// Near-miss regions show up as bright spots away from the real button.
+func showMatchHeatmap(win fyne.Window, displayID int) {
+	d := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		tplPath := reader.URI().Path()
+		reader.Close()
+
+		searcher := screen.NewSearcher()
+		tpl, err := searcher.LoadImage(tplPath)
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		frame, err := screenshot.CaptureRect(screenshot.GetDisplayBounds(displayID))
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+
+		progress := dialog.NewCustomWithoutButtons("匹配热力图", canvas.NewText("计算中... (Computing)", nil), win)
+		modal.Show(progress)
+		go func() {
+			heatmap := searcher.MatchHeatmap(frame, tpl)
+			outPath, saveErr := saveHeatmap(heatmap, tplPath)
+			fyne.Do(func() {
+				progress.Hide()
+				if saveErr != nil {
+					modal.ShowError(saveErr, win)
+				}
+				showHeatmapWindow(heatmap, tplPath, outPath)
+			})
+		}()
+	}, win)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".png"}))
+	modal.Show(d)
+}
+
+// saveHeatmap writes the heatmap to logs/heatmap_<template>_<time>.png
+func saveHeatmap(img image.Image, tplPath string) (string, error) {
+	if err := os.MkdirAll("logs", 0755); err != nil {
+		return "", err
+	}
+	name := strings.TrimSuffix(filepath.Base(tplPath), filepath.Ext(tplPath))
+	outPath := filepath.Join("logs", fmt.Sprintf("heatmap_%s_%s.png", name, time.Now().Format("20060102_150405")))
+
+	f, err := os.Create(outPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return outPath, png.Encode(f, img)
+}
+
+func showHeatmapWindow(heatmap image.Image, tplPath, outPath string) {
+	w := fyne.CurrentApp().NewWindow(fmt.Sprintf("匹配热力图 (Match Heatmap): %s", filepath.Base(tplPath)))
+	w.Resize(fyne.NewSize(900, 600))
+
+	raster := canvas.NewImageFromImage(heatmap)
+	raster.FillMode = canvas.ImageFillContain
+	raster.ScaleMode = canvas.ImageScalePixels
+
+	w.SetContent(raster)
+	if outPath != "" {
+		fmt.Printf("Saved heatmap: %s\n", outPath)
+	}
+	w.Show()
+}
@@ -0,0 +1,269 @@
+package tools
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/app/global"
+	"github.com/ConserveLee/gui-idle/app/modal"
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/input"
+	"github.com/go-vgo/robotgo"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showMacroWindow records a macro of clicks and key presses on the display and
+// replays it there (see input.Macro), for deterministic steps that need no
+// matching. While recording, resting the cursor on a spot records a click there
+// (see input.MacroRecorder); key presses are added from the window. The macro is
+// shown as editable JSON, so delays can be tuned before saving or playing.
+func showMacroWindow(displayID int) {
+	w := fyne.CurrentApp().NewWindow(fmt.Sprintf("宏 (Macro): Display %d", displayID))
+	w.Resize(fyne.NewSize(420, 520))
+
+	x, y, _, _ := robotgo.GetDisplayBounds(displayID)
+	origin := image.Pt(x, y)
+
+	status := widget.NewLabel("录制: 光标停留 = 点击 (Record: rest the cursor to click)")
+	status.Wrapping = fyne.TextWrapWord
+	stepsEntry := widget.NewMultiLineEntry()
+	stepsEntry.SetPlaceHolder(`{"steps": [{"delay_ms": 500, "op": "click", "x": 100, "y": 200}]}`)
+
+	showMacro := func(m input.Macro) {
+		data, err := m.Marshal()
+		if err != nil {
+			status.SetText(err.Error())
+			return
+		}
+		stepsEntry.SetText(string(data))
+	}
+	// current parses the (possibly edited) steps
+	current := func() (input.Macro, bool) {
+		m, err := input.ParseMacro([]byte(stepsEntry.Text))
+		if err != nil {
+			modal.ShowError(err, w)
+			return m, false
+		}
+		return m, true
+	}
+
+	// --- Recording ---
+	var mu sync.Mutex // Guards rec: polled off the UI thread
+	var rec *input.MacroRecorder
+	var stopRecording chan struct{} // nil = not recording
+
+	keyEntry := widget.NewEntry()
+	keyEntry.SetPlaceHolder("esc, space, f5, a...")
+	addKeyBtn := widget.NewButton("添加按键 (Add Key)", func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if rec == nil || keyEntry.Text == "" {
+			return
+		}
+		if !global.IsSupportedKey(keyEntry.Text) {
+			status.SetText(fmt.Sprintf("不支持的按键 (Unsupported key) %q", keyEntry.Text))
+			return
+		}
+		rec.Key(keyEntry.Text, time.Now())
+		showMacro(rec.Macro())
+	})
+	addKeyBtn.Disable()
+
+	var recordBtn *widget.Button
+	endRecording := func() {
+		close(stopRecording)
+		stopRecording = nil
+		fyne.CurrentApp().Lifecycle().SetOnEnteredForeground(nil)
+		mu.Lock()
+		showMacro(rec.Macro())
+		rec = nil
+		mu.Unlock()
+		recordBtn.SetText("录制 (Record)")
+		addKeyBtn.Disable()
+		status.SetText("录制结束 (Recording stopped)")
+	}
+	recordBtn = widget.NewButton("录制 (Record)", func() {
+		if stopRecording != nil {
+			endRecording()
+			return
+		}
+		mu.Lock()
+		rec = input.NewMacroRecorder(origin, constants.MacroDwell, constants.MacroRestRadius, time.Now())
+		mu.Unlock()
+		stopRecording = make(chan struct{})
+		// Turning to this window is not part of the macro
+		fyne.CurrentApp().Lifecycle().SetOnEnteredForeground(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if rec != nil {
+				rec.Interrupt()
+				showMacro(rec.Macro())
+			}
+		})
+		recordBtn.SetText("停止 (Stop)")
+		addKeyBtn.Enable()
+		status.SetText(fmt.Sprintf("录制中... 光标停留 %v = 点击 (Recording: rest the cursor %v to click)", constants.MacroDwell, constants.MacroDwell))
+		stepsEntry.SetText("")
+
+		go func(stop chan struct{}) {
+			ticker := time.NewTicker(constants.MacroPollInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case now := <-ticker.C:
+					cx, cy := robotgo.Location()
+					mu.Lock()
+					clicked := rec != nil && rec.Observe(image.Pt(cx, cy), now)
+					var m input.Macro
+					if clicked {
+						m = rec.Macro()
+					}
+					mu.Unlock()
+					if clicked {
+						fyne.Do(func() { showMacro(m) })
+					}
+				}
+			}
+		}(stopRecording)
+	})
+	recordBtn.Importance = widget.HighImportance
+
+	// --- Replay ---
+	var cancelPlay chan struct{} // nil = not playing
+	var playBtn *widget.Button
+	cancel := func() {
+		close(cancelPlay)
+		playBtn.Disable() // Until the replay has wound down
+	}
+	playBtn = widget.NewButton("回放 (Play)", func() {
+		if cancelPlay != nil {
+			cancel()
+			return
+		}
+		m, ok := current()
+		if !ok || len(m.Steps) == 0 {
+			return
+		}
+		cancelPlay = make(chan struct{})
+		playBtn.SetText("取消 (Cancel)")
+		clicker := &input.Clicker{
+			Actions: input.Robot{},
+			OffsetX: origin.X,
+			OffsetY: origin.Y,
+			Display: displayID,
+			Scale:   robotgo.SysScale(displayID),
+			LogFunc: func(msg string) { fyne.Do(func() { status.SetText(msg) }) },
+		}
+		go func(cancel chan struct{}) {
+			wait := func(d time.Duration) bool {
+				t := time.NewTimer(d)
+				defer t.Stop()
+				select {
+				case <-cancel:
+					return false
+				case <-t.C:
+					return true
+				}
+			}
+			fyne.Do(func() {
+				status.SetText(fmt.Sprintf("%v 后开始, 请切换到游戏 (Starting in %v, switch to the game)", constants.MacroStartDelay, constants.MacroStartDelay))
+			})
+			done := 0
+			if wait(constants.MacroStartDelay) {
+				done = m.Play(clicker, wait)
+			}
+			fyne.Do(func() {
+				status.SetText(fmt.Sprintf("回放 %d/%d 步 (Played %d of %d steps)", done, len(m.Steps), done, len(m.Steps)))
+				playBtn.SetText("回放 (Play)")
+				playBtn.Enable()
+				cancelPlay = nil
+			})
+		}(cancelPlay)
+	})
+
+	// --- Files ---
+	loadBtn := widget.NewButton("打开 (Open)", func() {
+		d := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				modal.ShowError(err, w)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			defer reader.Close()
+			data, err := io.ReadAll(reader)
+			if err != nil {
+				modal.ShowError(err, w)
+				return
+			}
+			m, err := input.ParseMacro(data)
+			if err != nil {
+				modal.ShowError(err, w)
+				return
+			}
+			showMacro(m)
+			status.SetText(fmt.Sprintf("%s: %d 步, %v (%d steps)", reader.URI().Name(), len(m.Steps), m.Duration(), len(m.Steps)))
+		}, w)
+		d.SetFilter(storage.NewExtensionFileFilter([]string{".json"}))
+		modal.Show(d)
+	})
+	saveBtn := widget.NewButton("保存 (Save)", func() {
+		m, ok := current()
+		if !ok {
+			return
+		}
+		d := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				modal.ShowError(err, w)
+				return
+			}
+			if writer == nil {
+				return // Cancelled
+			}
+			defer writer.Close()
+			data, err := m.Marshal()
+			if err == nil {
+				_, err = writer.Write(data)
+			}
+			if err != nil {
+				modal.ShowError(err, w)
+				return
+			}
+			status.SetText(fmt.Sprintf("已保存 (Saved): %s", writer.URI().Path()))
+		}, w)
+		d.SetFileName(fmt.Sprintf("macro_%s.json", time.Now().Format("20060102_150405")))
+		modal.Show(d)
+	})
+
+	w.SetOnClosed(func() {
+		if stopRecording != nil {
+			endRecording()
+		}
+		if cancelPlay != nil && !playBtn.Disabled() {
+			cancel()
+		}
+	})
+
+	w.SetContent(container.NewBorder(
+		container.NewVBox(
+			status,
+			container.NewGridWithColumns(2, recordBtn, playBtn),
+			container.NewBorder(nil, nil, nil, addKeyBtn, keyEntry),
+		),
+		container.NewGridWithColumns(2, loadBtn, saveBtn),
+		nil, nil,
+		stepsEntry,
+	))
+	w.Show()
+}
@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ConserveLee/gui-idle/app/global"
+	"github.com/ConserveLee/gui-idle/app/modal"
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/input"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+	"github.com/go-vgo/robotgo"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// previewMatch is one template match shown in the detection preview
+type previewMatch struct {
+	Template string
+	Pos      image.Point // Top-left in frame coordinates
+	Size     image.Point // Template dimensions
+}
+
+func (m previewMatch) String() string {
+	return fmt.Sprintf("%s at (%d, %d)", m.Template, m.Pos.X, m.Pos.Y)
+}
+
+// detectInFrame runs every PNG template in templateDir against frame
+func detectInFrame(searcher *screen.Searcher, frame image.Image, templateDir string, tolerance float64) ([]previewMatch, error) {
+	files, err := filepath.Glob(filepath.Join(templateDir, "*.png"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no PNG templates in %s", templateDir)
+	}
+	sort.Strings(files)
+
+	var matches []previewMatch
+	for _, file := range files {
+		if global.IsDisabled(file) {
+			continue // Match what the bot would load
+		}
+		tpl, err := searcher.LoadImage(file)
+		if err != nil {
+			continue
+		}
+		size := image.Point{X: tpl.Bounds().Dx(), Y: tpl.Bounds().Dy()}
+		for _, p := range searcher.FindAllTemplates(frame, tpl, tolerance) {
+			matches = append(matches, previewMatch{Template: filepath.Base(file), Pos: p, Size: size})
+		}
+	}
+	return matches, nil
+}
+
+// drawMatches returns a copy of frame with a red box around each match
+func drawMatches(frame image.Image, matches []previewMatch) *image.RGBA {
+	out := image.NewRGBA(frame.Bounds())
+	draw.Draw(out, out.Bounds(), frame, frame.Bounds().Min, draw.Src)
+
+	red := color.RGBA{R: 255, A: 255}
+	for _, m := range matches {
+		r := image.Rectangle{Min: m.Pos, Max: m.Pos.Add(m.Size)}
+		for t := 0; t < 2; t++ { // 2px border
+			for x := r.Min.X; x < r.Max.X; x++ {
+				out.Set(x, r.Min.Y+t, red)
+				out.Set(x, r.Max.Y-1-t, red)
+			}
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				out.Set(r.Min.X+t, y, red)
+				out.Set(r.Max.X-1-t, y, red)
+			}
+		}
+	}
+	return out
+}
+
+// showDetectionPreview shows the frame with match overlays and a list of matches.
+// A selected match can be test-clicked through the same click path the bot uses,
+// with displayID's offset applied; dry-run is on by default so nothing is clicked by accident.
+func showDetectionPreview(title string, frame image.Image, matches []previewMatch, displayID int) {
+	w := fyne.CurrentApp().NewWindow(title)
+	w.Resize(fyne.NewSize(900, 600))
+
+	raster := canvas.NewImageFromImage(drawMatches(frame, matches))
+	raster.FillMode = canvas.ImageFillContain
+	raster.ScaleMode = canvas.ImageScalePixels
+
+	matchList := widget.NewList(
+		func() int { return len(matches) },
+		func() fyne.CanvasObject { return widget.NewLabel("Match entry template") },
+		func(i widget.ListItemID, o fyne.CanvasObject) { o.(*widget.Label).SetText(matches[i].String()) },
+	)
+
+	// Simulate click
+	clickLbl := widget.NewLabel("")
+	clickLbl.Wrapping = fyne.TextWrapWord
+	dryRunCheck := widget.NewCheck("Dry Run", nil)
+	dryRunCheck.SetChecked(true)
+	doubleCheck := widget.NewCheck("Double Click", nil)
+
+	selected := -1
+	clickBtn := widget.NewButton("模拟点击 (Simulate Click)", func() {
+		if selected < 0 || selected >= len(matches) {
+			return
+		}
+		m := matches[selected]
+		offX, offY, _, _ := robotgo.GetDisplayBounds(displayID)
+		var lines []string // The clicker's log (e.g. the dry-run line), shown with the result
+		clicker := &input.Clicker{
+			Actions: input.Robot{},
+			OffsetX: offX,
+			OffsetY: offY,
+			DryRun:  dryRunCheck.Checked,
+			Double:  doubleCheck.Checked,
+			LogFunc: func(msg string) { lines = append(lines, msg) },
+		}
+		global := clicker.Click(m.Template, m.Pos.X, m.Pos.Y, m.Size.X, m.Size.Y)
+		msg := fmt.Sprintf("%s -> Global (%d, %d) [Display %d offset (%d, %d)]", m, global.X, global.Y, displayID, offX, offY)
+		clickLbl.SetText(strings.Join(append(lines, msg), "\n"))
+	})
+	clickBtn.Disable()
+
+	matchList.OnSelected = func(id widget.ListItemID) {
+		selected = id
+		clickBtn.Enable()
+	}
+
+	summary := widget.NewLabel(fmt.Sprintf("共 %d 个匹配 (%d matches)", len(matches), len(matches)))
+	clickControls := container.NewVBox(
+		widget.NewSeparator(),
+		container.NewHBox(dryRunCheck, doubleCheck),
+		clickBtn,
+		clickLbl,
+	)
+	side := container.NewBorder(summary, clickControls, nil, nil, matchList)
+
+	split := container.NewHSplit(raster, side)
+	split.Offset = 0.7
+	w.SetContent(split)
+	w.Show()
+}
+
+// showReferenceFrameCheck runs a template directory against a saved frame instead of
+// the live screen, so template changes can be checked against known-good frames
+func showReferenceFrameCheck(win fyne.Window, displayID int) {
+	var framePath, templateDir string
+
+	frameLbl := widget.NewLabel("-")
+	dirLbl := widget.NewLabel("-")
+
+	pickFrame := widget.NewButton("参考帧 (Frame)", func() {
+		d := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+			if err != nil {
+				modal.ShowError(err, win)
+				return
+			}
+			if reader == nil {
+				return
+			}
+			reader.Close()
+			framePath = reader.URI().Path()
+			frameLbl.SetText(filepath.Base(framePath))
+		}, win)
+		d.SetFilter(storage.NewExtensionFileFilter([]string{".png"}))
+		modal.Show(d)
+	})
+
+	pickDir := widget.NewButton("模板目录 (Templates)", func() {
+		modal.ShowFolderOpen(func(dir fyne.ListableURI, err error) {
+			if err != nil {
+				modal.ShowError(err, win)
+				return
+			}
+			if dir == nil {
+				return
+			}
+			templateDir = dir.Path()
+			dirLbl.SetText(templateDir)
+		}, win)
+	})
+
+	content := container.NewVBox(
+		container.NewBorder(nil, nil, pickFrame, nil, frameLbl),
+		container.NewBorder(nil, nil, pickDir, nil, dirLbl),
+	)
+
+	modal.ShowCustomConfirm("参考帧检测 (Check Reference Frame)", "检测", "取消", content, func(confirm bool) {
+		if !confirm {
+			return
+		}
+		if framePath == "" || templateDir == "" {
+			modal.ShowError(fmt.Errorf("请选择参考帧和模板目录"), win)
+			return
+		}
+
+		searcher := screen.NewSearcher()
+		frame, err := searcher.LoadImage(framePath)
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		// Full-frame matching can take a while; keep the UI responsive
+		go func() {
+			matches, err := detectInFrame(searcher, frame, templateDir, constants.DefaultTolerance)
+			fyne.Do(func() {
+				if err != nil {
+					modal.ShowError(err, win)
+					return
+				}
+				showDetectionPreview(fmt.Sprintf("检测预览 - %s", filepath.Base(framePath)), frame, matches, displayID)
+			})
+		}()
+	}, win)
+}
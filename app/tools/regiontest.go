@@ -0,0 +1,114 @@
+package tools
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ConserveLee/gui-idle/app/modal"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+	"github.com/kbinani/screenshot"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showRegionTest picks a template, captures the screen and reports the template's
+// best score inside a region drawn with the cropper: the smallest tolerance it
+// would match at (see Searcher.MatchDistance) and whether tolerance passes it.
+// Confirms fixed-position elements more precisely than a full-screen preview.
+func showRegionTest(win fyne.Window, displayID int, tolerance float64) {
+	d := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		if reader == nil {
+			return
+		}
+		tplPath := reader.URI().Path()
+		reader.Close()
+
+		searcher := screen.NewSearcher()
+		tpl, err := searcher.LoadImage(tplPath)
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		frame, err := screenshot.CaptureRect(screenshot.GetDisplayBounds(displayID))
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		showRegionTestWindow(searcher, frame, tpl, tplPath, tolerance)
+	}, win)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".png"}))
+	modal.Show(d)
+}
+
+func showRegionTestWindow(searcher *screen.Searcher, frame, tpl image.Image, tplPath string, tolerance float64) {
+	w := fyne.CurrentApp().NewWindow(fmt.Sprintf("区域测试 (Region Test): %s", filepath.Base(tplPath)))
+	w.Resize(fyne.NewSize(800, 600))
+
+	lbl := widget.NewLabel(fmt.Sprintf("请框选区域 (Draw a region, at least %dx%d)", tpl.Bounds().Dx(), tpl.Bounds().Dy()))
+	lbl.Alignment = fyne.TextAlignCenter
+
+	toleranceEntry := widget.NewEntry()
+	toleranceEntry.SetText(strconv.FormatFloat(tolerance, 'f', -1, 64))
+
+	var region image.Rectangle
+	gen := 0 // Bumped per test, so a slow result never overwrites a newer one
+	test := func() {
+		if region.Empty() {
+			return
+		}
+		tol, err := strconv.ParseFloat(toleranceEntry.Text, 64)
+		if err != nil || tol <= 0 {
+			lbl.SetText(fmt.Sprintf("无效容差 (Invalid tolerance) %q", toleranceEntry.Text))
+			return
+		}
+		if region.Dx() < tpl.Bounds().Dx() || region.Dy() < tpl.Bounds().Dy() {
+			lbl.SetText(fmt.Sprintf("区域 %v 小于模板 (smaller than the template)", region))
+			return
+		}
+
+		gen++
+		current, roi := gen, region
+		lbl.SetText("计算中... (Computing)")
+		go func() {
+			distance, at := searcher.MatchDistanceInROI(frame, tpl, roi)
+			text := fmt.Sprintf("区域 %v: 无法匹配 (no position can match)", roi)
+			if !math.IsInf(distance, 1) {
+				verdict := "通过 (PASS)"
+				if distance > tol {
+					verdict = "未通过 (FAIL)"
+				}
+				text = fmt.Sprintf("最佳分数 (Best score): %.1f at %v - %s at tolerance %g", distance, at, verdict, tol)
+			}
+			fyne.Do(func() {
+				if current == gen {
+					lbl.SetText(text)
+				}
+			})
+		}()
+	}
+	toleranceEntry.OnSubmitted = func(string) { test() }
+
+	cropper := NewCropperWidget(frame, func(rect image.Rectangle) {
+		region = rect
+		test()
+	})
+
+	w.SetContent(container.NewBorder(
+		nil,
+		container.NewVBox(lbl, container.NewBorder(nil, nil, widget.NewLabel("Tolerance:"), nil, toleranceEntry)),
+		nil, nil,
+		cropper,
+	))
+	w.Show()
+}
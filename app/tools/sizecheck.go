@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+)
+
+// TemplateReport is the result of benchmarking a candidate template on a screen
+type TemplateReport struct {
+	Width, Height int
+	Opaque        int           // Pixels that take part in matching (alpha > 0)
+	ScanTime      time.Duration // Average full-screen FindAllTemplates time
+	Matches       int           // Matches on the benchmark screen
+}
+
+// Oversized reports whether the template scans too slowly to be used every tick
+func (r TemplateReport) Oversized() bool {
+	return r.ScanTime > constants.TemplateMaxScanTime
+}
+
+// Risky reports whether the template has too few opaque pixels to match reliably
+func (r TemplateReport) Risky() bool {
+	return r.Opaque < constants.TemplateMinOpaquePixels
+}
+
+// String formats the report for the save dialog
+func (r TemplateReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "尺寸 %dx%d, 有效像素 %d, 全屏扫描 %v, 匹配 %d 处",
+		r.Width, r.Height, r.Opaque, r.ScanTime.Round(time.Microsecond), r.Matches)
+
+	if r.Oversized() {
+		fmt.Fprintf(&sb, "\n⚠ 扫描过慢 (> %v), 建议裁得更紧", constants.TemplateMaxScanTime)
+	}
+	if r.Risky() {
+		fmt.Fprintf(&sb, "\n⚠ 有效像素过少 (< %d), 容易误识别", constants.TemplateMinOpaquePixels)
+	}
+	if r.Matches == 0 {
+		sb.WriteString("\n⚠ 在当前画面上未匹配到")
+	} else if r.Matches > 1 {
+		sb.WriteString("\n⚠ 匹配到多处, 可能无法区分目标")
+	}
+	return sb.String()
+}
+
+// BenchmarkTemplate times full-screen matching of tpl on screenImg and counts its opaque pixels
+func BenchmarkTemplate(screenImg, tpl image.Image, tolerance float64) TemplateReport {
+	b := tpl.Bounds()
+	report := TemplateReport{Width: b.Dx(), Height: b.Dy()}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := tpl.At(x, y).RGBA(); a > 0 {
+				report.Opaque++
+			}
+		}
+	}
+
+	searcher := screen.NewSearcher()
+	start := time.Now()
+	for i := 0; i < constants.TemplateBenchmarkRuns; i++ {
+		report.Matches = searcher.CountTemplate(screenImg, tpl, tolerance)
+	}
+	report.ScanTime = time.Since(start) / constants.TemplateBenchmarkRuns
+	return report
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ConserveLee/gui-idle/app/global"
+	"github.com/ConserveLee/gui-idle/app/modal"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// listTemplates returns every template PNG under root (enabled and disabled), sorted
+func listTemplates(root string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.EqualFold(filepath.Ext(path), ".png") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// showTemplateManager lists the templates under root with a checkbox each;
+// unchecking disables a template by renaming it (see global.DisabledPrefix)
+func showTemplateManager(root string) {
+	w := fyne.CurrentApp().NewWindow("模板管理 (Manage Templates)")
+	w.Resize(fyne.NewSize(500, 600))
+
+	rows := container.NewVBox()
+
+	var refresh func()
+	refresh = func() {
+		rows.RemoveAll()
+		files, err := listTemplates(root)
+		if err != nil {
+			modal.ShowError(err, w)
+		}
+		for _, file := range files {
+			file := file
+			rel, _ := filepath.Rel(root, file)
+			check := widget.NewCheck(filepath.ToSlash(rel), nil)
+			check.SetChecked(!global.IsDisabled(file))
+			check.OnChanged = func(enabled bool) {
+				if _, err := global.SetTemplateEnabled(file, enabled); err != nil {
+					modal.ShowError(err, w)
+				}
+				refresh() // Paths changed (or the rename failed and the check must revert)
+			}
+			rows.Add(check)
+		}
+		rows.Refresh()
+	}
+	refresh()
+
+	hint := widget.NewLabel("取消勾选即禁用 (文件名加 \"" + global.DisabledPrefix + "\" 前缀), 下次启动时生效")
+	hint.Wrapping = fyne.TextWrapWord
+
+	w.SetContent(container.NewBorder(hint, nil, nil, nil, container.NewVScroll(rows)))
+	w.Show()
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+
+	"github.com/ConserveLee/gui-idle/app/modal"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+	"fyne.io/fyne/v2/widget"
+)
+
+// showToleranceTuner suggests a tolerance from a template, a screenshot where it
+// should match (positive) and one where it should not (negative)
+func showToleranceTuner(win fyne.Window) {
+	searcher := screen.NewSearcher()
+	paths := map[string]string{}
+
+	resultLabel := widget.NewLabel("")
+	resultLabel.Wrapping = fyne.TextWrapWord
+
+	// pick opens a PNG file picker and stores the chosen path under key
+	pick := func(key string, lbl *widget.Label) func() {
+		return func() {
+			d := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+				if err != nil {
+					modal.ShowError(err, win)
+					return
+				}
+				if reader == nil {
+					return
+				}
+				reader.Close()
+				paths[key] = reader.URI().Path()
+				lbl.SetText(filepath.Base(paths[key]))
+			}, win)
+			d.SetFilter(storage.NewExtensionFileFilter([]string{".png"}))
+			modal.Show(d)
+		}
+	}
+
+	templateLbl := widget.NewLabel("-")
+	positiveLbl := widget.NewLabel("-")
+	negativeLbl := widget.NewLabel("-")
+
+	computeBtn := widget.NewButton("计算 (Compute)", func() {
+		imgs := map[string]image.Image{}
+		for _, key := range []string{"template", "positive", "negative"} {
+			if paths[key] == "" {
+				modal.ShowError(fmt.Errorf("请选择 %s 图片", key), win)
+				return
+			}
+			img, err := searcher.LoadImage(paths[key])
+			if err != nil {
+				modal.ShowError(err, win)
+				return
+			}
+			imgs[key] = img
+		}
+
+		resultLabel.SetText("计算中... (Computing)")
+		go func() {
+			result, err := searcher.SuggestTolerance(imgs["template"], imgs["positive"], imgs["negative"])
+			text := ""
+			if err != nil {
+				text = fmt.Sprintf("无法推荐 (No suggestion): %v", err)
+			} else {
+				text = fmt.Sprintf("推荐容差 (Suggested): %.0f\n正样本 (Positive): %.1f at %v\n负样本 (Negative): %.1f at %v",
+					result.Suggested, result.PositiveDistance, result.PositiveAt, result.NegativeDistance, result.NegativeAt)
+			}
+			fyne.Do(func() { resultLabel.SetText(text) })
+		}()
+	})
+	computeBtn.Importance = widget.HighImportance
+
+	content := container.NewVBox(
+		container.NewBorder(nil, nil, widget.NewButton("模板 (Template)", pick("template", templateLbl)), nil, templateLbl),
+		container.NewBorder(nil, nil, widget.NewButton("应匹配 (Positive)", pick("positive", positiveLbl)), nil, positiveLbl),
+		container.NewBorder(nil, nil, widget.NewButton("不应匹配 (Negative)", pick("negative", negativeLbl)), nil, negativeLbl),
+		computeBtn,
+		resultLabel,
+	)
+
+	d := dialog.NewCustom("容差调优 (Tolerance Tuner)", "关闭", content, win)
+	d.Resize(fyne.NewSize(450, 350))
+	modal.Show(d)
+}
@@ -3,14 +3,20 @@ package tools
 import (
 	"fmt"
 	"image"
+	"image/color"
 	"image/png"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
-	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ConserveLee/gui-idle/app/global"
+	"github.com/ConserveLee/gui-idle/app/modal"
+	"github.com/ConserveLee/gui-idle/internal/config"
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
 	"github.com/kbinani/screenshot"
 
 	"fyne.io/fyne/v2"
@@ -20,8 +26,12 @@ import (
 	"fyne.io/fyne/v2/widget"
 )
 
-// NewToolsPanel creates the UI panel for utility tools
-func NewToolsPanel(win fyne.Window) fyne.CanvasObject {
+// NewToolsPanel creates the UI panel for utility tools.
+// assetsRoot returns the active profile's template root (it changes with the profile);
+// tolerance returns the configured color tolerance;
+// setScanRegion stores a feature's active scan region drawn in the cropper;
+// setCalibration stores the capture color correction (identity clears it).
+func NewToolsPanel(win fyne.Window, assetsRoot func() string, tolerance func() float64, setScanRegion func(feature string, r image.Rectangle), setCalibration func(screen.Normalization)) fyne.CanvasObject {
 	// State
 	selectedDisplay := 0
 	
@@ -61,17 +71,97 @@ func NewToolsPanel(win fyne.Window) fyne.CanvasObject {
 		bounds := screenshot.GetDisplayBounds(selectedDisplay)
 		img, err := screenshot.CaptureRect(bounds)
 		if err != nil {
-			dialog.ShowError(err, win)
+			modal.ShowError(err, win)
 			return
 		}
 
 		// 2. Open Cropper Window
-		showCropperWindow(win, img)
+		showCropperWindow(win, img, assetsRoot(), setScanRegion)
 	})
 	cropBtn.Importance = widget.HighImportance
 
+	// Save the raw screen without going through the cropper (bug reports, reference frames)
+	screenshotBtn := widget.NewButton("保存截图 (Save Screenshot)", func() {
+		bounds := screenshot.GetDisplayBounds(selectedDisplay)
+		img, err := screenshot.CaptureRect(bounds)
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		showScreenshotSaveDialog(win, img)
+	})
+
+	refFrameBtn := widget.NewButton("参考帧检测 (Check Saved Frame)", func() {
+		showReferenceFrameCheck(win, selectedDisplay)
+	})
+
+	// Best score of a template inside a drawn region (fixed-position elements)
+	regionTestBtn := widget.NewButton("区域匹配测试 (Test Template in Region)", func() {
+		showRegionTest(win, selectedDisplay, tolerance())
+	})
+
+	tunerBtn := widget.NewButton("容差调优 (Tune Tolerance)", func() {
+		showToleranceTuner(win)
+	})
+
+	// Record and replay fixed clicks and keys (no matching)
+	macroBtn := widget.NewButton("宏录制回放 (Record Macro)", func() {
+		showMacroWindow(selectedDisplay)
+	})
+
+	heatmapBtn := widget.NewButton("匹配热力图 (Match Heatmap)", func() {
+		showMatchHeatmap(win, selectedDisplay)
+	})
+	if !constants.DebugDump {
+		heatmapBtn.Hide()
+	}
+
+	// Capture color correction, fitted from a reference swatch
+	calibrateBtn := widget.NewButton("颜色校准 (Calibrate Colors)", func() {
+		showColorCalibration(win, selectedDisplay, setCalibration)
+	})
+	clearCalibrationBtn := widget.NewButton("清除校准", func() {
+		if setCalibration != nil {
+			setCalibration(screen.IdentityNormalization())
+		}
+	})
+
+	// Template bundles (share tuned template sets as a single zip)
+	exportBtn := widget.NewButton("导出素材包 (Export Bundle)", func() {
+		showExportBundleDialog(win, assetsRoot())
+	})
+	importBtn := widget.NewButton("导入素材包 (Import Bundle)", func() {
+		showImportBundleDialog(win, assetsRoot())
+	})
+
+	undoBtn := widget.NewButton("撤销上次保存 (Undo Last Save)", func() {
+		if len(lastSave) == 0 {
+			modal.ShowInformation("撤销", "没有可撤销的保存", win)
+			return
+		}
+		var paths []string
+		for _, s := range lastSave {
+			paths = append(paths, s.Path)
+		}
+		modal.ShowConfirm("撤销上次保存", fmt.Sprintf("删除以下文件?\n%s", strings.Join(paths, "\n")), func(confirm bool) {
+			if !confirm {
+				return
+			}
+			removed, err := undoLastSave()
+			if err != nil {
+				modal.ShowError(err, win)
+				return
+			}
+			modal.ShowInformation("撤销", fmt.Sprintf("已删除 %d 个文件", len(removed)), win)
+		}, win)
+	})
+
+	manageBtn := widget.NewButton("模板管理 (Manage Templates)", func() {
+		showTemplateManager(filepath.Join(assetsRoot(), "global_targets"))
+	})
+
 	openDirBtn := widget.NewButton("打开素材目录 (Open Assets)", func() {
-		openDir("assets")
+		openDir(assetsRoot())
 	})
 
 	// Layout
@@ -82,8 +172,18 @@ func NewToolsPanel(win fyne.Window) fyne.CanvasObject {
 		infoLabel,
 		layoutSpacer(),
 		cropBtn,
+		undoBtn,
+		screenshotBtn,
+		refFrameBtn,
+		regionTestBtn,
+		tunerBtn,
+		macroBtn,
+		heatmapBtn,
+		container.NewBorder(nil, nil, nil, clearCalibrationBtn, calibrateBtn),
 		layoutSpacer(),
 		widget.NewSeparator(),
+		container.NewGridWithColumns(2, exportBtn, importBtn),
+		manageBtn,
 	openDirBtn,
 	)
 
@@ -109,7 +209,109 @@ func openDir(path string) {
 	cmd.Run()
 }
 
-func showCropperWindow(parent fyne.Window, fullImg image.Image) {
+// showScreenshotSaveDialog asks where to save a full screenshot (timestamped default name)
+func showScreenshotSaveDialog(win fyne.Window, img image.Image) {
+	d := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		if writer == nil {
+			return // Cancelled
+		}
+		defer writer.Close()
+
+		if err := png.Encode(writer, img); err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+
+		path := writer.URI().Path()
+		fmt.Printf("Saved screenshot: %s\n", path)
+		modal.ShowInformation("成功", fmt.Sprintf("已保存截图: %s", path), win)
+	}, win)
+	d.SetFileName(fmt.Sprintf("screenshot_%s.png", time.Now().Format("20060102_150405")))
+	modal.Show(d)
+}
+
+func showExportBundleDialog(win fyne.Window, assetsDir string) {
+	d := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		if writer == nil {
+			return // Cancelled
+		}
+		defer writer.Close()
+
+		count, err := ExportBundle(assetsDir, writer)
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		modal.ShowInformation("成功", fmt.Sprintf("已导出 %d 个文件: %s", count, writer.URI().Path()), win)
+	}, win)
+	d.SetFileName(fmt.Sprintf("templates_%s.zip", time.Now().Format("20060102_150405")))
+	modal.Show(d)
+}
+
+func showImportBundleDialog(win fyne.Window, assetsDir string) {
+	d := dialog.NewFileOpen(func(reader fyne.URIReadCloser, err error) {
+		if err != nil {
+			modal.ShowError(err, win)
+			return
+		}
+		if reader == nil {
+			return // Cancelled
+		}
+		bundlePath := reader.URI().Path()
+		reader.Close()
+
+		policies := map[string]ConflictPolicy{
+			"跳过 (Skip)":      ConflictSkip,
+			"覆盖 (Overwrite)": ConflictOverwrite,
+			"重命名 (Rename)":   ConflictRename,
+		}
+		policySelect := widget.NewSelect([]string{"跳过 (Skip)", "覆盖 (Overwrite)", "重命名 (Rename)"}, nil)
+		policySelect.SetSelected("跳过 (Skip)")
+
+		content := container.NewVBox(
+			widget.NewLabel(filepath.Base(bundlePath)),
+			widget.NewLabel("文件已存在时 (On conflict):"),
+			policySelect,
+		)
+
+		modal.ShowCustomConfirm("导入素材包", "导入", "取消", content, func(confirm bool) {
+			if !confirm {
+				return
+			}
+
+			f, err := os.Open(bundlePath)
+			if err != nil {
+				modal.ShowError(err, win)
+				return
+			}
+			defer f.Close()
+			info, err := f.Stat()
+			if err != nil {
+				modal.ShowError(err, win)
+				return
+			}
+
+			result, err := ImportBundle(f, info.Size(), assetsDir, policies[policySelect.Selected])
+			if err != nil {
+				modal.ShowError(err, win)
+				return
+			}
+			modal.ShowInformation("成功", fmt.Sprintf("已导入 %d 个文件 (跳过 %d, 重命名 %d)",
+				len(result.Written), len(result.Skipped), result.Renamed), win)
+		}, win)
+	}, win)
+	modal.Show(d)
+}
+
+func showCropperWindow(parent fyne.Window, fullImg image.Image, assetsDir string, setScanRegion func(feature string, r image.Rectangle)) {
 	w := fyne.CurrentApp().NewWindow("裁切素材 (Crop Template)")
 	w.Resize(fyne.NewSize(800, 600))
 
@@ -124,10 +326,30 @@ func showCropperWindow(parent fyne.Window, fullImg image.Image) {
 	var currentSelection image.Rectangle
 
 	// Cropper Widget
+	// Active scan region: the selection can instead confine a feature's matching
+	regionSelect := widget.NewSelect(config.ScanRegionFeatures, nil)
+	regionSelect.SetSelected(config.ScanRegionFeatures[0])
+	regionBtn := widget.NewButton("设为扫描区域 (Set Scan Region)", func() {
+		if currentSelection.Empty() || setScanRegion == nil {
+			return
+		}
+		setScanRegion(regionSelect.Selected, currentSelection)
+		lbl.SetText(fmt.Sprintf("%s 扫描区域: %v", regionSelect.Selected, currentSelection))
+	})
+	regionBtn.Disable()
+	clearRegionBtn := widget.NewButton("清除", func() {
+		if setScanRegion == nil {
+			return
+		}
+		setScanRegion(regionSelect.Selected, image.Rectangle{})
+		lbl.SetText(fmt.Sprintf("已清除 %s 扫描区域", regionSelect.Selected))
+	})
+
 	cropper := NewCropperWidget(fullImg, func(rect image.Rectangle) {
 		currentSelection = rect
 		lbl.SetText(fmt.Sprintf("已选区: %v (点击保存)", rect))
 		saveBtn.Enable()
+		regionBtn.Enable()
 	})
 
 	saveBtn.OnTapped = func() {
@@ -141,19 +363,19 @@ func showCropperWindow(parent fyne.Window, fullImg image.Image) {
 		})
 		
 		if !ok {
-			dialog.ShowError(fmt.Errorf("image type does not support cropping"), w)
+			modal.ShowError(fmt.Errorf("image type does not support cropping"), w)
 			return
 		}
 		
 		finalImg := subImg.SubImage(currentSelection)
 		
 		// Show Save Dialog Logic
-		showSaveForm(w, finalImg)
+		showSaveForm(w, finalImg, fullImg, assetsDir)
 	}
 
 	content := container.NewBorder(
 		nil, 
-		container.NewVBox(lbl, saveBtn),
+		container.NewVBox(lbl, saveBtn, container.NewHBox(regionSelect, regionBtn, clearRegionBtn)),
 		nil, nil,
 		cropper,
 	)
@@ -162,29 +384,83 @@ func showCropperWindow(parent fyne.Window, fullImg image.Image) {
 	w.Show()
 }
 
-func showSaveForm(win fyne.Window, img image.Image) {
-	// Preview
-	imageObj := canvas.NewImageFromImage(img)
-	imageObj.FillMode = canvas.ImageFillContain
-	imageObj.SetMinSize(fyne.NewSize(100, 100))
+// showSaveForm asks where to save a cropped template.
+// screenImg is the frame it was cropped from, used to benchmark the crop;
+// assetsDir is the template root the feature directories are under.
+func showSaveForm(win fyne.Window, img, screenImg image.Image, assetsDir string) {
+	// Preview (tap a pixel to use its color as the chroma key)
+	chromaKey := ""
+
+	// Size check: benchmark the (keyed) template on the source frame
+	benchLabel := widget.NewLabel("")
+	benchLabel.Wrapping = fyne.TextWrapWord
+	benchRun := 0
+	runBenchmark := func() {
+		tpl := img
+		if key, err := global.ParseHexColor(chromaKey); chromaKey != "" && err == nil {
+			tpl = screen.ApplyChromaKey(img, key, constants.ChromaKeyTolerance)
+		}
+		benchRun++
+		run := benchRun
+		benchLabel.SetText("基准测试中 (Benchmarking)...")
+		go func() {
+			report := BenchmarkTemplate(screenImg, tpl, constants.DefaultTolerance)
+			fyne.Do(func() {
+				if run == benchRun { // Ignore results superseded by a newer chroma key
+					benchLabel.SetText(report.String())
+				}
+			})
+		}()
+	}
+	chromaSwatch := canvas.NewRectangle(color.Transparent)
+	chromaSwatch.SetMinSize(fyne.NewSize(20, 20))
+	chromaLabel := widget.NewLabel("抠像色: 无 (点击图片选取背景色)")
+	imageObj := NewColorPickImage(img, fyne.NewSize(100, 100), func(c color.Color) {
+		chromaKey = global.HexColor(c)
+		chromaSwatch.FillColor = c
+		chromaSwatch.Refresh()
+		chromaLabel.SetText(fmt.Sprintf("抠像色 (Chroma Key): %s", chromaKey))
+		runBenchmark()
+	})
+	clearChromaBtn := widget.NewButton("清除", func() {
+		chromaKey = ""
+		chromaSwatch.FillColor = color.Transparent
+		chromaSwatch.Refresh()
+		chromaLabel.SetText("抠像色: 无 (点击图片选取背景色)")
+		runBenchmark()
+	})
+	runBenchmark()
+
+	// Zoom and pixel grid, to check the crop pixel by pixel
+	previewScroll := container.NewScroll(container.NewCenter(imageObj))
+	previewScroll.SetMinSize(fyne.NewSize(300, 200))
+	zoomLevels := map[string]int{"适应 (Fit)": 0, "1x": 1, "2x": 2, "4x": 4, "8x": 8, "16x": 16}
+	zoomSelect := widget.NewSelect([]string{"适应 (Fit)", "1x", "2x", "4x", "8x", "16x"}, func(s string) {
+		imageObj.SetZoom(zoomLevels[s])
+		previewScroll.Refresh()
+	})
+	zoomSelect.SetSelected("适应 (Fit)")
+	gridCheck := widget.NewCheck("像素网格 (Pixel Grid)", imageObj.SetGrid)
 
 	// Form
 	// Mapping friendly names to paths
 	dirMap := map[string]string{
-		"找游戏 - 游戏入口 (Games)":     "assets/global_targets/find_game/games",
-		"找游戏 - 界面特征 (Finding)":   "assets/global_targets/find_game",
-		"等待中 - 大厅特征 (Lobby)":     "assets/global_targets/waiting",
-		"游戏中 - 技能图标 (Skill)":     "assets/global_targets/in_game",
-		"游戏中 - 退出按钮 (Exit)":      "assets/global_targets/in_game",
-		"频道选择 - 返回按钮 (Return)":   "assets/global_targets/channel",
-		"频道选择 - 打开列表 (Open)":     "assets/global_targets/channel",
-		"频道选择 - 选择频道 (Select)":   "assets/global_targets/channel",
-		"普通关卡":                     "assets/normal_targets",
+		"找游戏 - 游戏入口 (Games)":     filepath.Join(assetsDir, "global_targets/find_game/games"),
+		"找游戏 - 界面特征 (Finding)":   filepath.Join(assetsDir, "global_targets/find_game"),
+		"找游戏 - 否决模板 (Anti)":      filepath.Join(assetsDir, "global_targets/find_game/anti"),
+		"等待中 - 大厅特征 (Lobby)":     filepath.Join(assetsDir, "global_targets/waiting"),
+		"游戏中 - 技能图标 (Skill)":     filepath.Join(assetsDir, "global_targets/in_game"),
+		"游戏中 - 退出按钮 (Exit)":      filepath.Join(assetsDir, "global_targets/in_game"),
+		"频道选择 - 返回按钮 (Return)":   filepath.Join(assetsDir, "global_targets/channel"),
+		"频道选择 - 打开列表 (Open)":     filepath.Join(assetsDir, "global_targets/channel"),
+		"频道选择 - 选择频道 (Select)":   filepath.Join(assetsDir, "global_targets/channel"),
+		"普通关卡":                     filepath.Join(assetsDir, "normal_targets"),
 	}
 	// Sorted keys for consistent UI order
 	dirOptions := []string{
 		"找游戏 - 游戏入口 (Games)",
 		"找游戏 - 界面特征 (Finding)",
+		"找游戏 - 否决模板 (Anti)",
 		"等待中 - 大厅特征 (Lobby)",
 		"游戏中 - 技能图标 (Skill)",
 		"游戏中 - 退出按钮 (Exit)",
@@ -194,16 +470,15 @@ func showSaveForm(win fyne.Window, img image.Image) {
 		"普通关卡",
 	}
 	
-dirSelect := widget.NewSelect(dirOptions, nil)
-	
+	// Several features can share one crop: each checked directory gets its own copy
+	dirCheck := widget.NewCheckGroup(dirOptions, nil)
+
 	nameEntry := widget.NewEntry()
+	planLabel := widget.NewLabel("")
 
-	// Helper to update filename based on selection
-	updateName := func(friendlyName string) {
-		realDir, ok := dirMap[friendlyName]
-		if !ok {
-			return
-		}
+	// suggestName returns the default filename for a feature directory
+	suggestName := func(friendlyName string) string {
+		realDir := dirMap[friendlyName]
 		// Ensure dir exists
 		os.MkdirAll(realDir, 0755)
 
@@ -211,83 +486,179 @@ dirSelect := widget.NewSelect(dirOptions, nil)
 		switch friendlyName {
 		case "找游戏 - 游戏入口 (Games)":
 			// Games use high priority numbers (20, 19, 18...)
-			nextName := getNextFileName(realDir, true)
-			nameEntry.SetText(nextName)
+			return getNextFileName(realDir, true)
 		case "找游戏 - 界面特征 (Finding)":
-			nameEntry.SetText("finding.png")
+			return "finding.png"
+		case "找游戏 - 否决模板 (Anti)":
+			// No leading number: vetoes every entry (prefix "20_" to pair with 20.png only)
+			return "anti.png"
 		case "等待中 - 大厅特征 (Lobby)":
-			nameEntry.SetText("lobby.png")
+			return "lobby.png"
 		case "游戏中 - 技能图标 (Skill)":
-			nameEntry.SetText("skill.png")
+			return "skill.png"
 		case "游戏中 - 退出按钮 (Exit)":
-			nameEntry.SetText("exit.png")
+			return "exit.png"
 		case "频道选择 - 返回按钮 (Return)":
-			nameEntry.SetText("return.png")
+			return "return.png"
 		case "频道选择 - 打开列表 (Open)":
-			nameEntry.SetText("open.png")
+			return "open.png"
 		case "频道选择 - 选择频道 (Select)":
-			nameEntry.SetText("select.png")
+			return "select.png"
 		default:
-			nextName := getNextFileName(realDir, false)
-			nameEntry.SetText(nextName)
+			return getNextFileName(realDir, false)
 		}
 	}
 
-	dirSelect.OnChanged = func(s string) {
-		updateName(s)
+	// The filename is editable for a single directory; several use the suggestions
+	dirCheck.OnChanged = func(selected []string) {
+		switch len(selected) {
+		case 0:
+			nameEntry.SetText("")
+			nameEntry.Disable()
+			planLabel.SetText("")
+		case 1:
+			nameEntry.SetText(suggestName(selected[0]))
+			nameEntry.Enable()
+			planLabel.SetText("")
+		default:
+			nameEntry.SetText("")
+			nameEntry.Disable()
+			var plan []string
+			for _, friendlyName := range selected {
+				plan = append(plan, filepath.Join(dirMap[friendlyName], suggestName(friendlyName)))
+			}
+			planLabel.SetText(strings.Join(plan, "\n"))
+		}
 	}
-	
+
 	// Init default
-	dirSelect.SetSelected(dirOptions[0]) 
+	dirCheck.SetSelected([]string{dirOptions[0]})
 
 	content := container.NewVBox(
 		widget.NewLabel("确认保存此素材?"),
-		container.NewCenter(imageObj),
-		widget.NewLabel("保存至 (Target Feature):"),
-		dirSelect,
+		previewScroll,
+		container.NewHBox(widget.NewLabel("缩放:"), zoomSelect, gridCheck),
+		container.NewHBox(chromaSwatch, chromaLabel, clearChromaBtn),
+		benchLabel,
+		widget.NewLabel("保存至 (Target Features):"),
+		dirCheck,
 		widget.NewLabel("文件名 (Suggestion):"),
 		nameEntry,
+		planLabel,
 	)
 
-	dialog.ShowCustomConfirm("保存素材", "保存", "取消", content, func(confirm bool) {
+	modal.ShowCustomConfirm("保存素材", "保存", "取消", content, func(confirm bool) {
 		if !confirm {
 			return
 		}
-		
-		friendlyName := dirSelect.Selected
-		realDir := dirMap[friendlyName]
-		targetName := nameEntry.Text
-		
-		if targetName == "" {
-			dialog.ShowError(fmt.Errorf("文件名不能为空"), win)
+
+		selected := dirCheck.Selected
+		if len(selected) == 0 {
+			modal.ShowError(fmt.Errorf("请至少选择一个保存位置"), win)
 			return
 		}
-		
-		targetPath := filepath.Join(realDir, targetName)
-		
-		// Ensure directory exists before saving
-		if err := os.MkdirAll(realDir, 0755); err != nil {
-			dialog.ShowError(err, win)
+		if len(selected) == 1 && nameEntry.Text == "" {
+			modal.ShowError(fmt.Errorf("文件名不能为空"), win)
 			return
 		}
-		
-f, err := os.Create(targetPath)
-		if err != nil {
-			dialog.ShowError(err, win)
-			return
+
+		// Resolve every target path first so collisions can be confirmed in one go
+		targets := make([]string, len(selected))
+		var existing []string
+		for i, friendlyName := range selected {
+			targetName := nameEntry.Text
+			if len(selected) > 1 {
+				targetName = suggestName(friendlyName)
+			}
+			targets[i] = filepath.Join(dirMap[friendlyName], targetName)
+			if _, err := os.Stat(targets[i]); err == nil {
+				existing = append(existing, targets[i])
+			}
 		}
-		defer f.Close()
-		
-		if err := png.Encode(f, img); err != nil {
-			dialog.ShowError(err, win)
+
+		save := func() {
+			var saved []string
+			var written []savedTemplate
+			for i, friendlyName := range selected {
+				st, err := saveTemplate(targets[i], img, chromaKey, screenImg.Bounds().Size())
+				if err != nil {
+					lastSave = written // Keep what did get saved undoable
+					modal.ShowError(fmt.Errorf("%s: %w", targets[i], err), win)
+					return
+				}
+				written = append(written, st)
+				saved = append(saved, fmt.Sprintf("%s\n(%s)", targets[i], friendlyName))
+			}
+			lastSave = written
+
+			modal.ShowInformation("成功", fmt.Sprintf("已保存:\n%s", strings.Join(saved, "\n")), win)
+			win.Close()
+		}
+
+		if len(existing) == 0 {
+			save()
 			return
 		}
-		
-		dialog.ShowInformation("成功", fmt.Sprintf("已保存: %s\n(%s)", targetName, friendlyName), win)
-		win.Close() 
+
+		// Never clobber a known-good template silently
+		d := dialog.NewCustomWithoutButtons("文件已存在",
+			widget.NewLabel(fmt.Sprintf("以下文件已存在:\n%s", strings.Join(existing, "\n"))), win)
+		d.SetButtons([]fyne.CanvasObject{
+			widget.NewButton("取消", d.Hide),
+			widget.NewButton("改名保存 (Rename)", func() {
+				d.Hide()
+				for i := range targets {
+					if _, err := os.Stat(targets[i]); err == nil {
+						targets[i] = uniquePath(targets[i])
+					}
+				}
+				save()
+			}),
+			widget.NewButton("覆盖 (Overwrite)", func() {
+				d.Hide()
+				save()
+			}),
+		})
+		modal.Show(d)
 	}, win)
 }
 
+// saveTemplate writes img as a PNG template, plus the chroma key sidecar if set
+func saveTemplate(targetPath string, img image.Image, chromaKey string, source image.Point) (savedTemplate, error) {
+	saved := savedTemplate{Path: targetPath}
+
+	// Ensure directory exists before saving
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return saved, err
+	}
+
+	f, err := os.Create(targetPath)
+	if err != nil {
+		return saved, err
+	}
+	defer f.Close()
+
+	if err := png.Encode(f, img); err != nil {
+		return saved, err
+	}
+
+	// Chroma key and source resolution go into the template's sidecar (keeping any other settings).
+	// The resolution lets the bot warn when it runs on a display of a different size.
+	scPath := global.SidecarPath(targetPath)
+	if _, err := os.Stat(scPath); os.IsNotExist(err) {
+		saved.Sidecar = scPath // Created by this save, so undo removes it too
+	}
+	sc, _ := global.LoadSidecar(targetPath)
+	if chromaKey != "" {
+		sc.ChromaKey = chromaKey
+	}
+	sc.SourceResolution = global.FormatResolution(source)
+	if err := global.SaveSidecar(targetPath, sc); err != nil {
+		return saved, err
+	}
+	return saved, nil
+}
+
 // getNextFileName calculates the suggested filename
 func getNextFileName(dir string, decrement bool) string {
 	files, _ := filepath.Glob(filepath.Join(dir, "*.png"))
@@ -304,20 +675,10 @@ func getNextFileName(dir string, decrement bool) string {
 	foundNum := false
 	
 	for _, f := range files {
-		base := filepath.Base(f)
-		name := strings.TrimSuffix(base, filepath.Ext(base))
-		// Handle "20-11" -> parse "20"
-		parts := strings.FieldsFunc(name, func(r rune) bool {
-			return r < '0' || r > '9'
-		})
-		
-		if len(parts) > 0 {
-			if idx, err := strconv.Atoi(parts[0]); err == nil {
-				if idx > maxIdx {
-					maxIdx = idx
-					foundNum = true
-				}
-			}
+		// "20-11.png" and a disabled "_20.png" both take 20
+		if spec := global.ParseTargetName(f); spec.HasPriority && spec.Priority > maxIdx {
+			maxIdx = spec.Priority
+			foundNum = true
 		}
 	}
 	
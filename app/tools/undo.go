@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"os"
+)
+
+// savedTemplate is one file written by the save form
+type savedTemplate struct {
+	Path    string // Template PNG
+	Sidecar string // Sidecar created by the save (empty if none was created)
+}
+
+// lastSave holds the files written by the most recent save, for "Undo Last Save".
+// Only touched from the UI thread.
+var lastSave []savedTemplate
+
+// undoLastSave deletes the files written by the most recent save and clears the undo target.
+// Other templates are left alone, so entry numbering keeps its gap rather than shifting.
+// Returns the deleted template paths.
+func undoLastSave() ([]string, error) {
+	var removed []string
+	for _, s := range lastSave {
+		if err := os.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		if s.Sidecar != "" {
+			if err := os.Remove(s.Sidecar); err != nil && !os.IsNotExist(err) {
+				return removed, err
+			}
+		}
+		removed = append(removed, s.Path)
+	}
+	lastSave = nil
+	return removed, nil
+}
@@ -0,0 +1,116 @@
+// Package assets holds the default templates built into the binary (see
+// defaults/README.md) and lists templates from the on-disk assets directory with
+// the embedded set as a fallback, so the app also works without assets/.
+package assets
+
+import (
+	"embed"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed defaults
+var embedded embed.FS
+
+// disabledPrefix marks a disabled template (global.DisabledPrefix); a disabled
+// file on disk still overrides the embedded file it was renamed from
+const disabledPrefix = "_"
+
+// Defaults returns the embedded templates, laid out like the assets directory
+// (global_targets/find_game/games/20.png, ...)
+func Defaults() fs.FS {
+	sub, err := fs.Sub(embedded, "defaults")
+	if err != nil {
+		panic(err) // Cannot happen: the directory is embedded above
+	}
+	return sub
+}
+
+// File is a template file, on disk or in an embedded set
+type File struct {
+	Name string // Base name
+	Path string // Path on disk, or slash-separated path inside FS
+	FS   fs.FS  // Embedded set holding the file (nil = on disk)
+}
+
+// Embedded reports whether the file comes from an embedded set
+func (f File) Embedded() bool {
+	return f.FS != nil
+}
+
+// Open opens the file for reading
+func (f File) Open() (io.ReadCloser, error) {
+	if f.FS == nil {
+		return os.Open(f.Path)
+	}
+	return f.FS.Open(f.Path)
+}
+
+// ReadSibling reads the file at the same path with ext in place of the file's
+// extension (e.g. its ".json" sidecar)
+func (f File) ReadSibling(ext string) ([]byte, error) {
+	p := strings.TrimSuffix(f.Path, filepath.Ext(f.Path)) + ext
+	if f.FS == nil {
+		return os.ReadFile(p)
+	}
+	return fs.ReadFile(f.FS, p)
+}
+
+// Glob returns the files matching pattern (e.g. "*.png") in subDir (slash-separated)
+// of dir on disk, plus those of subDir in fallback that no on-disk file of the same
+// name overrides, sorted by name. A missing dir or a nil fallback is not an error.
+func Glob(dir string, fallback fs.FS, subDir, pattern string) ([]File, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, filepath.FromSlash(subDir), pattern))
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	onDisk := make(map[string]bool)
+	for _, p := range paths {
+		name := filepath.Base(p)
+		files = append(files, File{Name: name, Path: p})
+		onDisk[strings.TrimPrefix(name, disabledPrefix)] = true
+	}
+
+	if fallback != nil {
+		paths, err := fs.Glob(fallback, path.Join(subDir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range paths {
+			if name := path.Base(p); !onDisk[strings.TrimPrefix(name, disabledPrefix)] {
+				files = append(files, File{Name: name, Path: p, FS: fallback})
+			}
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// Find returns the file name in subDir of dir on disk, or from fallback if it is not
+// on disk (nor disabled there). The error wraps fs.ErrNotExist if neither has it.
+func Find(dir string, fallback fs.FS, subDir, name string) (File, error) {
+	p := filepath.Join(dir, filepath.FromSlash(subDir), name)
+	_, err := os.Stat(p)
+	if err == nil {
+		return File{Name: name, Path: p}, nil
+	}
+	if fallback == nil || !errors.Is(err, fs.ErrNotExist) {
+		return File{}, err
+	}
+	if _, disabled := os.Stat(filepath.Join(filepath.Dir(p), disabledPrefix+name)); disabled == nil {
+		return File{}, err
+	}
+	embedded := path.Join(subDir, name)
+	if _, err := fs.Stat(fallback, embedded); err != nil {
+		return File{}, err
+	}
+	return File{Name: name, Path: embedded, FS: fallback}, nil
+}
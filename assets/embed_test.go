@@ -0,0 +1,98 @@
+package assets
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGlobFallback(t *testing.T) {
+	fallback := fstest.MapFS{
+		"find_game/finding.png":   {Data: []byte("finding")},
+		"find_game/games/10.png":  {Data: []byte("embedded")},
+		"find_game/games/20.png":  {Data: []byte("embedded")},
+		"find_game/games/30.png":  {Data: []byte("embedded")},
+		"find_game/games/30.json": {Data: []byte("{}")},
+	}
+	embedded := func(files []File) (n int) {
+		for _, f := range files {
+			if f.Embedded() {
+				n++
+			}
+		}
+		return n
+	}
+
+	dir := t.TempDir()
+	absent, err := Glob(filepath.Join(dir, "missing"), fallback, "find_game/games", "*.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(absent) != 3 || embedded(absent) != 3 {
+		t.Errorf("without an assets directory: %d files, %d embedded; want 3, 3", len(absent), embedded(absent))
+	}
+
+	// 20.png and a disabled _30.png on disk override their embedded namesakes
+	games := filepath.Join(dir, "find_game", "games")
+	if err := os.MkdirAll(games, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"20.png", "_30.png"} {
+		if err := os.WriteFile(filepath.Join(games, name), []byte("disk"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	present, err := Glob(dir, fallback, "find_game/games", "*.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(present) != 3 || embedded(present) != 1 {
+		t.Errorf("with overrides on disk: %d files, %d embedded; want 3, 1", len(present), embedded(present))
+	}
+	var names []string
+	for _, f := range present {
+		names = append(names, f.Name)
+	}
+	if want := []string{"10.png", "20.png", "_30.png"}; !slices.Equal(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+}
+
+func TestFindFallback(t *testing.T) {
+	fallback := fstest.MapFS{"find_game/finding.png": {Data: []byte("finding")}}
+	dir := t.TempDir()
+
+	f, err := Find(dir, fallback, "find_game", "finding.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f.Embedded() {
+		t.Error("Find did not fall back to the embedded file")
+	}
+	r, err := f.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if data, _ := io.ReadAll(r); string(data) != "finding" {
+		t.Errorf("embedded file read %q", data)
+	}
+
+	if _, err := Find(dir, fallback, "find_game", "missing.png"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Find(missing) error = %v, want fs.ErrNotExist", err)
+	}
+	if _, err := Find(dir, nil, "find_game", "finding.png"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Find without a fallback error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestDefaultsReadable(t *testing.T) {
+	if _, err := fs.Stat(Defaults(), "README.md"); err != nil {
+		t.Errorf("embedded defaults: %v", err)
+	}
+}
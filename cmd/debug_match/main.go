@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 
 	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
 )
 
 func main() {
@@ -35,7 +36,7 @@ func main() {
 		// Test with new percentage-based matching
 		for _, tolerance := range []float64{60, 80} {
 			matches := findAllTemplatesNew(screenImg, tplImg, tolerance)
-			fmt.Printf("  Tolerance %.0f (%.0f%% fail allowed): %d matches", tolerance, constants.MaxFailRate*100, len(matches))
+			fmt.Printf("  Tolerance %.0f (%.0f%% similarity, %.0f%% fail allowed): %d matches", tolerance, screen.SimilarityFromTolerance(tolerance), constants.MaxFailRate*100, len(matches))
 			if len(matches) > 0 {
 				fmt.Printf(" -> %v", matches)
 			}
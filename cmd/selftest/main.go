@@ -0,0 +1,192 @@
+// Command selftest is an end-to-end accuracy check of the template matcher. It
+// generates random screens, places a random template at a known position, adds
+// per-pixel noise and a global brightness shift, and checks that FindAllTemplates
+// and MatchDistance (the best-match search) recover the position:
+//
+//	go run ./cmd/selftest -cases 200 -noise 10 -seed 7
+//
+// Every case is printed with PASS/FAIL. It exits non-zero if the pass rate falls
+// below -min-pass. Unlike the screen package tests, the cases are random but reproducible
+// from -seed. With -bench it instead times a loosely cropped template with and
+// without the opaque-bounds optimization.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/input"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+)
+
+// options are the generator settings and pass thresholds
+type options struct {
+	cases     int
+	width     int
+	height    int
+	noise     int     // Max per-channel noise added to every screen pixel (+/-)
+	shift     int     // Max global brightness shift of the screen (+/-)
+	tolerance float64 // Tolerance passed to FindAllTemplates
+	maxOffset int     // Max distance (px, per axis) between a found and the true position
+	minPass   float64 // Fraction of cases that must pass
+}
+
+func main() {
+	var opt options
+	flag.IntVar(&opt.cases, "cases", 100, "Number of generated cases")
+	flag.IntVar(&opt.width, "width", 320, "Generated screen width")
+	flag.IntVar(&opt.height, "height", 240, "Generated screen height")
+	flag.IntVar(&opt.noise, "noise", 8, "Max per-channel noise on the screen (+/-)")
+	flag.IntVar(&opt.shift, "shift", 6, "Max global brightness shift of the screen (+/-)")
+	flag.Float64Var(&opt.tolerance, "tolerance", constants.DefaultTolerance, "Match tolerance")
+	flag.IntVar(&opt.maxOffset, "max-offset", 0, "Allowed position error in pixels (per axis)")
+	flag.Float64Var(&opt.minPass, "min-pass", 1.0, "Minimum pass rate (0-1)")
+	seed := flag.Int64("seed", 1, "Generator seed (0 = random)")
+	bench := flag.Bool("bench", false, "Time opaque-bounds matching instead of running cases")
+	flag.Parse()
+
+	rng, used := input.NewRand(*seed)
+	if *bench {
+		benchOpaqueBounds(rng, opt)
+		return
+	}
+	fmt.Printf("seed %d, %d cases, %dx%d screens, noise +/-%d, shift +/-%d, tolerance %.1f, max offset %dpx\n\n",
+		used, opt.cases, opt.width, opt.height, opt.noise, opt.shift, opt.tolerance, opt.maxOffset)
+
+	passed := 0
+	for i := 0; i < opt.cases; i++ {
+		if err := runCase(rng, opt); err != nil {
+			fmt.Printf("FAIL  case %d: %v\n", i+1, err)
+			continue
+		}
+		passed++
+		fmt.Printf("PASS  case %d\n", i+1)
+	}
+
+	rate := float64(passed) / float64(opt.cases)
+	fmt.Printf("\n%d/%d cases passed (%.1f%%, need %.1f%%)\n", passed, opt.cases, rate*100, opt.minPass*100)
+	if rate < opt.minPass {
+		os.Exit(1)
+	}
+}
+
+// runCase generates one screen with one template and checks both searches
+func runCase(rng *rand.Rand, opt options) error {
+	tw, th := 12+rng.Intn(29), 12+rng.Intn(29) // 12-40px
+	if tw > opt.width || th > opt.height {
+		return fmt.Errorf("screen %dx%d is smaller than the template %dx%d", opt.width, opt.height, tw, th)
+	}
+	tpl := randomBlocks(rng, tw, th, 4)
+	scr := randomBlocks(rng, opt.width, opt.height, 8)
+	at := image.Point{X: rng.Intn(opt.width - tw + 1), Y: rng.Intn(opt.height - th + 1)}
+	paste(scr, tpl, at)
+	distort(rng, scr, opt.noise, opt.shift)
+
+	s := screen.NewSearcher()
+	matches := s.FindAllTemplates(scr, tpl, opt.tolerance)
+	if len(matches) != 1 {
+		return fmt.Errorf("%dx%d at %v: FindAllTemplates found %d matches %v, want 1", tw, th, at, len(matches), matches)
+	}
+	if !near(matches[0], at, opt.maxOffset) {
+		return fmt.Errorf("%dx%d at %v: FindAllTemplates found %v", tw, th, at, matches[0])
+	}
+	dist, best := s.MatchDistance(scr, tpl)
+	if !near(best, at, opt.maxOffset) {
+		return fmt.Errorf("%dx%d at %v: best match %v (distance %.1f)", tw, th, at, best, dist)
+	}
+	return nil
+}
+
+// benchOpaqueBounds times full-screen scans of a template with a wide transparent
+// margin, walking only its opaque box vs the whole template. Both must agree.
+func benchOpaqueBounds(rng *rand.Rand, opt options) {
+	const runs = 5
+	core := randomBlocks(rng, 24, 24, 4)
+	loose := image.NewRGBA(image.Rect(0, 0, 72, 64)) // Transparent margin around the core
+	paste(loose, core, image.Point{X: 24, Y: 20})
+
+	// Tiles of the core make the full check run often, not just the probes
+	scr := randomBlocks(rng, opt.width, opt.height, 8)
+	for y := 0; y+24 <= opt.height; y += 48 {
+		for x := 0; x+24 <= opt.width; x += 48 {
+			paste(scr, core, image.Point{X: x, Y: y})
+		}
+	}
+	distort(rng, scr, opt.noise, opt.shift)
+
+	s := screen.NewSearcher()
+	timeScans := func(full bool) (time.Duration, []image.Point) {
+		s.SetFullTemplateMatch(full)
+		var matches []image.Point
+		start := time.Now()
+		for i := 0; i < runs; i++ {
+			matches = s.FindAllTemplates(scr, loose, opt.tolerance)
+		}
+		return time.Since(start) / runs, matches
+	}
+	full, want := timeScans(true)
+	trimmed, got := timeScans(false)
+
+	fmt.Printf("template %v, opaque %v, screen %dx%d, %d matches\n", loose.Bounds().Size(), screen.OpaqueBounds(loose), opt.width, opt.height, len(got))
+	fmt.Printf("full template:  %v/scan\n", full)
+	fmt.Printf("opaque bounds:  %v/scan (%.1fx)\n", trimmed, float64(full)/float64(trimmed))
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		fmt.Printf("FAIL  results differ: %v vs %v\n", got, want)
+		os.Exit(1)
+	}
+}
+
+// randomBlocks returns a w x h image of random opaque cell x cell color blocks,
+// so templates have structure and screens have texture to reject against
+func randomBlocks(rng *rand.Rand, w, h, cell int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for cy := 0; cy < h; cy += cell {
+		for cx := 0; cx < w; cx += cell {
+			c := color.RGBA{uint8(rng.Intn(256)), uint8(rng.Intn(256)), uint8(rng.Intn(256)), 255}
+			for y := cy; y < cy+cell && y < h; y++ {
+				for x := cx; x < cx+cell && x < w; x++ {
+					img.SetRGBA(x, y, c)
+				}
+			}
+		}
+	}
+	return img
+}
+
+// distort adds per-pixel noise in [-noise, noise] and one global shift in
+// [-shift, shift] to every channel of img
+func distort(rng *rand.Rand, img *image.RGBA, noise, shift int) {
+	offset := 0
+	if shift > 0 {
+		offset = rng.Intn(2*shift+1) - shift
+	}
+	for i := 0; i < len(img.Pix); i += 4 {
+		for c := 0; c < 3; c++ {
+			v := int(img.Pix[i+c]) + offset
+			if noise > 0 {
+				v += rng.Intn(2*noise+1) - noise
+			}
+			img.Pix[i+c] = uint8(max(0, min(255, v)))
+		}
+	}
+}
+
+func paste(dst *image.RGBA, src *image.RGBA, at image.Point) {
+	b := src.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dst.SetRGBA(at.X+x-b.Min.X, at.Y+y-b.Min.Y, src.RGBAAt(x, y))
+		}
+	}
+}
+
+func near(p, q image.Point, maxOffset int) bool {
+	dx, dy := p.X-q.X, p.Y-q.Y
+	return dx >= -maxOffset && dx <= maxOffset && dy >= -maxOffset && dy <= maxOffset
+}
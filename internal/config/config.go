@@ -0,0 +1,515 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the config file used when none is given on the command line
+const DefaultPath = "config.json"
+
+// MaxTolerance is the largest possible Euclidean RGB distance (sqrt(3*255^2))
+var MaxTolerance = screen.MaxColorDistance
+
+// Duration wraps time.Duration so config files can use strings like "150ms" or "30s"
+type Duration time.Duration
+
+// D returns the value as a time.Duration
+func (d Duration) D() time.Duration {
+	return time.Duration(d)
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// Also accept plain numbers (nanoseconds)
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid duration %s", string(data))
+		}
+		*d = Duration(n)
+		return nil
+	}
+	return d.parse(s)
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	return d.parse(value.Value)
+}
+
+func (d *Duration) parse(s string) error {
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// ParseSeconds parses a positive number of seconds as typed in the UI (e.g. "50", "2.5")
+func ParseSeconds(s string) (Duration, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || !(v > 0) || v > math.MaxInt64/float64(time.Second) {
+		return 0, fmt.Errorf("invalid number of seconds %q", s)
+	}
+	return Duration(v * float64(time.Second)), nil
+}
+
+// FormatSeconds formats d as a number of seconds, the form ParseSeconds reads
+func FormatSeconds(d Duration) string {
+	return strconv.FormatFloat(d.D().Seconds(), 'f', -1, 64)
+}
+
+// Config holds the declarative settings for the global bot.
+// It is shared by the GUI and CLI so both stay in sync.
+type Config struct {
+	AssetsDir string  `json:"assets_dir" yaml:"assets_dir"` // Root of the global expedition targets
+	Display   int     `json:"display" yaml:"display"`       // Display index to capture
+	Tolerance float64 `json:"tolerance" yaml:"tolerance"`   // Color tolerance for pixel comparison
+	MatchMode string  `json:"match_mode" yaml:"match_mode"` // Pixel comparison mode: "color", "binary" or "edges"
+
+	// Similarity: the tolerance as a percentage (0-100, see screen.ToleranceFromSimilarity).
+	// When set it takes precedence: Tolerance is derived from it on load. 0 = unset.
+	Similarity float64 `json:"similarity,omitempty" yaml:"similarity,omitempty"`
+
+	// Stable display id (see screen.DisplayID); wins over Display when it resolves,
+	// so the same monitor stays selected when indices shuffle after a replug
+	DisplayID string `json:"display_id,omitempty" yaml:"display_id,omitempty"`
+
+	// Scan Intervals
+	EntryScanInterval  Duration `json:"entry_scan_interval" yaml:"entry_scan_interval"`
+	InGameScanInterval Duration `json:"in_game_scan_interval" yaml:"in_game_scan_interval"`
+	SearchScanInterval Duration `json:"search_scan_interval" yaml:"search_scan_interval"`
+
+	// Lobby Wait
+	LobbyPollInterval Duration `json:"lobby_poll_interval" yaml:"lobby_poll_interval"`
+	LobbyTimeout      Duration `json:"lobby_timeout" yaml:"lobby_timeout"`
+
+	// Post-Click Waits: settle time after a click before the next capture
+	EntryClickWait  Duration `json:"entry_click_wait" yaml:"entry_click_wait"`   // After an entry click, before verification
+	ExitClickWait   Duration `json:"exit_click_wait" yaml:"exit_click_wait"`     // After the exit button
+	SearchClickWait Duration `json:"search_click_wait" yaml:"search_click_wait"` // After return/open/select in the channel search
+
+	// Auto-Detect
+	AutoDetectMinMatches int `json:"auto_detect_min_matches" yaml:"auto_detect_min_matches"` // Templates that must match before a transition
+
+	// Entry Order
+	EntrySortOrder string `json:"entry_sort_order" yaml:"entry_sort_order"` // "highest_first" or "lowest_first"
+
+	// Entry ROI (fast path around the last clicked entry)
+	EntryROIMargin   int `json:"entry_roi_margin" yaml:"entry_roi_margin"`       // Margin (px) left, right and below
+	EntryROIMarginUp int `json:"entry_roi_margin_up" yaml:"entry_roi_margin_up"` // Margin (px) above, for lists scrolling up
+
+	// Incremental Scan: the full entry scan rescans only blocks that changed since the
+	// previous scan and keeps earlier matches elsewhere (for mostly static screens)
+	IncrementalScan bool `json:"incremental_scan" yaml:"incremental_scan"`
+
+	// Center-Out Scan: a full-screen re-find of a fixed or search-step button that was
+	// found before scans outward from its last position and stops at the first match,
+	// instead of scanning the whole screen top to bottom (see Searcher.FindFirstNear)
+	CenterOutScan bool `json:"center_out_scan" yaml:"center_out_scan"`
+
+	// Largest-First Scan: checks that stop at the first template found (auto-detect,
+	// "is any of these on screen") try templates by pixel area, largest first, instead
+	// of in click-priority order (see screen.LargestFirst). Which entry is clicked still
+	// follows click priority.
+	LargestFirstScan bool `json:"largest_first_scan" yaml:"largest_first_scan"`
+
+	// Entry Tracker: entities tracked longer than this are dropped and re-detected fresh,
+	// even if still seen, so drifted positions and click counts are not acted on (0 = never)
+	EntityMaxAge Duration `json:"entity_max_age" yaml:"entity_max_age"`
+
+	// Entry Blacklist: what happens when an entry is clicked the max times without
+	// leaving the entry screen: "skip", "scroll" (the entry list), "notify" or "stop"
+	EntryBlacklistAction string `json:"entry_blacklist_action" yaml:"entry_blacklist_action"`
+
+	// Entry Scroll: bring off-screen entries into view after consecutive empty scans
+	EntryScrollAfter     int    `json:"entry_scroll_after" yaml:"entry_scroll_after"`                       // Empty entry scans before scrolling (0 = never)
+	EntryScrollDirection string `json:"entry_scroll_direction" yaml:"entry_scroll_direction"`               // "down" or "up"
+	EntryScrollLines     int    `json:"entry_scroll_lines" yaml:"entry_scroll_lines"`                       // Mouse wheel steps per scroll
+	EntryScrollRegion    Region `json:"entry_scroll_region,omitempty" yaml:"entry_scroll_region,omitempty"` // Scroll at its center (default: entry scan region, else the display center)
+
+	// Capture Failures
+	CaptureFailThreshold int      `json:"capture_fail_threshold" yaml:"capture_fail_threshold"`   // Consecutive failures before alerting
+	StopOnCaptureFailure bool     `json:"stop_on_capture_failure" yaml:"stop_on_capture_failure"` // Auto-stop when the threshold is hit
+	CaptureRetries       int      `json:"capture_retries" yaml:"capture_retries"`                 // Retries of a failed capture before it counts as a failure
+	CaptureRetryBackoff  Duration `json:"capture_retry_backoff" yaml:"capture_retry_backoff"`     // Wait before the first retry (doubles per retry)
+
+	// Frozen Screen: the screen has not changed and no expected template was found for
+	// FreezeTimeout (the game crashed or hung), so acting on it would click a stale picture.
+	// FreezeAction: "pause" (until the screen changes), "stop" or "recover" (press
+	// FreezeRecoveryKey and/or click FreezeRecoveryClick; pauses once the attempts run out)
+	FreezeTimeout       Duration `json:"freeze_timeout" yaml:"freeze_timeout"`                                   // 0 = off
+	FreezeAction        string   `json:"freeze_action" yaml:"freeze_action"`                                     // "pause", "stop" or "recover"
+	FreezeNotify        bool     `json:"freeze_notify" yaml:"freeze_notify"`                                     // Also send a desktop notification
+	FreezeRecoveryKey   string   `json:"freeze_recovery_key,omitempty" yaml:"freeze_recovery_key,omitempty"`     // Key to press (e.g. "esc")
+	FreezeRecoveryClick *Point   `json:"freeze_recovery_click,omitempty" yaml:"freeze_recovery_click,omitempty"` // Point to click (display coordinates)
+
+	// State Dwell: minimum time in a state before a transition out is allowed, by state
+	// name (e.g. "AutoDetect": "500ms"); held-back transitions run once it has passed
+	StateDwell map[string]Duration `json:"state_dwell,omitempty" yaml:"state_dwell,omitempty"`
+
+	// Search Verify Failures: the search flow ran but the highlight was never verified
+	VerifyFailThreshold int  `json:"verify_fail_threshold" yaml:"verify_fail_threshold"`   // Consecutive failed search cycles before alerting (0 = never)
+	StopOnVerifyFailure bool `json:"stop_on_verify_failure" yaml:"stop_on_verify_failure"` // Auto-stop when the threshold is hit (otherwise keep falling back to AutoDetect)
+
+	// Active Scan Regions: confine matching for a feature to one rectangle (display coordinates)
+	ScanRegions map[string]Region `json:"scan_regions,omitempty" yaml:"scan_regions,omitempty"`
+
+	// Color Calibration: correction applied to captured frames before matching (nil = none)
+	ColorCalibration *ColorCalibration `json:"color_calibration,omitempty" yaml:"color_calibration,omitempty"`
+
+	// Per-Feature Tolerances: override Tolerance for one flow (unset features use Tolerance)
+	FeatureTolerances map[string]float64 `json:"feature_tolerances,omitempty" yaml:"feature_tolerances,omitempty"`
+
+	// Entry Tolerance Tiers: looser tolerances (ascending) tried per template only when the
+	// entry tolerance finds nothing, to still catch faded buttons
+	EntryToleranceTiers []float64 `json:"entry_tolerance_tiers,omitempty" yaml:"entry_tolerance_tiers,omitempty"`
+
+	DryRun            bool     `json:"dry_run" yaml:"dry_run"`                         // Detect and log, but never click
+	Debug             bool     `json:"debug" yaml:"debug"`                             // Show debug aids (e.g. the ROI overlay)
+	VerboseDetections bool     `json:"verbose_detections" yaml:"verbose_detections"`   // Log every entry detection of each scan, not just clicks
+	ConfirmFirstClick bool     `json:"confirm_first_click" yaml:"confirm_first_click"` // Ask before the first real click of each run
+	MaxRuntime        Duration `json:"max_runtime" yaml:"max_runtime"`                 // Stop automatically after this long (0 = unlimited)
+	WarmUp            Duration `json:"warm_up" yaml:"warm_up"`                         // Delay before the first scan of a run (time to switch to the game)
+	AlwaysOnTop       bool     `json:"always_on_top" yaml:"always_on_top"`             // Keep the control window above other windows
+	ClickMarker       bool     `json:"click_marker" yaml:"click_marker"`               // Flash a marker where each click lands
+
+	// Power Saver: scan less often (e.g. on battery)
+	PowerSaver       bool    `json:"power_saver" yaml:"power_saver"`               // Multiply every scan interval by PowerSaverFactor
+	PowerSaverFactor float64 `json:"power_saver_factor" yaml:"power_saver_factor"` // Interval multiplier while power saving (>= 1)
+
+	// Seed for every randomized behavior (click offsets, timing jitter); reuse a logged
+	// seed to reproduce a run (0 = new seed each run)
+	Seed int64 `json:"seed" yaml:"seed"`
+}
+
+// ScanRegionFeatures lists the features that accept an active scan region
+// ("verify" = the lobby/game/exit checks after an entry click)
+var ScanRegionFeatures = []string{"entry", "exit", "channel", "verify"}
+
+// ToleranceFeatures lists the features that accept their own tolerance
+var ToleranceFeatures = []string{"entry", "exit", "search_open", "search_select", "search_verify"}
+
+// Region is a rectangle in display coordinates
+type Region struct {
+	X int `json:"x" yaml:"x"`
+	Y int `json:"y" yaml:"y"`
+	W int `json:"w" yaml:"w"`
+	H int `json:"h" yaml:"h"`
+}
+
+// NewRegion converts an image.Rectangle to a Region
+func NewRegion(r image.Rectangle) Region {
+	return Region{X: r.Min.X, Y: r.Min.Y, W: r.Dx(), H: r.Dy()}
+}
+
+// Rect returns the region as an image.Rectangle
+func (r Region) Rect() image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.W, r.Y+r.H)
+}
+
+// Point is a position in display coordinates
+type Point struct {
+	X int `json:"x" yaml:"x"`
+	Y int `json:"y" yaml:"y"`
+}
+
+// ColorCalibration is a per-channel linear color correction: out = in*gain + offset (R, G, B)
+type ColorCalibration struct {
+	Gain   [3]float64 `json:"gain" yaml:"gain"`
+	Offset [3]float64 `json:"offset" yaml:"offset"`
+}
+
+// NewColorCalibration converts a screen.Normalization to a ColorCalibration (nil for identity)
+func NewColorCalibration(n screen.Normalization) *ColorCalibration {
+	if n.IsIdentity() {
+		return nil
+	}
+	return &ColorCalibration{Gain: n.Gain, Offset: n.Offset}
+}
+
+// Normalization returns the calibration as a screen.Normalization (identity if nil)
+func (c *ColorCalibration) Normalization() screen.Normalization {
+	if c == nil {
+		return screen.IdentityNormalization()
+	}
+	return screen.Normalization{Gain: c.Gain, Offset: c.Offset}
+}
+
+// Default returns a Config populated with the built-in defaults
+func Default() Config {
+	return Config{
+		AssetsDir:          "assets/global_targets",
+		Display:            0,
+		Tolerance:          constants.DefaultTolerance,
+		MatchMode:          screen.MatchColor.String(),
+		EntryScanInterval:  Duration(constants.EntryScanIntervalHighSpeed),
+		InGameScanInterval: Duration(constants.InGameScanInterval),
+		SearchScanInterval: Duration(constants.SearchScanInterval),
+		LobbyPollInterval:  Duration(constants.LobbyPollInterval),
+		LobbyTimeout:       Duration(constants.LobbyWaitTimeout),
+		EntryClickWait:     Duration(constants.VerifyPreWait),
+		ExitClickWait:      Duration(constants.WaitAfterClickNormal),
+		SearchClickWait:    Duration(constants.WaitAfterClickNormal),
+
+		AutoDetectMinMatches: 1,
+		EntrySortOrder:       "highest_first",
+		EntryBlacklistAction: "skip",
+		EntryROIMargin:       constants.EntryROIMargin,
+		EntryROIMarginUp:     constants.EntryROIMargin,
+		EntryScrollDirection: "down",
+		EntryScrollLines:     constants.EntryScrollLines,
+		CaptureFailThreshold: constants.CaptureFailThreshold,
+		CaptureRetries:       constants.CaptureRetries,
+		CaptureRetryBackoff:  Duration(constants.CaptureRetryBackoff),
+		VerifyFailThreshold:  constants.VerifyFailThreshold,
+		FreezeAction:         "pause",
+		FreezeNotify:         true,
+		WarmUp:               Duration(constants.WarmUpDelay),
+		PowerSaverFactor:     constants.PowerSaverFactor,
+	}
+}
+
+// Validate checks the config for out-of-range values
+func (c Config) Validate() error {
+	var problems []string
+
+	if strings.TrimSpace(c.AssetsDir) == "" {
+		problems = append(problems, "assets_dir must not be empty")
+	}
+	if c.Display < 0 {
+		problems = append(problems, fmt.Sprintf("display must be >= 0 (got %d)", c.Display))
+	}
+	if c.Similarity < 0 || c.Similarity >= 100 {
+		problems = append(problems, fmt.Sprintf("similarity must be in [0, 100) (got %.1f; 0 = use tolerance)", c.Similarity))
+	}
+	if c.Tolerance <= 0 || c.Tolerance > MaxTolerance {
+		problems = append(problems, fmt.Sprintf("tolerance must be in (0, %.1f] (got %.1f)", MaxTolerance, c.Tolerance))
+	}
+	if _, err := screen.ParseMatchMode(c.MatchMode); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if c.EntryScanInterval < 0 {
+		problems = append(problems, "entry_scan_interval must not be negative")
+	}
+	if c.InGameScanInterval < 0 {
+		problems = append(problems, "in_game_scan_interval must not be negative")
+	}
+	if c.SearchScanInterval < 0 {
+		problems = append(problems, "search_scan_interval must not be negative")
+	}
+	if c.LobbyPollInterval <= 0 {
+		problems = append(problems, "lobby_poll_interval must be positive")
+	}
+	if c.LobbyTimeout <= 0 {
+		problems = append(problems, "lobby_timeout must be positive")
+	}
+	if c.LobbyTimeout > 0 && c.LobbyPollInterval > c.LobbyTimeout {
+		problems = append(problems, "lobby_poll_interval must not exceed lobby_timeout")
+	}
+	if c.EntryClickWait < 0 || c.ExitClickWait < 0 || c.SearchClickWait < 0 {
+		problems = append(problems, "entry_click_wait, exit_click_wait and search_click_wait must not be negative")
+	}
+	if c.AutoDetectMinMatches < 1 {
+		problems = append(problems, "auto_detect_min_matches must be >= 1")
+	}
+	switch c.EntrySortOrder {
+	case "", "highest_first", "lowest_first":
+	default:
+		problems = append(problems, fmt.Sprintf("entry_sort_order must be highest_first or lowest_first (got %q)", c.EntrySortOrder))
+	}
+	if c.EntryROIMargin < 0 || c.EntryROIMarginUp < 0 {
+		problems = append(problems, "entry_roi_margin and entry_roi_margin_up must not be negative")
+	}
+	if c.EntityMaxAge < 0 {
+		problems = append(problems, "entity_max_age must not be negative")
+	}
+	if c.EntryScrollAfter < 0 {
+		problems = append(problems, "entry_scroll_after must not be negative")
+	}
+	switch c.EntryScrollDirection {
+	case "", "down", "up":
+	default:
+		problems = append(problems, fmt.Sprintf("entry_scroll_direction must be down or up (got %q)", c.EntryScrollDirection))
+	}
+	switch c.EntryBlacklistAction {
+	case "", "skip", "scroll", "notify", "stop":
+	default:
+		problems = append(problems, fmt.Sprintf("entry_blacklist_action must be skip, scroll, notify or stop (got %q)", c.EntryBlacklistAction))
+	}
+	if (c.EntryScrollAfter > 0 || c.EntryBlacklistAction == "scroll") && c.EntryScrollLines < 1 {
+		problems = append(problems, "entry_scroll_lines must be >= 1 when entry_scroll_after is set or entry_blacklist_action is scroll")
+	}
+	if r := c.EntryScrollRegion; r != (Region{}) && (r.W <= 0 || r.H <= 0 || r.X < 0 || r.Y < 0) {
+		problems = append(problems, "entry_scroll_region must have a non-negative origin and positive size")
+	}
+	if c.CaptureFailThreshold < 1 {
+		problems = append(problems, "capture_fail_threshold must be >= 1")
+	}
+	if c.CaptureRetries < 0 || c.CaptureRetryBackoff < 0 {
+		problems = append(problems, "capture_retries and capture_retry_backoff must not be negative")
+	}
+	if c.VerifyFailThreshold < 0 {
+		problems = append(problems, "verify_fail_threshold must not be negative")
+	}
+	for name, d := range c.StateDwell {
+		if d < 0 {
+			problems = append(problems, fmt.Sprintf("state_dwell[%s] must not be negative", name))
+		}
+	}
+	for feature, r := range c.ScanRegions {
+		known := false
+		for _, f := range ScanRegionFeatures {
+			known = known || f == feature
+		}
+		if !known {
+			problems = append(problems, fmt.Sprintf("scan_regions: unknown feature %q (want one of %v)", feature, ScanRegionFeatures))
+		}
+		if r.W <= 0 || r.H <= 0 || r.X < 0 || r.Y < 0 {
+			problems = append(problems, fmt.Sprintf("scan_regions.%s must have x, y >= 0 and positive w, h", feature))
+		}
+	}
+	for feature, tol := range c.FeatureTolerances {
+		known := false
+		for _, f := range ToleranceFeatures {
+			known = known || f == feature
+		}
+		if !known {
+			problems = append(problems, fmt.Sprintf("feature_tolerances: unknown feature %q (want one of %v)", feature, ToleranceFeatures))
+		}
+		if tol <= 0 || tol > MaxTolerance {
+			problems = append(problems, fmt.Sprintf("feature_tolerances.%s must be in (0, %.1f] (got %.1f)", feature, MaxTolerance, tol))
+		}
+	}
+	for i, tol := range c.EntryToleranceTiers {
+		if tol <= 0 || tol > MaxTolerance {
+			problems = append(problems, fmt.Sprintf("entry_tolerance_tiers[%d] must be in (0, %.1f] (got %.1f)", i, MaxTolerance, tol))
+		}
+		if i > 0 && tol <= c.EntryToleranceTiers[i-1] {
+			problems = append(problems, "entry_tolerance_tiers must be strictly ascending")
+		}
+	}
+	if c.FreezeTimeout < 0 {
+		problems = append(problems, "freeze_timeout must not be negative")
+	}
+	switch c.FreezeAction {
+	case "", "pause", "stop":
+	case "recover":
+		if c.FreezeRecoveryKey == "" && c.FreezeRecoveryClick == nil {
+			problems = append(problems, "freeze_action recover needs freeze_recovery_key or freeze_recovery_click")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("freeze_action must be pause, stop or recover (got %q)", c.FreezeAction))
+	}
+	if p := c.FreezeRecoveryClick; p != nil && (p.X < 0 || p.Y < 0) {
+		problems = append(problems, "freeze_recovery_click must have x, y >= 0")
+	}
+	if c.MaxRuntime < 0 {
+		problems = append(problems, "max_runtime must not be negative")
+	}
+	if cal := c.ColorCalibration; cal != nil && (cal.Gain[0] <= 0 || cal.Gain[1] <= 0 || cal.Gain[2] <= 0) {
+		problems = append(problems, "color_calibration gains must be positive")
+	}
+	if c.PowerSaverFactor < 1 {
+		problems = append(problems, "power_saver_factor must be >= 1")
+	}
+	if c.WarmUp < 0 {
+		problems = append(problems, "warm_up must not be negative")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Load reads a config file (.json, .yaml or .yml) on top of the defaults.
+// A missing file is not an error; the defaults are returned instead.
+func Load(path string) (Config, error) {
+	return loadOnto(path, Default())
+}
+
+// loadOnto reads a config file on top of cfg (see Load)
+func loadOnto(path string, cfg Config) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+
+	if err := Parse(data, filepath.Ext(path), &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	cfg.ApplySimilarity()
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// ApplySimilarity sets Tolerance from Similarity, if set. Out-of-range values are
+// left for Validate to report.
+func (c *Config) ApplySimilarity() {
+	if c.Similarity > 0 && c.Similarity < 100 {
+		c.Tolerance = screen.ToleranceFromSimilarity(c.Similarity)
+	}
+}
+
+// Parse decodes data into cfg based on the file extension.
+// Fields missing from data keep their current values.
+func Parse(data []byte, ext string, cfg *Config) error {
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal(data, cfg)
+	case ".json", "":
+		return json.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("unsupported config format %q", ext)
+	}
+}
+
+// Save writes the config to path, choosing the format from the extension
+func Save(path string, cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(cfg)
+	case ".json", "":
+		data, err = json.MarshalIndent(cfg, "", "  ")
+	default:
+		return fmt.Errorf("unsupported config format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
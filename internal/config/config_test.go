@@ -0,0 +1,249 @@
+package config
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/ConserveLee/gui-idle/internal/engine/screen"
+)
+
+func TestDefaultIsValid(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Default().Validate() = %v", err)
+	}
+	if cfg.Tolerance != constants.DefaultTolerance {
+		t.Errorf("Tolerance = %v, want %v", cfg.Tolerance, constants.DefaultTolerance)
+	}
+	if cfg.LobbyTimeout.D() != constants.LobbyWaitTimeout {
+		t.Errorf("LobbyTimeout = %v, want %v", cfg.LobbyTimeout.D(), constants.LobbyWaitTimeout)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		data    string
+		check   func(c Config) bool
+		wantErr bool
+	}{
+		{
+			name: "json",
+			ext:  ".json",
+			data: `{"display": 2, "tolerance": 12.5, "lobby_timeout": "90s"}`,
+			check: func(c Config) bool {
+				return c.Display == 2 && c.Tolerance == 12.5 && c.LobbyTimeout.D() == 90*time.Second
+			},
+		},
+		{
+			name:  "json duration as nanoseconds",
+			ext:   ".json",
+			data:  `{"entry_click_wait": 250000000}`,
+			check: func(c Config) bool { return c.EntryClickWait.D() == 250*time.Millisecond },
+		},
+		{
+			name:  "no extension is json",
+			ext:   "",
+			data:  `{"dry_run": true}`,
+			check: func(c Config) bool { return c.DryRun },
+		},
+		{
+			name: "yaml",
+			ext:  ".yaml",
+			data: "display: 1\nmatch_mode: binary\nlobby_poll_interval: 2s\n",
+			check: func(c Config) bool {
+				return c.Display == 1 && c.MatchMode == "binary" && c.LobbyPollInterval.D() == 2*time.Second
+			},
+		},
+		{
+			name:  "yml, upper case",
+			ext:   ".YML",
+			data:  "scan_regions:\n  entry: {x: 1, y: 2, w: 30, h: 40}\n",
+			check: func(c Config) bool { return c.ScanRegions["entry"] == Region{X: 1, Y: 2, W: 30, H: 40} },
+		},
+		{
+			name:  "missing fields keep their values",
+			ext:   ".json",
+			data:  `{}`,
+			check: func(c Config) bool { return c.AssetsDir == Default().AssetsDir && c.Tolerance == Default().Tolerance },
+		},
+		{name: "invalid json duration", ext: ".json", data: `{"lobby_timeout": "soon"}`, wantErr: true},
+		{name: "invalid yaml duration", ext: ".yaml", data: "lobby_timeout: soon\n", wantErr: true},
+		{name: "malformed json", ext: ".json", data: `{"display": `, wantErr: true},
+		{name: "unsupported format", ext: ".toml", data: `display = 1`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			err := Parse([]byte(tt.data), tt.ext, &cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !tt.check(cfg) {
+				t.Errorf("Parse gave %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string // Substring of the error; "" = valid
+	}{
+		{"defaults", func(*Config) {}, ""},
+		{"empty assets dir", func(c *Config) { c.AssetsDir = " " }, "assets_dir"},
+		{"negative display", func(c *Config) { c.Display = -1 }, "display"},
+		{"zero tolerance", func(c *Config) { c.Tolerance = 0 }, "tolerance"},
+		{"tolerance over the max", func(c *Config) { c.Tolerance = MaxTolerance + 1 }, "tolerance"},
+		{"unknown match mode", func(c *Config) { c.MatchMode = "fuzzy" }, "match mode"},
+		{"negative scan interval", func(c *Config) { c.EntryScanInterval = -1 }, "entry_scan_interval"},
+		{"zero lobby poll", func(c *Config) { c.LobbyPollInterval = 0 }, "lobby_poll_interval"},
+		{"zero lobby timeout", func(c *Config) { c.LobbyTimeout = 0 }, "lobby_timeout"},
+		{"lobby poll longer than the timeout", func(c *Config) { c.LobbyPollInterval, c.LobbyTimeout = Duration(time.Minute), Duration(time.Second) }, "lobby_poll_interval"},
+		{"lobby poll equal to the timeout", func(c *Config) { c.LobbyPollInterval, c.LobbyTimeout = Duration(time.Minute), Duration(time.Minute) }, ""},
+		{"negative click wait", func(c *Config) { c.ExitClickWait = -1 }, "exit_click_wait"},
+		{"zero min matches", func(c *Config) { c.AutoDetectMinMatches = 0 }, "auto_detect_min_matches"},
+		{"unknown sort order", func(c *Config) { c.EntrySortOrder = "random" }, "entry_sort_order"},
+		{"unknown blacklist action", func(c *Config) { c.EntryBlacklistAction = "ignore" }, "entry_blacklist_action"},
+		{"scroll without lines", func(c *Config) { c.EntryScrollAfter, c.EntryScrollLines = 3, 0 }, "entry_scroll_lines"},
+		{"unknown scan region feature", func(c *Config) { c.ScanRegions = map[string]Region{"lobby": {W: 1, H: 1}} }, "unknown feature"},
+		{"empty scan region", func(c *Config) { c.ScanRegions = map[string]Region{"entry": {X: 1, Y: 1}} }, "scan_regions.entry"},
+		{"descending tolerance tiers", func(c *Config) { c.EntryToleranceTiers = []float64{40, 30} }, "ascending"},
+		{"zero calibration gain", func(c *Config) { c.ColorCalibration = &ColorCalibration{Gain: [3]float64{1, 0, 1}} }, "color_calibration"},
+		{"power saver factor below 1", func(c *Config) { c.PowerSaverFactor = 0.5 }, "power_saver_factor"},
+		{"recover without a key or click", func(c *Config) { c.FreezeAction = "recover" }, "freeze_recovery_key"},
+		{"recover with a key", func(c *Config) { c.FreezeAction, c.FreezeRecoveryKey = "recover", "esc" }, ""},
+		{"unknown freeze action", func(c *Config) { c.FreezeAction = "panic" }, "freeze_action"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Validate = %v, want nil", err)
+			case tt.want != "" && err == nil:
+				t.Errorf("Validate = nil, want an error about %s", tt.want)
+			case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+				t.Errorf("Validate = %v, want an error about %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSeconds(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"50", 50 * time.Second, false},
+		{" 120 ", 2 * time.Minute, false},
+		{"2.5", 2500 * time.Millisecond, false},
+		{"0", 0, true},
+		{"-5", 0, true},
+		{"", 0, true},
+		{"50s", 0, true},
+		{"NaN", 0, true},
+		{"Inf", 0, true},
+		{"1e300", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSeconds(tt.text)
+		if (err != nil) != tt.wantErr || got.D() != tt.want {
+			t.Errorf("ParseSeconds(%q) = %v, %v; want %v, wantErr %v", tt.text, got.D(), err, tt.want, tt.wantErr)
+		}
+	}
+	for _, d := range []time.Duration{50 * time.Second, 2500 * time.Millisecond} {
+		if got, err := ParseSeconds(FormatSeconds(Duration(d))); err != nil || got.D() != d {
+			t.Errorf("ParseSeconds(FormatSeconds(%v)) = %v, %v", d, got.D(), err)
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	for _, ext := range []string{".json", ".yaml"} {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config"+ext)
+			cfg := Default()
+			cfg.Display = 1
+			cfg.LobbyTimeout = Duration(2 * time.Minute)
+			cfg.ScanRegions = map[string]Region{"entry": {X: 10, Y: 20, W: 300, H: 400}}
+			if err := Save(path, cfg); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if loaded.Display != 1 || loaded.LobbyTimeout != cfg.LobbyTimeout || loaded.ScanRegions["entry"] != cfg.ScanRegions["entry"] {
+				t.Errorf("Load = %+v, want the saved config", loaded)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	if cfg, err := Load(filepath.Join(dir, "missing.json")); err != nil || cfg.Tolerance != Default().Tolerance {
+		t.Errorf("Load(missing) = %v, want the defaults", err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte(`{"tolerance": -1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(invalid); err == nil || !strings.Contains(err.Error(), "tolerance") {
+		t.Errorf("Load(invalid) = %v, want a tolerance error", err)
+	}
+
+	malformed := filepath.Join(dir, "malformed.yaml")
+	if err := os.WriteFile(malformed, []byte("display: [\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(malformed); err == nil || !strings.Contains(err.Error(), malformed) {
+		t.Errorf("Load(malformed) = %v, want an error naming the file", err)
+	}
+}
+
+// The similarity slider's ends (0% and 99%) must survive a save and a load
+func TestSimilarityRoundTrip(t *testing.T) {
+	for _, percent := range []float64{0, 99} {
+		path := filepath.Join(t.TempDir(), "config.json")
+		cfg := Default()
+		cfg.Tolerance = screen.ToleranceFromSimilarity(percent)
+		cfg.Similarity = percent
+		if err := Save(path, cfg); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("%.0f%%: Load = %v", percent, err)
+		}
+		if got := math.Round(screen.SimilarityFromTolerance(loaded.Tolerance)); got != percent {
+			t.Errorf("%.0f%%: loaded tolerance %.2f is %.0f%%", percent, loaded.Tolerance, got)
+		}
+	}
+}
+
+func TestApplySimilarity(t *testing.T) {
+	cfg := Default()
+	cfg.ApplySimilarity()
+	if cfg.Tolerance != Default().Tolerance {
+		t.Errorf("similarity 0 changed the tolerance to %.2f", cfg.Tolerance)
+	}
+	cfg.Similarity = 99
+	cfg.ApplySimilarity()
+	if want := screen.ToleranceFromSimilarity(99); cfg.Tolerance != want {
+		t.Errorf("similarity 99: tolerance = %.2f, want %.2f", cfg.Tolerance, want)
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ProfilesDir holds one directory per named profile (see Profile)
+const ProfilesDir = "profiles"
+
+// DefaultProfile is the built-in profile: config.json and assets/ in the working
+// directory, the layout from before profiles existed
+const DefaultProfile = "default"
+
+// activeProfileFile (in ProfilesDir) remembers the last-used profile
+const activeProfileFile = "active"
+
+// Profile is a named set of settings and asset directories, one per game.
+// A named profile lives in profiles/<name>/ with its own config.json and assets/.
+type Profile struct {
+	Name       string
+	ConfigPath string // Settings file
+	AssetsRoot string // Template root (global_targets, normal_targets, ...)
+}
+
+// ResolveProfile returns the paths of the named profile ("" = DefaultProfile).
+// The profile's directory does not have to exist yet.
+func ResolveProfile(name string) (Profile, error) {
+	if name == "" || name == DefaultProfile {
+		return Profile{Name: DefaultProfile, ConfigPath: DefaultPath, AssetsRoot: "assets"}, nil
+	}
+	if err := ValidateProfileName(name); err != nil {
+		return Profile{}, err
+	}
+	dir := filepath.Join(ProfilesDir, name)
+	p := Profile{Name: name, ConfigPath: filepath.Join(dir, DefaultPath), AssetsRoot: filepath.Join(dir, "assets")}
+	// Keep a YAML config if the profile was written by hand that way
+	for _, ext := range []string{".yaml", ".yml"} {
+		path := filepath.Join(dir, "config"+ext)
+		if _, err := os.Stat(path); err == nil {
+			p.ConfigPath = path
+			break
+		}
+	}
+	return p, nil
+}
+
+// ValidateProfileName rejects names that are not a single plain directory name
+func ValidateProfileName(name string) error {
+	switch {
+	case strings.TrimSpace(name) != name || name == "":
+		return fmt.Errorf("invalid profile name %q: must not be empty or padded", name)
+	case strings.ContainsAny(name, `/\:`) || strings.HasPrefix(name, "."):
+		return fmt.Errorf("invalid profile name %q: must be a plain directory name", name)
+	}
+	return nil
+}
+
+// LoadProfile loads the profile's config on top of the defaults, with the assets
+// dir defaulting to the profile's own global_targets
+func LoadProfile(p Profile) (Config, error) {
+	cfg := Default()
+	cfg.AssetsDir = filepath.Join(p.AssetsRoot, "global_targets")
+	return loadOnto(p.ConfigPath, cfg)
+}
+
+// ListProfiles returns DefaultProfile followed by the profiles in ProfilesDir, sorted
+func ListProfiles() ([]string, error) {
+	names := []string{DefaultProfile}
+	entries, err := os.ReadDir(ProfilesDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return names, nil
+	}
+	if err != nil {
+		return names, err
+	}
+	var found []string
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != DefaultProfile && ValidateProfileName(e.Name()) == nil {
+			found = append(found, e.Name())
+		}
+	}
+	sort.Strings(found)
+	return append(names, found...), nil
+}
+
+// CreateProfile creates the directories of a new named profile
+func CreateProfile(name string) (Profile, error) {
+	if name == DefaultProfile {
+		return Profile{}, fmt.Errorf("profile %q already exists", name)
+	}
+	p, err := ResolveProfile(name)
+	if err != nil {
+		return p, err
+	}
+	if _, err := os.Stat(filepath.Dir(p.ConfigPath)); err == nil {
+		return p, fmt.Errorf("profile %q already exists", name)
+	}
+	for _, dir := range []string{"global_targets", "normal_targets"} {
+		if err := os.MkdirAll(filepath.Join(p.AssetsRoot, dir), 0755); err != nil {
+			return p, err
+		}
+	}
+	return p, nil
+}
+
+// ActiveProfile returns the last-used profile name (DefaultProfile if none was saved)
+func ActiveProfile() string {
+	data, err := os.ReadFile(filepath.Join(ProfilesDir, activeProfileFile))
+	if err != nil {
+		return DefaultProfile
+	}
+	name := strings.TrimSpace(string(data))
+	if ValidateProfileName(name) != nil {
+		return DefaultProfile
+	}
+	return name
+}
+
+// SaveActiveProfile remembers name as the last-used profile
+func SaveActiveProfile(name string) error {
+	if err := os.MkdirAll(ProfilesDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(ProfilesDir, activeProfileFile), []byte(name+"\n"), 0644)
+}
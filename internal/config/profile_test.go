@@ -0,0 +1,128 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"game-a", false},
+		{"Game B 2", false},
+		{"", true},
+		{" padded", true},
+		{"..", true},
+		{".hidden", true},
+		{"a/b", true},
+		{`a\b`, true},
+		{"../escape", true},
+		{"c:", true},
+	}
+	for _, tt := range tests {
+		if err := ValidateProfileName(tt.name); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateProfileName(%q) = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestResolveProfile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	for _, name := range []string{"", DefaultProfile} {
+		p, err := ResolveProfile(name)
+		if err != nil || p.Name != DefaultProfile || p.ConfigPath != DefaultPath || p.AssetsRoot != "assets" {
+			t.Errorf("ResolveProfile(%q) = %+v, %v; want the default layout", name, p, err)
+		}
+	}
+	if _, err := ResolveProfile("../escape"); err == nil {
+		t.Error("ResolveProfile accepted a path outside the profiles directory")
+	}
+
+	// A hand-written YAML config is kept
+	dir := filepath.Join(ProfilesDir, "yaml")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("display: 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := ResolveProfile("yaml"); err != nil || p.ConfigPath != filepath.Join(dir, "config.yaml") {
+		t.Errorf("ResolveProfile(yaml) = %+v, %v; want its config.yaml", p, err)
+	}
+}
+
+func TestProfileRoundTrip(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if names, err := ListProfiles(); err != nil || !slices.Equal(names, []string{DefaultProfile}) {
+		t.Errorf("ListProfiles without a profiles directory = %v, %v", names, err)
+	}
+
+	p, err := CreateProfile("game-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"global_targets", "normal_targets"} {
+		if _, err := os.Stat(filepath.Join(p.AssetsRoot, dir)); err != nil {
+			t.Errorf("CreateProfile did not create %s: %v", dir, err)
+		}
+	}
+	if _, err := CreateProfile("game-a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateProfile("game-b"); err == nil {
+		t.Error("CreateProfile overwrote an existing profile")
+	}
+	if _, err := CreateProfile(DefaultProfile); err == nil {
+		t.Error("CreateProfile created the default profile")
+	}
+
+	names, err := ListProfiles()
+	if want := []string{DefaultProfile, "game-a", "game-b"}; err != nil || !slices.Equal(names, want) {
+		t.Errorf("ListProfiles = %v, %v; want %v", names, err, want)
+	}
+
+	// A new profile loads the defaults, with its own assets
+	cfg, err := LoadProfile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(p.AssetsRoot, "global_targets"); cfg.AssetsDir != want {
+		t.Errorf("AssetsDir = %q, want %q", cfg.AssetsDir, want)
+	}
+
+	cfg.Display = 2
+	if err := Save(p.ConfigPath, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := LoadProfile(p); err != nil || loaded.Display != 2 {
+		t.Errorf("LoadProfile after Save = display %d, %v; want 2", loaded.Display, err)
+	}
+}
+
+func TestActiveProfile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if got := ActiveProfile(); got != DefaultProfile {
+		t.Errorf("ActiveProfile with nothing saved = %q, want %q", got, DefaultProfile)
+	}
+	if err := SaveActiveProfile("game-a"); err != nil {
+		t.Fatal(err)
+	}
+	if got := ActiveProfile(); got != "game-a" {
+		t.Errorf("ActiveProfile = %q, want game-a", got)
+	}
+
+	// A tampered file falls back to the default
+	if err := os.WriteFile(filepath.Join(ProfilesDir, activeProfileFile), []byte("../escape\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := ActiveProfile(); got != DefaultProfile {
+		t.Errorf("ActiveProfile with an invalid name saved = %q, want %q", got, DefaultProfile)
+	}
+}
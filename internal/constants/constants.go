@@ -11,27 +11,129 @@ const (
 	SearchScanInterval         = 2 * time.Second        // Scan interval for search steps
 	SearchRetryInterval        = 500 * time.Millisecond // Fast retry interval for search states
 
+	// Handler Retry Intervals
+	EntryCaptureRetryInterval = 400 * time.Millisecond // Retry after a failed capture in Entry
+	ExitCaptureRetryInterval  = 10 * time.Second       // Retry after a failed capture in ExitStep1
+	ExitRetryInterval         = 5 * time.Second        // Rescan when no exit button is found in ExitStep1
+
+	// Start
+	WarmUpDelay = 2 * time.Second // Delay before the first scan, to switch to the game
+
+	// Color Calibration
+	CalibrationTolerance = 80.0 // Loose tolerance for finding the reference swatch on an uncorrected frame
+
+	// Power Saver
+	PowerSaverFactor = 3.0 // Default scan interval multiplier while power saving
+
+	// Compact Mode
+	CompactWindowWidth = 420 // Control window width in compact mode
+
+	// Lobby Wait
+	LobbyPollInterval = 5 * time.Second  // Interval between lobby.png checks
+	LobbyWaitTimeout  = 50 * time.Second // Give up waiting in lobby after this long
+
+	// Idle Detection (the lobby wait on an unchanging screen, see handleEntryWaitingState)
+	IdleFrames          = 3     // Consecutive unchanged frames before going idle
+	IdleIntervalFactor  = 3     // Scan interval multiplier while idle
+	IdleChangeThreshold = 0.005 // Fraction of changed pixels that counts as "the screen changed"
+
+	// Search Step ROI
+	SearchROIMargin = 50 // Margin (px) around a search-step button's last position
+	HashMaxDistance = 5  // Max differing aHash bits (of 64) for a fixed-position hash hit
+
+	// Entry ROI
+	EntryROIMargin = 100 // Default margin (px) around the last clicked entry
+
+	// Entry Y Cutoff: matches below this line are ignored (likely false positives).
+	// Tuned at 1080p and scaled to the captured display height.
+	EntryMaxY          = 950
+	EntryMaxYRefHeight = 1080
+
+	// Entry Scroll
+	EntryScrollLines = 5 // Default mouse wheel steps per entry list scroll
+
+	// Anti-Templates
+	AntiTemplateMargin = 30 // Margin (px) around an entry within which an anti-template vetoes it
+
+	// Hover Before Click
+	HoverVerifyMargin = 10 // Margin (px) around a hovered target searched when re-verifying it
+
 	// Retry Limits
-	SearchMaxRetries = 3 // Max retries before falling back to AutoDetect
+	SearchMaxRetries     = 3                     // Max retries before falling back to AutoDetect
+	CaptureFailThreshold = 10                    // Consecutive capture failures before escalating
+	CaptureRetries       = 2                     // Extra capture attempts within one CaptureScreen call (transient glitches)
+	CaptureRetryBackoff  = 30 * time.Millisecond // Wait before the first capture retry (doubles per retry)
+	VerifyFailThreshold  = 5                     // Consecutive failed search cycles (highlight never verified) before escalating
 
 	// Interaction Delays
 	WaitAfterClickQuick  = 100 * time.Millisecond // Quick wait after clicking Entry
 	WaitAfterClickNormal = 1 * time.Second        // Standard wait after clicking Search/Exit buttons
 
 	// Verification
-	EntryVerifyTimeout = 5 * time.Second
-	VerifyPreWait      = 200 * time.Millisecond // Wait before starting verification (screen transition)
-	VerifyRetryWait    = 200 * time.Millisecond // Wait between verification attempts
-	VerifyLoadingWait  = 300 * time.Millisecond // Wait when screen state is loading/unrecognized
+	EntryVerifyTimeout  = 5 * time.Second
+	EntryVerifyAttempts = 5                      // Verification attempts (one per tick) before giving up on an entry click
+	VerifyPreWait       = 200 * time.Millisecond // Wait before starting verification (screen transition)
+	VerifyRetryWait     = 200 * time.Millisecond // Wait between verification attempts
+	VerifyLoadingWait   = 300 * time.Millisecond // Wait when screen state is loading/unrecognized
+
+	// Region-Change Verification (fallback when finding.png is missing)
+	RegionChangeMargin    = 20  // Margin (px) around the clicked entity that is compared
+	RegionChangeThreshold = 0.3 // Fraction of changed pixels that counts as "the click did something"
+
+	// Frozen Screen
+	FreezeMaxRecoveries = 3 // Recovery attempts per freeze before pausing instead
+
+	// Macros (tools tab)
+	MacroPollInterval = 50 * time.Millisecond // Cursor sampling interval while recording
+	MacroDwell        = 1 * time.Second       // Cursor rest that records a click
+	MacroRestRadius   = 3                     // Movement (px) that still counts as resting
+	MacroStartDelay   = 3 * time.Second       // Countdown before a replay (time to switch to the game)
+
+	// Pause
+	PausePollInterval = 250 * time.Millisecond // Loop wake-up interval while paused (e.g. a dialog is open)
+
+	// Status Bar
+	ScanRateWindow    = 5 * time.Second // Rolling window for the scans/s readout
+	ScanProgressEvery = 3               // Templates between "scanning template i/n" status updates
+
+	// State History
+	StateHistorySize = 200 // Max state transitions kept for the history view
+
+	// Events
+	EventBufferSize = 64 // Buffered BotEvents before new ones are dropped
 
 	// Entity Tracker
-	EntityTTL = 2 * time.Second // Time before a tracked entity is removed if not seen
+	EntityTTL                 = 2 * time.Second  // Time before a tracked entity is removed if not seen
+	BlacklistCooldownInterval = 3 * time.Second  // Entry scan interval while every detected entity is blacklisted
+	BlacklistExpiry           = 60 * time.Second // Blacklisted entities become retryable after this long
 
 	// Image Matching
-	DefaultTolerance = 60    // Color tolerance for pixel comparison
-	MaxFailRate      = 0.03  // Allow up to 3% of pixels to fail matching
-	MaxPixelDiff     = 150.0 // Maximum allowed color diff for any pixel (reject if exceeded)
+	DefaultTolerance   = 60    // Color tolerance for pixel comparison
+	MaxFailRate        = 0.03  // Allow up to 3% of pixels to fail matching
+	MaxPixelDiff       = 150.0 // Maximum allowed color diff for any pixel (reject if exceeded)
+	BinaryThreshold    = 160   // Luma cutoff for binary match mode (>= is white)
+	ChromaKeyTolerance = 12    // Max color diff for a template pixel to count as the chroma key
+
+	OpaqueBoundsCacheSize = 512 // Templates whose opaque bounds a Searcher remembers
+
+	// Incremental Scan (entry): rescan only blocks that changed since the last frame
+	IncrementalBlockSize     = 32 // Block size (px) of the frame diff
+	IncrementalDiffTolerance = 10 // Per-pixel color difference that changes a block
+
+	// Template Size Check (crop tool)
+	TemplateMinOpaquePixels = 100                   // Fewer opaque pixels than this matches unreliably
+	TemplateMaxScanTime     = 50 * time.Millisecond // Full-screen scans slower than this suggest a tighter crop
+	TemplateBenchmarkRuns   = 3                     // Scans averaged per benchmark
+
+	// Confirm First Click
+	ConfirmPreviewRadius = 120 // Half-size (px) of the screen area shown around the first click
+
+	// Click Marker
+	ClickMarkerSize      = 40          // Size (px) of the marker flashed at each click
+	ClickMarkerDuration  = time.Second // Time for the marker to fade out
+	ClickMarkerFadeSteps = 10          // Opacity steps while fading
 
 	// Debugging
-	DebugDump = true
+	DebugDump      = true
+	HeatmapSamples = 256 // Max template pixels sampled per position by the match heatmap
 )
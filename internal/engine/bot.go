@@ -2,6 +2,7 @@ package engine
 
 import (
 	"fmt"
+	"github.com/ConserveLee/gui-idle/internal/constants"
 	"github.com/ConserveLee/gui-idle/internal/engine/screen"
 	"image"
 	"path/filepath"
@@ -23,6 +24,7 @@ const (
 type BotConfig struct {
 	AssetsDir string        // Directory containing target images
 	Interval  time.Duration // Scan interval
+	Tolerance float64       // Color tolerance for pixel comparison
 }
 
 type Target struct {
@@ -41,6 +43,7 @@ type Bot struct {
 	DebugFunc  func(string, ...interface{}) // For console debug
 
 	stopChan  chan struct{}
+	stopping  bool // Stop is waiting for the loop to exit
 	wg        sync.WaitGroup
 	mu        sync.Mutex
 	
@@ -60,6 +63,7 @@ func NewBot(logFunc func(string), statusFunc func(string), debugFunc func(string
 		Config: BotConfig{
 			AssetsDir: "assets/click",
 			Interval:  1 * time.Second,
+			Tolerance: constants.DefaultTolerance,
 		},
 	}
 }
@@ -76,7 +80,7 @@ func (b *Bot) loadAssets() error {
 	for _, file := range files {
 		img, err := b.searcher.LoadImage(file)
 		if err != nil {
-			b.DebugFunc("Failed to load asset %s: %v", file, err)
+			b.LogFunc(fmt.Sprintf("Skipping corrupt asset: %v", err))
 			continue
 		}
 		
@@ -93,10 +97,17 @@ func (b *Bot) loadAssets() error {
 }
 
 // SetDisplayID sets which monitor the bot should scan
-func (b *Bot) SetDisplayID(id int) {
+func (b *Bot) SetDisplayID(id int) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	b.searcher.SetDisplayID(id)
+	return b.searcher.SetDisplayID(id)
+}
+
+// SetTolerance sets the color tolerance; it takes effect on the next scan
+func (b *Bot) SetTolerance(tolerance float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.Config.Tolerance = tolerance
 }
 
 // Start begins the automation loop
@@ -125,17 +136,29 @@ func (b *Bot) Start() {
 	go b.loop()
 }
 
-// Stop signals the automation loop to end
-func (b *Bot) Stop() {
+// Running reports whether the automation loop is active
+func (b *Bot) Running() bool {
 	b.mu.Lock()
 	defer b.mu.Unlock()
+	return b.Status == StatusRunning
+}
 
-	if b.Status == StatusStopped {
+// Stop signals the automation loop to end
+func (b *Bot) Stop() {
+	b.mu.Lock()
+	if b.Status == StatusStopped || b.stopping {
+		b.mu.Unlock()
 		return
 	}
-
+	b.stopping = true
 	close(b.stopChan)
-	b.wg.Wait() // Wait for loop to finish
+	b.mu.Unlock()
+
+	b.wg.Wait() // Wait for loop to finish (process takes b.mu to read the tolerance)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stopping = false
 	b.Status = StatusStopped
 	b.LogFunc("Bot stopped.")
 	b.StatusFunc("Status: Stopped")
@@ -171,10 +194,14 @@ func (b *Bot) process() {
 	// Update transient status (Scanning...)
 	b.StatusFunc("Status: Scanning...")
 
+	// Tolerance may be changed from the UI while running
+	b.mu.Lock()
+	tolerance := b.Config.Tolerance
+	b.mu.Unlock()
+
 	// 2. Iterate through targets by priority
 	for _, target := range b.targets {
-		// Use a tolerance of ~45 for RGB difference
-		fx, fy, found := b.searcher.FindTemplate(screenImg, target.Image, 45.0)
+		fx, fy, found := b.searcher.FindTemplate(screenImg, target.Image, tolerance)
 
 		if found {
 			// Log success
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+func newTestBot() *Bot {
+	return NewBot(func(string) {}, func(string) {}, func(string, ...interface{}) {})
+}
+
+// runFakeLoop marks b as running with a loop that waits for Stop, then calls exit
+// before finishing (standing in for a process call that was mid-scan)
+func runFakeLoop(b *Bot, exit func()) {
+	b.Status = StatusRunning
+	b.stopChan = make(chan struct{})
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		<-b.stopChan
+		exit()
+	}()
+}
+
+func TestSetTolerance(t *testing.T) {
+	b := newTestBot()
+	if b.Config.Tolerance != constants.DefaultTolerance {
+		t.Fatalf("default tolerance = %v, want %v", b.Config.Tolerance, constants.DefaultTolerance)
+	}
+
+	// Set while a scan reads it, as the UI slider does (run with -race)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			b.mu.Lock()
+			_ = b.Config.Tolerance
+			b.mu.Unlock()
+		}
+	}()
+	b.SetTolerance(30)
+	wg.Wait()
+
+	if b.Config.Tolerance != 30 {
+		t.Errorf("tolerance = %v, want 30", b.Config.Tolerance)
+	}
+}
+
+func TestStopDoesNotHoldLockWhileWaiting(t *testing.T) {
+	b := newTestBot()
+	// The loop takes b.mu on its way out, like process reading the tolerance
+	runFakeLoop(b, func() { b.SetTolerance(20) })
+
+	done := make(chan struct{})
+	go func() {
+		b.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop deadlocked waiting for a loop that needs the lock")
+	}
+	if b.Running() {
+		t.Error("bot still running after Stop")
+	}
+	if b.Config.Tolerance != 20 {
+		t.Errorf("tolerance = %v, want the loop's 20", b.Config.Tolerance)
+	}
+}
+
+func TestStopWhileStopping(t *testing.T) {
+	b := newTestBot()
+	release := make(chan struct{})
+	runFakeLoop(b, func() { <-release })
+
+	first := make(chan struct{})
+	go func() {
+		b.Stop()
+		close(first)
+	}()
+	// Wait until the first Stop is blocked on the loop
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		b.mu.Lock()
+		stopping := b.stopping
+		b.mu.Unlock()
+		if stopping {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Stop never set the stopping flag")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// A second Stop returns at once instead of closing stopChan again
+	second := make(chan struct{})
+	go func() {
+		b.Stop()
+		close(second)
+	}()
+	select {
+	case <-second:
+	case <-time.After(2 * time.Second):
+		t.Fatal("second Stop blocked while the first was waiting")
+	}
+	if !b.Running() {
+		t.Error("bot reported stopped before its loop exited")
+	}
+
+	close(release)
+	<-first
+	if b.Running() {
+		t.Error("bot still running after Stop")
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopping {
+		t.Error("stopping flag not cleared")
+	}
+}
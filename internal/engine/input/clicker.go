@@ -0,0 +1,181 @@
+package input
+
+import (
+	"fmt"
+	"image"
+	"time"
+)
+
+// DoubleClickGap is the pause between the two clicks of a double-click
+const DoubleClickGap = 10 * time.Millisecond
+
+// Clicker turns a display-local template match into a global click.
+// It is the single click path shared by the bots and the tools preview.
+type Clicker struct {
+	Actions Actions
+	OffsetX int           // Display origin in global coordinates
+	OffsetY int           // Display origin in global coordinates
+	DryRun  bool          // Log the click instead of performing it
+	Double  bool          // Double-click instead of single click
+	Hold    time.Duration // Press and hold this long instead of clicking (0 = instant click)
+	Hover   time.Duration // Rest on the target this long before pressing (hover-reveal UIs; 0 = none)
+
+	// StillThere is asked after hovering; false cancels the press (nil = no re-check)
+	StillThere func() bool
+
+	// Gate skips real input while held, e.g. while a dialog is open (nil = never)
+	Gate *Gate
+
+	// Record receives every click performed (or dry-run), for the click accuracy log
+	Record  func(ClickRecord)
+	Display int     // Display index, for Record
+	Scale   float64 // Display scale factor, for Record
+
+	LogFunc   func(string)
+	DebugFunc func(string, ...interface{})
+}
+
+// Click clicks the center of the w x h box at display-local (x, y)
+// and returns the global coordinate that was (or would have been) clicked.
+// With Hover set it moves there first, waits, and only presses if StillThere agrees.
+func (c *Clicker) Click(name string, x, y, w, h int) image.Point {
+	centerX := x + w/2
+	centerY := y + h/2
+	global := image.Point{X: centerX + c.OffsetX, Y: centerY + c.OffsetY}
+
+	if c.DebugFunc != nil {
+		c.DebugFunc("Clicking [%s] Center(%d, %d) [Global: %d, %d]", name, centerX, centerY, global.X, global.Y)
+	}
+	if c.DryRun {
+		if c.LogFunc != nil {
+			c.LogFunc(fmt.Sprintf("[DryRun] Would click [%s] at (%d, %d)", name, global.X, global.Y))
+		}
+		c.record(name, x, y, w, h, global)
+		return global
+	}
+	if c.paused("click", name) {
+		return global
+	}
+
+	c.Actions.MoveMouse(global.X, global.Y)
+	if c.Hover > 0 {
+		time.Sleep(c.Hover)
+		if c.StillThere != nil && !c.StillThere() {
+			if c.LogFunc != nil {
+				c.LogFunc(fmt.Sprintf("[%s] gone after hovering %v, not clicking", name, c.Hover))
+			}
+			return global
+		}
+		if c.paused("click", name) { // Paused while hovering
+			return global
+		}
+	}
+	c.record(name, x, y, w, h, global)
+	if c.Hold > 0 {
+		c.Actions.Toggle("left", "down")
+		time.Sleep(c.Hold)
+		c.Actions.Toggle("left", "up")
+		return global
+	}
+	c.Actions.Click("left")
+	if c.Double {
+		time.Sleep(DoubleClickGap)
+		c.Actions.Click("left")
+	}
+	return global
+}
+
+// paused reports (and logs) that an action is skipped because the gate is held
+func (c *Clicker) paused(action, name string) bool {
+	if !c.Gate.Held() {
+		return false
+	}
+	if c.LogFunc != nil {
+		c.LogFunc(fmt.Sprintf("[Paused] Skipped %s [%s]", action, name))
+	}
+	return true
+}
+
+// record reports a click of the w x h box at display-local (x, y) to Record, if set
+func (c *Clicker) record(name string, x, y, w, h int, global image.Point) {
+	if c.Record == nil {
+		return
+	}
+	c.Record(ClickRecord{
+		Time:    time.Now(),
+		Name:    name,
+		Display: c.Display,
+		Scale:   c.Scale,
+		Target:  image.Rect(x, y, x+w, y+h),
+		Center:  image.Point{X: x + w/2, Y: y + h/2},
+		Offset:  image.Point{X: c.OffsetX, Y: c.OffsetY},
+		Global:  global,
+		DryRun:  c.DryRun,
+	})
+}
+
+// Scroll moves the mouse to display-local (x, y) and turns the wheel by lines
+// (positive scrolls down, negative up). It returns the global point scrolled at.
+func (c *Clicker) Scroll(name string, x, y, lines int) image.Point {
+	global := image.Point{X: x + c.OffsetX, Y: y + c.OffsetY}
+
+	if c.DebugFunc != nil {
+		c.DebugFunc("Scrolling [%s] %d lines [Global: %d, %d]", name, lines, global.X, global.Y)
+	}
+	if c.DryRun {
+		if c.LogFunc != nil {
+			c.LogFunc(fmt.Sprintf("[DryRun] Would scroll [%s] %d lines at (%d, %d)", name, lines, global.X, global.Y))
+		}
+		return global
+	}
+	if c.paused("scroll", name) {
+		return global
+	}
+
+	c.Actions.MoveMouse(global.X, global.Y)
+	c.Actions.Scroll(0, -lines)
+	return global
+}
+
+// Key presses key (a robotgo.KeyTap name)
+func (c *Clicker) Key(name, key string) {
+	if c.DebugFunc != nil {
+		c.DebugFunc("Pressing key [%s] for [%s]", key, name)
+	}
+	if c.DryRun {
+		if c.LogFunc != nil {
+			c.LogFunc(fmt.Sprintf("[DryRun] Would press [%s] for [%s]", key, name))
+		}
+		return
+	}
+	if c.paused("key", name) {
+		return
+	}
+	c.Actions.KeyTap(key)
+}
+
+// Drag presses at the center of the w x h box at display-local (x, y), moves by
+// (dx, dy) with the button held and releases. It returns the global start and end points.
+func (c *Clicker) Drag(name string, x, y, w, h, dx, dy int) (image.Point, image.Point) {
+	start := image.Point{X: x + w/2 + c.OffsetX, Y: y + h/2 + c.OffsetY}
+	end := start.Add(image.Point{X: dx, Y: dy})
+
+	if c.DebugFunc != nil {
+		c.DebugFunc("Dragging [%s] [Global: %d, %d -> %d, %d]", name, start.X, start.Y, end.X, end.Y)
+	}
+	if c.DryRun {
+		if c.LogFunc != nil {
+			c.LogFunc(fmt.Sprintf("[DryRun] Would drag [%s] from (%d, %d) to (%d, %d)", name, start.X, start.Y, end.X, end.Y))
+		}
+		return start, end
+	}
+	if c.paused("drag", name) {
+		return start, end
+	}
+
+	c.Actions.MoveMouse(start.X, start.Y)
+	c.Actions.Toggle("left", "down")
+	c.Actions.MoveSmooth(end.X, end.Y)
+	c.Actions.Toggle("left", "up")
+	return start, end
+}
@@ -0,0 +1,138 @@
+package input
+
+import (
+	"image"
+	"slices"
+	"testing"
+	"time"
+)
+
+// ops returns the recorded operations, toggles as "down" or "up"
+func ops(calls []Call) []string {
+	var out []string
+	for _, c := range calls {
+		if c.Op == "toggle" {
+			out = append(out, c.Dir)
+			continue
+		}
+		out = append(out, c.Op)
+	}
+	return out
+}
+
+func TestClickHold(t *testing.T) {
+	const hold = 30 * time.Millisecond
+	rec := &Recorder{}
+	c := &Clicker{Actions: rec, Hold: hold}
+	c.Click("hold", 10, 10, 8, 8)
+
+	if got, want := ops(rec.Calls), []string{"move", "down", "up"}; !slices.Equal(got, want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+	if gap := rec.Calls[2].At.Sub(rec.Calls[1].At); gap < hold {
+		t.Errorf("held %v, want at least %v", gap, hold)
+	}
+
+	rec = &Recorder{}
+	c = &Clicker{Actions: rec}
+	c.Click("instant", 10, 10, 8, 8)
+	if got, want := ops(rec.Calls), []string{"move", "click"}; !slices.Equal(got, want) {
+		t.Errorf("zero hold: calls = %v, want %v", got, want)
+	}
+}
+
+func TestClickHover(t *testing.T) {
+	const hover = 20 * time.Millisecond
+	tests := []struct {
+		name       string
+		stillThere bool
+		want       []string
+	}{
+		{"target still there", true, []string{"move", "reverify", "click"}},
+		{"target gone after the hover", false, []string{"move", "reverify"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &Recorder{}
+			c := &Clicker{Actions: rec, Hover: hover}
+			c.StillThere = func() bool {
+				rec.Calls = append(rec.Calls, Call{Op: "reverify", At: time.Now()})
+				return tt.stillThere
+			}
+			c.Click("hover", 10, 10, 8, 8)
+
+			if got := ops(rec.Calls); !slices.Equal(got, tt.want) {
+				t.Fatalf("calls = %v, want %v", got, tt.want)
+			}
+			if gap := rec.Calls[1].At.Sub(rec.Calls[0].At); gap < hover {
+				t.Errorf("re-verified %v after the move, want at least %v", gap, hover)
+			}
+		})
+	}
+}
+
+func TestDrag(t *testing.T) {
+	rec := &Recorder{}
+	c := &Clicker{Actions: rec, OffsetX: 1920}
+	start, end := c.Drag("drag", 100, 200, 40, 20, 0, -150)
+
+	if start != image.Pt(2040, 210) || end != image.Pt(2040, 60) {
+		t.Errorf("Drag = %v -> %v, want (2040,210) -> (2040,60)", start, end)
+	}
+	want := []Call{
+		{Op: "move", X: 2040, Y: 210},
+		{Op: "toggle", Button: "left", Dir: "down"},
+		{Op: "move_smooth", X: 2040, Y: 60},
+		{Op: "toggle", Button: "left", Dir: "up"},
+	}
+	if len(rec.Calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", ops(rec.Calls), ops(want))
+	}
+	for i, call := range rec.Calls {
+		call.At = time.Time{}
+		if call != want[i] {
+			t.Errorf("call %d = %+v, want %+v", i, call, want[i])
+		}
+	}
+}
+
+func TestScroll(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines int
+		wheel int // robotgo convention: positive scrolls up
+	}{
+		{"down", 5, -5},
+		{"up", -3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &Recorder{}
+			c := &Clicker{Actions: rec, OffsetX: 100, OffsetY: 50}
+			c.Scroll("list", 300, 400, tt.lines)
+			if len(rec.Calls) != 2 {
+				t.Fatalf("calls = %v, want move and scroll", ops(rec.Calls))
+			}
+			if m := rec.Calls[0]; m.Op != "move" || m.X != 400 || m.Y != 450 {
+				t.Errorf("first call = %+v, want a move to (400,450)", m)
+			}
+			if s := rec.Calls[1]; s.Op != "scroll" || s.X != 0 || s.Y != tt.wheel {
+				t.Errorf("second call = %+v, want a scroll of %d", s, tt.wheel)
+			}
+		})
+	}
+}
+
+func TestClickKeyDryRun(t *testing.T) {
+	rec := &Recorder{}
+	var logged []string
+	c := &Clicker{Actions: rec, DryRun: true, LogFunc: func(msg string) { logged = append(logged, msg) }}
+	c.Click("dry", 10, 10, 8, 8)
+	c.Key("dry", "esc")
+	if len(rec.Calls) != 0 {
+		t.Errorf("dry run performed %v", ops(rec.Calls))
+	}
+	if len(logged) != 2 {
+		t.Errorf("logged %q, want one line per action", logged)
+	}
+}
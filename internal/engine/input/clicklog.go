@@ -0,0 +1,91 @@
+package input
+
+import (
+	"encoding/csv"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ClickRecord is one executed click, kept to check click accuracy after a run
+// (e.g. a systematic offset between the matched box and where the mouse went)
+type ClickRecord struct {
+	Time    time.Time
+	Name    string
+	Display int
+	Scale   float64         // Display scale factor (1 = 100%)
+	Target  image.Rectangle // Matched box, display-local
+	Center  image.Point     // Point clicked, display-local
+	Offset  image.Point     // Display origin added to reach global coordinates
+	Global  image.Point
+	DryRun  bool
+}
+
+// ClickLogHeader is the header row of the click log CSV
+var ClickLogHeader = []string{
+	"time", "name", "display", "scale",
+	"target_x", "target_y", "target_w", "target_h",
+	"center_x", "center_y", "offset_x", "offset_y", "global_x", "global_y", "dry_run",
+}
+
+// Row formats the record as a click log CSV row (see ClickLogHeader)
+func (r ClickRecord) Row() []string {
+	itoa := strconv.Itoa
+	return []string{
+		r.Time.Format(time.RFC3339Nano), r.Name, itoa(r.Display), strconv.FormatFloat(r.Scale, 'f', -1, 64),
+		itoa(r.Target.Min.X), itoa(r.Target.Min.Y), itoa(r.Target.Dx()), itoa(r.Target.Dy()),
+		itoa(r.Center.X), itoa(r.Center.Y), itoa(r.Offset.X), itoa(r.Offset.Y),
+		itoa(r.Global.X), itoa(r.Global.Y), strconv.FormatBool(r.DryRun),
+	}
+}
+
+// WriteClickRecords writes records as CSV rows to w, preceded by the header if header is set
+func WriteClickRecords(w io.Writer, header bool, records ...ClickRecord) error {
+	cw := csv.NewWriter(w)
+	if header {
+		cw.Write(ClickLogHeader)
+	}
+	for _, r := range records {
+		cw.Write(r.Row())
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ClickLog appends click records to a CSV file, writing the header when the file is new
+type ClickLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewClickLog returns a click log writing to path (created on the first record)
+func NewClickLog(path string) *ClickLog {
+	return &ClickLog{path: path}
+}
+
+// Record appends one click to the log
+func (l *ClickLog) Record(r ClickRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := WriteClickRecords(f, info.Size() == 0, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
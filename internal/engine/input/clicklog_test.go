@@ -0,0 +1,28 @@
+package input
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestClickRecordCSV(t *testing.T) {
+	var records []ClickRecord
+	c := &Clicker{Actions: &Recorder{}, OffsetX: 1920, OffsetY: -40, Display: 1, Scale: 1.25,
+		Record: func(r ClickRecord) { records = append(records, r) }}
+	c.Click("20.png", 100, 200, 40, 21)
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	records[0].Time = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := WriteClickRecords(&buf, true, records...); err != nil {
+		t.Fatal(err)
+	}
+	want := "time,name,display,scale,target_x,target_y,target_w,target_h,center_x,center_y,offset_x,offset_y,global_x,global_y,dry_run\n" +
+		"2024-05-01T12:00:00Z,20.png,1,1.25,100,200,40,21,120,210,1920,-40,2040,170,false\n"
+	if got := buf.String(); got != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,37 @@
+package input
+
+import "sync"
+
+// Gate pauses input while it is held, e.g. once per open dialog. Holds nest: input
+// resumes when the last one is released. Safe for concurrent use.
+type Gate struct {
+	mu    sync.Mutex
+	holds int
+}
+
+// Hold pauses input until release is called. Calling release again has no effect,
+// so it can be wired to callbacks that may fire twice.
+func (g *Gate) Hold() (release func()) {
+	g.mu.Lock()
+	g.holds++
+	g.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			g.holds--
+			g.mu.Unlock()
+		})
+	}
+}
+
+// Held reports whether any hold is active (a nil Gate is never held)
+func (g *Gate) Held() bool {
+	if g == nil {
+		return false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.holds > 0
+}
@@ -0,0 +1,36 @@
+package input
+
+import "testing"
+
+func TestGatePausesClicks(t *testing.T) {
+	rec := &Recorder{}
+	gate := &Gate{}
+	c := &Clicker{Actions: rec, Gate: gate}
+
+	closeFirst := gate.Hold()  // Dialog opened
+	closeSecond := gate.Hold() // A nested dialog (e.g. an error from the first)
+	c.Click("paused", 10, 10, 8, 8)
+	c.Scroll("paused", 10, 10, 3)
+	closeFirst()
+	closeFirst() // Hide and the close callback may both fire
+	if len(rec.Calls) != 0 {
+		t.Errorf("input while held: %v", ops(rec.Calls))
+	}
+	if !gate.Held() {
+		t.Error("releasing one hold twice released the other")
+	}
+
+	closeSecond()
+	if gate.Held() {
+		t.Error("gate still held after every hold was released")
+	}
+	c.Click("resumed", 10, 10, 8, 8)
+	if len(rec.Calls) != 2 {
+		t.Errorf("resumed click: calls = %v, want move and click", ops(rec.Calls))
+	}
+
+	var nilGate *Gate
+	if nilGate.Held() {
+		t.Error("a nil gate is held")
+	}
+}
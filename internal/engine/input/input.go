@@ -0,0 +1,43 @@
+package input
+
+import (
+	"github.com/go-vgo/robotgo"
+)
+
+// Actions performs mouse and keyboard input.
+// Bots talk to this instead of robotgo directly so input can be swapped out (e.g. for a mock).
+type Actions interface {
+	MoveMouse(x, y int)
+	MoveSmooth(x, y int) // Move in visible steps (for drags)
+	Click(button string)
+	Toggle(button, direction string) // direction is "down" or "up"
+	Scroll(x, y int)                 // Mouse wheel; positive y scrolls up (robotgo convention)
+	KeyTap(key string)
+}
+
+// Robot implements Actions using robotgo
+type Robot struct{}
+
+func (Robot) MoveMouse(x, y int) {
+	robotgo.MoveMouse(x, y)
+}
+
+func (Robot) MoveSmooth(x, y int) {
+	robotgo.MoveSmooth(x, y)
+}
+
+func (Robot) Click(button string) {
+	robotgo.Click(button)
+}
+
+func (Robot) Toggle(button, direction string) {
+	robotgo.Toggle(button, direction)
+}
+
+func (Robot) Scroll(x, y int) {
+	robotgo.Scroll(x, y)
+}
+
+func (Robot) KeyTap(key string) {
+	robotgo.KeyTap(key)
+}
@@ -0,0 +1,196 @@
+package input
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"time"
+)
+
+// Macro is a recorded sequence of clicks and key presses, replayed as is without
+// image matching (for deterministic steps). Click positions are display-local, so
+// a macro replays on whichever display is selected.
+type Macro struct {
+	Steps []MacroStep `json:"steps"`
+}
+
+// MacroStep is one click or key press, performed DelayMS after the previous step
+// (the first one after the start)
+type MacroStep struct {
+	DelayMS int    `json:"delay_ms"`
+	Op      string `json:"op"`            // "click" or "key"
+	X       int    `json:"x,omitempty"`   // click, display-local
+	Y       int    `json:"y,omitempty"`   // click, display-local
+	Key     string `json:"key,omitempty"` // key (robotgo.KeyTap name)
+}
+
+// Delay returns the wait before the step
+func (s MacroStep) Delay() time.Duration {
+	return time.Duration(s.DelayMS) * time.Millisecond
+}
+
+// Duration returns the total of the step delays
+func (m Macro) Duration() time.Duration {
+	var d time.Duration
+	for _, s := range m.Steps {
+		d += s.Delay()
+	}
+	return d
+}
+
+// Validate checks every step for an unknown op, a negative delay or position and a
+// missing key
+func (m Macro) Validate() error {
+	var errs []error
+	for i, s := range m.Steps {
+		if s.DelayMS < 0 {
+			errs = append(errs, fmt.Errorf("step %d: delay_ms must not be negative", i+1))
+		}
+		switch s.Op {
+		case "click":
+			if s.X < 0 || s.Y < 0 {
+				errs = append(errs, fmt.Errorf("step %d: click position must have x, y >= 0", i+1))
+			}
+		case "key":
+			if s.Key == "" {
+				errs = append(errs, fmt.Errorf("step %d: key step needs a key", i+1))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("step %d: unknown op %q (want click or key)", i+1, s.Op))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Marshal encodes the macro as indented JSON
+func (m Macro) Marshal() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// ParseMacro decodes and validates a JSON macro
+func ParseMacro(data []byte) (Macro, error) {
+	var m Macro
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, err
+	}
+	return m, m.Validate()
+}
+
+// LoadMacro reads a JSON macro file
+func LoadMacro(path string) (Macro, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Macro{}, err
+	}
+	m, err := ParseMacro(data)
+	if err != nil {
+		return m, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// SaveMacro writes the macro to path as JSON
+func SaveMacro(path string, m Macro) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Play performs the steps through c (its offset maps them onto the display),
+// waiting each step's delay with wait. It stops early when wait returns false
+// (e.g. the user cancelled) and returns the number of steps performed. Clicks go
+// through c.Click, so DryRun, Gate and Record apply as for any other click.
+func (m Macro) Play(c *Clicker, wait func(time.Duration) bool) int {
+	for i, s := range m.Steps {
+		if !wait(s.Delay()) {
+			return i
+		}
+		name := fmt.Sprintf("macro#%d", i+1)
+		switch s.Op {
+		case "click":
+			// A 0x0 size clicks exactly at the point
+			c.Click(name, s.X, s.Y, 0, 0)
+		case "key":
+			c.Key(name, s.Key)
+		}
+	}
+	return len(m.Steps)
+}
+
+// MacroRecorder builds a Macro from cursor positions polled while recording.
+// robotgo has no global mouse or keyboard hooks, so a click is recorded where the
+// cursor comes to rest (stays within Radius for Dwell) at a new spot; key presses
+// are added with Key. A step's delay runs up to when the cursor arrived, not to
+// when the rest was noticed, so a replay does not include the dwell.
+type MacroRecorder struct {
+	Origin image.Point   // Display origin, subtracted to store display-local positions
+	Dwell  time.Duration // Rest this long to record a click
+	Radius int           // Movement (px) that still counts as resting
+
+	macro      Macro
+	last       time.Time   // Time of the previous step (or the start)
+	rest       image.Point // Where the cursor is resting
+	restSince  time.Time
+	recorded   bool      // A click was recorded for the current rest
+	beforeRest time.Time // last before that click, to take it back
+	started    bool
+}
+
+// NewMacroRecorder starts a recording at start for the display at origin
+func NewMacroRecorder(origin image.Point, dwell time.Duration, radius int, start time.Time) *MacroRecorder {
+	return &MacroRecorder{Origin: origin, Dwell: dwell, Radius: radius, last: start}
+}
+
+// Observe takes the global cursor position sampled at now and returns true if it
+// completed a rest, recording a click there
+func (r *MacroRecorder) Observe(pos image.Point, now time.Time) bool {
+	d := pos.Sub(r.rest)
+	if !r.started || d.X*d.X+d.Y*d.Y > r.Radius*r.Radius {
+		r.started = true
+		r.rest, r.restSince, r.recorded = pos, now, false
+		return false
+	}
+	if r.recorded || now.Sub(r.restSince) < r.Dwell {
+		return false
+	}
+	r.recorded = true
+	r.beforeRest = r.last
+	local := r.rest.Sub(r.Origin)
+	r.add(MacroStep{Op: "click", X: local.X, Y: local.Y}, r.restSince)
+	return true
+}
+
+// Interrupt ends the current rest when the user turns to the recorder's own window
+// (e.g. it gains focus): the cursor rested there to click it, so a click recorded
+// for that rest is taken back, if it is still the last step. The next Observe
+// starts a new rest.
+func (r *MacroRecorder) Interrupt() {
+	if n := len(r.macro.Steps); r.started && r.recorded && n > 0 && r.macro.Steps[n-1].Op == "click" {
+		r.macro.Steps = r.macro.Steps[:n-1]
+		r.last = r.beforeRest
+	}
+	r.started = false
+}
+
+// Key records a key press at now
+func (r *MacroRecorder) Key(key string, now time.Time) {
+	r.add(MacroStep{Op: "key", Key: key}, now)
+}
+
+func (r *MacroRecorder) add(step MacroStep, at time.Time) {
+	step.DelayMS = int(max(at.Sub(r.last), 0) / time.Millisecond)
+	r.macro.Steps = append(r.macro.Steps, step)
+	r.last = at
+}
+
+// Macro returns the steps recorded so far
+func (r *MacroRecorder) Macro() Macro {
+	return Macro{Steps: append([]MacroStep(nil), r.macro.Steps...)}
+}
@@ -0,0 +1,122 @@
+package input
+
+import (
+	"image"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMacroSaveLoad(t *testing.T) {
+	m := Macro{Steps: []MacroStep{
+		{DelayMS: 500, Op: "click", X: 120, Y: 80},
+		{DelayMS: 250, Op: "key", Key: "esc"},
+	}}
+	path := filepath.Join(t.TempDir(), "macro.json")
+	if err := SaveMacro(path, m); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadMacro(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, m) {
+		t.Errorf("LoadMacro = %+v, want %+v", loaded, m)
+	}
+}
+
+func TestParseMacroValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"valid", `{"steps": [{"op": "click", "x": 1, "y": 2}, {"op": "key", "key": "f5"}]}`, false},
+		{"empty", `{"steps": []}`, false},
+		{"unknown op", `{"steps": [{"op": "drag"}]}`, true},
+		{"key without a key", `{"steps": [{"op": "key"}]}`, true},
+		{"negative delay", `{"steps": [{"op": "click", "delay_ms": -1}]}`, true},
+		{"negative position", `{"steps": [{"op": "click", "x": -5}]}`, true},
+		{"not JSON", `steps`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseMacro([]byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Errorf("ParseMacro error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMacroPlay(t *testing.T) {
+	m := Macro{Steps: []MacroStep{
+		{DelayMS: 30, Op: "click", X: 10, Y: 20},
+		{DelayMS: 60, Op: "key", Key: "space"},
+		{DelayMS: 0, Op: "click", X: 5, Y: 5},
+	}}
+	rec := &Recorder{}
+	c := &Clicker{Actions: rec, OffsetX: 100, OffsetY: 50}
+
+	// Each step waits its delay first
+	var waits []time.Duration
+	played := m.Play(c, func(d time.Duration) bool {
+		waits = append(waits, d)
+		return true
+	})
+	if want := []time.Duration{30 * time.Millisecond, 60 * time.Millisecond, 0}; !reflect.DeepEqual(waits, want) {
+		t.Errorf("waits = %v, want %v", waits, want)
+	}
+	if played != 3 || len(rec.Calls) != 5 {
+		t.Errorf("played %d steps with %d calls, want 3 and 5", played, len(rec.Calls))
+	}
+	// Clicks land at the display offset
+	if first := rec.Calls[0]; first.X != 110 || first.Y != 70 {
+		t.Errorf("first click at (%d,%d), want (110,70)", first.X, first.Y)
+	}
+
+	rec = &Recorder{}
+	c.Actions = rec
+	start := time.Now()
+	m.Play(c, func(d time.Duration) bool { time.Sleep(d); return true })
+	if rec.Calls[0].At.Sub(start) < 30*time.Millisecond || rec.Calls[2].At.Sub(rec.Calls[1].At) < 60*time.Millisecond {
+		t.Error("steps were not spaced by their delays")
+	}
+
+	// A cancelled wait ends the replay
+	waited := 0
+	if n := m.Play(c, func(time.Duration) bool { waited++; return waited < 3 }); n != 2 {
+		t.Errorf("cancelled before the third step: played %d, want 2", n)
+	}
+}
+
+func TestMacroRecorder(t *testing.T) {
+	t0 := time.Unix(0, 0)
+	at := func(ms int) time.Time { return t0.Add(time.Duration(ms) * time.Millisecond) }
+	r := NewMacroRecorder(image.Pt(100, 50), time.Second, 3, t0)
+
+	for ms := 0; ms < 2000; ms += 100 {
+		r.Observe(image.Pt(ms/10, ms/20), at(ms)) // Moving
+	}
+	for ms := 2000; ms <= 3500; ms += 100 {
+		r.Observe(image.Pt(300+ms%2, 200), at(ms)) // Resting (1px jitter)
+	}
+	r.Key("esc", at(4000))
+	// A rest on the recorder's own window, taken back when it is focused
+	for ms := 5000; ms <= 6500; ms += 100 {
+		r.Observe(image.Pt(500, 500), at(ms))
+	}
+	if n := len(r.Macro().Steps); n != 3 {
+		t.Fatalf("recorded %d steps before the interrupt, want 3", n)
+	}
+	r.Interrupt()
+
+	// The click is display-local and delayed to when the cursor arrived
+	want := []MacroStep{
+		{DelayMS: 2000, Op: "click", X: 200, Y: 150},
+		{DelayMS: 2000, Op: "key", Key: "esc"},
+	}
+	if got := r.Macro().Steps; !reflect.DeepEqual(got, want) {
+		t.Errorf("steps = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,16 @@
+package input
+
+import (
+	"math/rand"
+	"time"
+)
+
+// NewRand returns the random source every randomized input behavior (click offsets,
+// timing jitter) draws from, so a run can be replayed by reusing its seed.
+// A zero seed picks one from the clock; the seed actually used is returned for logging.
+func NewRand(seed int64) (*rand.Rand, int64) {
+	for seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed)), seed
+}
@@ -0,0 +1,19 @@
+package input
+
+import "testing"
+
+func TestNewRandSeed(t *testing.T) {
+	a, seed := NewRand(42)
+	b, _ := NewRand(42)
+	if seed != 42 {
+		t.Errorf("NewRand(42) reported seed %d", seed)
+	}
+	for i := 0; i < 100; i++ {
+		if a.Int63() != b.Int63() || a.Float64() != b.Float64() {
+			t.Fatalf("sequences from the same seed differ at %d", i)
+		}
+	}
+	if _, picked := NewRand(0); picked == 0 {
+		t.Error("NewRand(0) did not pick a seed")
+	}
+}
@@ -0,0 +1,43 @@
+package input
+
+import "time"
+
+// Call is one input action recorded by a Recorder
+type Call struct {
+	Op     string // "move", "move_smooth", "click", "toggle", "scroll" or "key"
+	X, Y   int    // move, move_smooth, scroll
+	Button string // click, toggle
+	Dir    string // toggle: "down" or "up"
+	Key    string // key
+	At     time.Time
+}
+
+// Recorder is an Actions mock that records every call instead of performing it.
+// Use it with SetActions to drive a bot or a Clicker without touching the real mouse.
+type Recorder struct {
+	Calls []Call
+}
+
+func (r *Recorder) MoveMouse(x, y int) {
+	r.Calls = append(r.Calls, Call{Op: "move", X: x, Y: y, At: time.Now()})
+}
+
+func (r *Recorder) MoveSmooth(x, y int) {
+	r.Calls = append(r.Calls, Call{Op: "move_smooth", X: x, Y: y, At: time.Now()})
+}
+
+func (r *Recorder) Click(button string) {
+	r.Calls = append(r.Calls, Call{Op: "click", Button: button, At: time.Now()})
+}
+
+func (r *Recorder) Toggle(button, direction string) {
+	r.Calls = append(r.Calls, Call{Op: "toggle", Button: button, Dir: direction, At: time.Now()})
+}
+
+func (r *Recorder) Scroll(x, y int) {
+	r.Calls = append(r.Calls, Call{Op: "scroll", X: x, Y: y, At: time.Now()})
+}
+
+func (r *Recorder) KeyTap(key string) {
+	r.Calls = append(r.Calls, Call{Op: "key", Key: key, At: time.Now()})
+}
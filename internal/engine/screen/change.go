@@ -0,0 +1,28 @@
+package screen
+
+import (
+	"image"
+)
+
+// RegionChange returns the fraction (0-1) of pixels inside r whose color differs
+// between before and after by more than tolerance. It is used to verify a click
+// when there is no template for the expected result: "something changed where I clicked".
+// r is clamped to both images; an empty region reports no change.
+func RegionChange(before, after image.Image, r image.Rectangle, tolerance float64) float64 {
+	r = r.Intersect(before.Bounds()).Intersect(after.Bounds())
+	if r.Empty() {
+		return 0
+	}
+
+	changed := 0
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			br, bg, bb, _ := rawPixel(before, x, y)
+			ar, ag, ab, _ := rawPixel(after, x, y)
+			if !colorSimilar(br, bg, bb, ar, ag, ab, tolerance) {
+				changed++
+			}
+		}
+	}
+	return float64(changed) / float64(r.Dx()*r.Dy())
+}
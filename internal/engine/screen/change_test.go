@@ -0,0 +1,30 @@
+package screen
+
+import (
+	"image"
+	"math"
+	"testing"
+)
+
+func TestRegionChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		changed image.Rectangle
+		want    float64 // Percent of the region
+	}{
+		{"unchanged (change outside the region)", image.Rect(40, 0, 41, 1), 0},
+		{"half changed", image.Rect(10, 10, 30, 20), 50},
+		{"fully changed", image.Rect(5, 5, 35, 35), 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := newScreen(60, 40)
+			after := newScreen(60, 40)
+			paste(after, newTemplate(tt.changed.Dx(), tt.changed.Dy(), 0), tt.changed.Min.X, tt.changed.Min.Y)
+			got := RegionChange(before, after, image.Rect(10, 10, 30, 30), tol) * 100
+			if math.Abs(got-tt.want) > 0.5 {
+				t.Errorf("RegionChange = %.1f%%, want %.0f%%", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package screen
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/kbinani/screenshot"
+)
+
+// DisplayID returns a stable identifier for a monitor: its resolution and desktop
+// position, e.g. "1920x1080@0,0". Display indices shuffle when monitors are
+// re-plugged; the id follows the physical arrangement instead.
+func DisplayID(bounds image.Rectangle) string {
+	return fmt.Sprintf("%dx%d@%d,%d", bounds.Dx(), bounds.Dy(), bounds.Min.X, bounds.Min.Y)
+}
+
+// ActiveDisplays returns the bounds of every active display, by index
+func ActiveDisplays() []image.Rectangle {
+	n := screenshot.NumActiveDisplays()
+	displays := make([]image.Rectangle, n)
+	for i := range displays {
+		displays[i] = screenshot.GetDisplayBounds(i)
+	}
+	return displays
+}
+
+// LocateDisplay returns the index of the first frame (one per display) on which any
+// of templates is found, and which template matched. Used to find the display the game runs on.
+func (s *Searcher) LocateDisplay(frames, templates []image.Image, tolerance float64) (display, template int, found bool) {
+	for d, frame := range frames {
+		if frame == nil {
+			continue // Display could not be captured
+		}
+		if t, _, ok := s.FindAny(frame, templates, tolerance); ok {
+			return d, t, true
+		}
+	}
+	return -1, -1, false
+}
+
+// ScaleY scales a y coordinate tuned on a display refHeight pixels tall to one height pixels tall
+func ScaleY(y, refHeight, height int) int {
+	if refHeight <= 0 || height <= 0 {
+		return y
+	}
+	return y * height / refHeight
+}
+
+// ResolveDisplay maps a stable id back to its current index in displays.
+// An exact match (resolution and position) wins; otherwise a display with the same
+// resolution is used if it is the only one (the monitor was moved in the arrangement).
+func ResolveDisplay(id string, displays []image.Rectangle) (int, bool) {
+	var w, h, x, y int
+	if _, err := fmt.Sscanf(id, "%dx%d@%d,%d", &w, &h, &x, &y); err != nil {
+		return 0, false
+	}
+
+	sameSize := -1
+	for i, b := range displays {
+		if b.Dx() != w || b.Dy() != h {
+			continue
+		}
+		if b.Min.X == x && b.Min.Y == y {
+			return i, true
+		}
+		if sameSize >= 0 {
+			sameSize = len(displays) // Ambiguous
+		} else {
+			sameSize = i
+		}
+	}
+	if sameSize >= 0 && sameSize < len(displays) {
+		return sameSize, true
+	}
+	return 0, false
+}
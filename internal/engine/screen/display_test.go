@@ -0,0 +1,76 @@
+package screen
+
+import (
+	"image"
+	"testing"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+func TestResolveDisplay(t *testing.T) {
+	left, right := image.Rect(0, 0, 1920, 1080), image.Rect(1920, 0, 4480, 1440)
+	tests := []struct {
+		name     string
+		bounds   image.Rectangle
+		displays []image.Rectangle
+		index    int
+		ok       bool
+	}{
+		{"same arrangement", right, []image.Rectangle{left, right}, 1, true},
+		{"indices swapped", right, []image.Rectangle{right, left}, 0, true},
+		{"monitor moved", right, []image.Rectangle{left, image.Rect(-2560, 0, 0, 1440)}, 1, true},
+		{"unplugged", right, []image.Rectangle{left}, 0, false},
+		{"ambiguous resolution", image.Rect(0, 0, 1920, 1080),
+			[]image.Rectangle{image.Rect(1920, 0, 3840, 1080), image.Rect(-1920, 0, 0, 1080)}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, ok := ResolveDisplay(DisplayID(tt.bounds), tt.displays)
+			if index != tt.index || ok != tt.ok {
+				t.Errorf("ResolveDisplay = %d, %v; want %d, %v", index, ok, tt.index, tt.ok)
+			}
+		})
+	}
+}
+
+func TestScaleY(t *testing.T) {
+	tests := []struct {
+		height, want int
+	}{
+		{1080, 950},
+		{1440, 1266},
+		{2160, 1900},
+	}
+	for _, tt := range tests {
+		if got := ScaleY(constants.EntryMaxY, constants.EntryMaxYRefHeight, tt.height); got != tt.want {
+			t.Errorf("ScaleY(%d) = %d, want %d", tt.height, got, tt.want)
+		}
+	}
+}
+
+func TestLocateDisplay(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	marker := newTemplate(10, 6, 40)
+	game := newScreen(60, 40)
+	paste(game, tpl, 30, 20)
+
+	tests := []struct {
+		name     string
+		frames   []image.Image
+		display  int
+		template int
+		found    bool
+	}{
+		{"game on the secondary display", []image.Image{newScreen(80, 50), game}, 1, 1, true},
+		{"uncapturable display skipped", []image.Image{nil, newScreen(60, 40), game}, 2, 1, true},
+		{"not found", []image.Image{newScreen(60, 40), newScreen(60, 40)}, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, i, found := NewSearcher().LocateDisplay(tt.frames, []image.Image{marker, tpl}, tol)
+			if found != tt.found || (found && (d != tt.display || i != tt.template)) {
+				t.Errorf("LocateDisplay = %d, %d, %v; want %d, %d, %v", d, i, found, tt.display, tt.template, tt.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package screen
+
+import (
+	"image"
+	"time"
+)
+
+// FreezeDetector notices a frozen game: frames that stop changing while none of
+// the templates the bot expects turns up, for longer than Timeout. A game that is
+// only waiting (e.g. a static lobby) still shows its expected template, so it never
+// counts as frozen. Like IdleDetector it does not block: the loop calls Observe once
+// per tick.
+type FreezeDetector struct {
+	Timeout   time.Duration // Frozen this long before Observe reports it
+	Threshold float64       // Fraction of changed pixels that counts as a change
+	Tolerance float64       // Per-pixel color tolerance
+
+	last     image.Image
+	since    time.Time // Last change or expected template (or the last report)
+	triggers int       // Reports since the screen last changed or showed an expected template
+}
+
+// NewFreezeDetector creates a detector that reports after timeout frozen
+func NewFreezeDetector(timeout time.Duration, threshold, tolerance float64) *FreezeDetector {
+	return &FreezeDetector{Timeout: timeout, Threshold: threshold, Tolerance: tolerance}
+}
+
+// Observe records frame, taken at now; expected is whether the scan of that frame
+// found a template the bot was looking for. It returns true when the screen has
+// been frozen for Timeout. The period then restarts, so a freeze that persists
+// (e.g. through a recovery attempt) is reported again after another Timeout.
+func (d *FreezeDetector) Observe(frame image.Image, expected bool, now time.Time) bool {
+	if expected || d.Changed(frame) {
+		d.last = frame
+		d.since = now
+		d.triggers = 0
+		return false
+	}
+	if now.Sub(d.since) < d.Timeout {
+		return false
+	}
+	d.since = now
+	d.triggers++
+	return true
+}
+
+// Changed reports whether frame differs from the last observed frame (true if
+// there is none), without recording it
+func (d *FreezeDetector) Changed(frame image.Image) bool {
+	return d.last == nil || !frame.Bounds().Eq(d.last.Bounds()) ||
+		RegionChange(d.last, frame, frame.Bounds(), d.Tolerance) > d.Threshold
+}
+
+// Triggers returns how often Observe has reported this freeze
+func (d *FreezeDetector) Triggers() int {
+	return d.triggers
+}
+
+// Reset forgets the previous frame (e.g. on start)
+func (d *FreezeDetector) Reset() {
+	d.last = nil
+	d.triggers = 0
+}
@@ -0,0 +1,45 @@
+package screen
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+func TestFreezeDetector(t *testing.T) {
+	frozen := newScreen(64, 32)
+	paste(frozen, newTemplate(8, 8, 0), 10, 10)
+	changed := newScreen(64, 32)
+	paste(changed, newTemplate(8, 8, 0), 40, 10)
+	start := time.Unix(0, 0)
+	at := func(tick int) time.Time { return start.Add(time.Duration(tick) * time.Second) }
+
+	// Identical frames one second apart with nothing expected found trigger every timeout
+	d := NewFreezeDetector(10*time.Second, constants.IdleChangeThreshold, tol)
+	var triggered []int
+	for tick := 0; tick <= 35; tick++ {
+		if d.Observe(frozen, false, at(tick)) {
+			triggered = append(triggered, tick)
+			if d.Triggers() != len(triggered) {
+				t.Errorf("tick %d: Triggers = %d, want %d", tick, d.Triggers(), len(triggered))
+			}
+		}
+	}
+	if len(triggered) != 3 || triggered[0] != 10 || triggered[1] != 20 || triggered[2] != 30 {
+		t.Errorf("triggered at %v, want [10 20 30]", triggered)
+	}
+	// A changed frame clears the count
+	d.Observe(changed, false, at(36))
+	if d.Triggers() != 0 {
+		t.Errorf("Triggers after a change = %d, want 0", d.Triggers())
+	}
+
+	// A static screen that shows an expected template is only waiting
+	d = NewFreezeDetector(10*time.Second, constants.IdleChangeThreshold, tol)
+	for tick := 0; tick <= 35; tick++ {
+		if d.Observe(frozen, true, at(tick)) {
+			t.Fatalf("tick %d: triggered while the expected template was found", tick)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package screen
+
+import (
+	"image"
+	"math/bits"
+)
+
+// hashSize is the side of the grid an image region is reduced to for AverageHash (8x8 = 64 bits)
+const hashSize = 8
+
+// AverageHash computes a 64-bit average hash (aHash) of region r of img: the region is
+// reduced to an 8x8 grid of mean luma values and each bit is set where a cell is brighter
+// than the overall mean. Small visual changes flip few bits, so two regions can be compared
+// with HammingDistance far faster than a sliding-window scan.
+// Returns false if r is smaller than 8x8 or not inside img.
+func AverageHash(img image.Image, r image.Rectangle) (uint64, bool) {
+	if r.Dx() < hashSize || r.Dy() < hashSize || !r.In(img.Bounds()) {
+		return 0, false
+	}
+
+	var cells [hashSize * hashSize]float64
+	var total float64
+	for cy := 0; cy < hashSize; cy++ {
+		y0 := r.Min.Y + cy*r.Dy()/hashSize
+		y1 := r.Min.Y + (cy+1)*r.Dy()/hashSize
+		for cx := 0; cx < hashSize; cx++ {
+			x0 := r.Min.X + cx*r.Dx()/hashSize
+			x1 := r.Min.X + (cx+1)*r.Dx()/hashSize
+
+			var sum uint32
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					pr, pg, pb, _ := rawPixel(img, x, y)
+					sum += luma(pr, pg, pb)
+				}
+			}
+			v := float64(sum) / float64((x1-x0)*(y1-y0))
+			cells[cy*hashSize+cx] = v
+			total += v
+		}
+	}
+
+	mean := total / float64(len(cells))
+	var hash uint64
+	for i, v := range cells {
+		if v > mean {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash, true
+}
+
+// TemplateHash returns the AverageHash of a whole template.
+// Templates with transparent pixels are not hashable: their wildcard pixels would
+// be compared against whatever is on screen.
+func TemplateHash(tpl image.Image) (uint64, bool) {
+	b := tpl.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := rawPixel(tpl, x, y); a < 255 {
+				return 0, false
+			}
+		}
+	}
+	return AverageHash(tpl, b)
+}
+
+// HammingDistance returns the number of differing bits between two hashes
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
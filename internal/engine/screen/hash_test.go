@@ -0,0 +1,54 @@
+package screen
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+func TestAverageHash(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(scr *image.RGBA)
+		match  bool
+	}{
+		{"unchanged", func(*image.RGBA) {}, true},
+		{"small change under the threshold", func(scr *image.RGBA) {
+			for y := 20; y < 22; y++ {
+				for x := 20; x < 22; x++ {
+					scr.SetRGBA(x, y, color.RGBA{255, 255, 255, 255})
+				}
+			}
+		}, true},
+		{"large change over the threshold", func(scr *image.RGBA) {
+			for y := 5; y < 37; y++ {
+				for x := 10; x < 26; x++ {
+					scr.SetRGBA(x, y, background)
+				}
+			}
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl := newTemplate(32, 32, 0)
+			scr := newScreen(60, 40)
+			paste(scr, tpl, 10, 5)
+			tt.modify(scr)
+
+			tplHash, ok := TemplateHash(tpl)
+			if !ok {
+				t.Fatal("TemplateHash failed")
+			}
+			regionHash, ok := AverageHash(scr, image.Rect(10, 5, 42, 37))
+			if !ok {
+				t.Fatal("AverageHash failed")
+			}
+			d := HammingDistance(tplHash, regionHash)
+			if got := d <= constants.HashMaxDistance; got != tt.match {
+				t.Errorf("distance %d: match = %v, want %v", d, got, tt.match)
+			}
+		})
+	}
+}
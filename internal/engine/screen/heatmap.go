@@ -0,0 +1,75 @@
+package screen
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+// MatchHeatmap scores the template at every candidate position of screenImg and
+// returns a grayscale image where pixel (x, y) is the score of the template placed
+// with its top-left corner there: bright = close match, dark = far off.
+// The score is the mean color diff over a grid sample of up to HeatmapSamples opaque
+// template pixels, contrast-stretched between the best and worst position.
+func (s *Searcher) MatchHeatmap(screenImg, templateImg image.Image) *image.Gray {
+	sBounds := screenImg.Bounds()
+	tBounds := templateImg.Bounds()
+	tWidth, tHeight := tBounds.Dx(), tBounds.Dy()
+	w, h := sBounds.Dx()-tWidth+1, sBounds.Dy()-tHeight+1
+	if w <= 0 || h <= 0 {
+		return image.NewGray(image.Rectangle{})
+	}
+	getRgbAndAlpha := s.pixelGetter()
+
+	// Sample the opaque template pixels on a grid so large templates stay tractable
+	type px struct {
+		dx, dy  int
+		r, g, b uint32
+	}
+	step := int(math.Ceil(math.Sqrt(float64(tWidth*tHeight) / constants.HeatmapSamples)))
+	if step < 1 {
+		step = 1
+	}
+	var tpx []px
+	for ty := 0; ty < tHeight; ty += step {
+		for tx := 0; tx < tWidth; tx += step {
+			r, g, b, a := getRgbAndAlpha(templateImg, tBounds.Min.X+tx, tBounds.Min.Y+ty)
+			if a > 0 {
+				tpx = append(tpx, px{tx, ty, r, g, b})
+			}
+		}
+	}
+
+	out := image.NewGray(image.Rect(0, 0, w, h))
+	if len(tpx) == 0 {
+		return out
+	}
+
+	scores := make([]float64, w*h)
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			sum := 0.0
+			for _, p := range tpx {
+				sr, sg, sb, _ := getRgbAndAlpha(screenImg, sBounds.Min.X+x+p.dx, sBounds.Min.Y+y+p.dy)
+				sum += math.Sqrt(float64((sr-p.r)*(sr-p.r) + (sg-p.g)*(sg-p.g) + (sb-p.b)*(sb-p.b)))
+			}
+			score := sum / float64(len(tpx))
+			scores[y*w+x] = score
+			lo = math.Min(lo, score)
+			hi = math.Max(hi, score)
+		}
+	}
+
+	span := hi - lo
+	for i, score := range scores {
+		v := 255.0
+		if span > 0 {
+			v = 255 * (1 - (score-lo)/span)
+		}
+		out.SetGray(i%w, i/w, color.Gray{Y: uint8(v)})
+	}
+	return out
+}
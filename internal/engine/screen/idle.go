@@ -0,0 +1,64 @@
+package screen
+
+import (
+	"image"
+	"time"
+)
+
+// IdleDetector notices when consecutive frames stop changing, so a long wait
+// (e.g. the lobby) can scan less often until something happens on screen.
+// It does not block: the state handler calls Observe once per tick and passes
+// its normal interval through Interval.
+type IdleDetector struct {
+	Frames    int     // Consecutive unchanged frames before going idle
+	Factor    int     // Interval multiplier while idle
+	Threshold float64 // Fraction of changed pixels that counts as a change
+	Tolerance float64 // Per-pixel color tolerance
+
+	last      image.Image
+	unchanged int
+	idle      bool
+}
+
+// NewIdleDetector creates a detector that goes idle after frames unchanged frames
+func NewIdleDetector(frames, factor int, threshold, tolerance float64) *IdleDetector {
+	return &IdleDetector{Frames: frames, Factor: factor, Threshold: threshold, Tolerance: tolerance}
+}
+
+// Observe compares frame with the previous one. It returns whether the detector
+// is idle and whether that just changed (entered or left idle) with this frame.
+func (d *IdleDetector) Observe(frame image.Image) (idle, toggled bool) {
+	changed := d.last == nil || !frame.Bounds().Eq(d.last.Bounds()) ||
+		RegionChange(d.last, frame, frame.Bounds(), d.Tolerance) > d.Threshold
+	d.last = frame
+
+	wasIdle := d.idle
+	if changed {
+		d.unchanged = 0
+		d.idle = false
+	} else {
+		d.unchanged++
+		d.idle = d.unchanged >= d.Frames
+	}
+	return d.idle, d.idle != wasIdle
+}
+
+// Idle reports whether the last observed frames were unchanged long enough
+func (d *IdleDetector) Idle() bool {
+	return d.idle
+}
+
+// Interval stretches the normal interval while idle
+func (d *IdleDetector) Interval(normal time.Duration) time.Duration {
+	if d.idle && d.Factor > 1 {
+		return normal * time.Duration(d.Factor)
+	}
+	return normal
+}
+
+// Reset forgets the previous frame (e.g. on a state change)
+func (d *IdleDetector) Reset() {
+	d.last = nil
+	d.unchanged = 0
+	d.idle = false
+}
@@ -0,0 +1,39 @@
+package screen
+
+import (
+	"image"
+	"testing"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+func TestIdleDetector(t *testing.T) {
+	d := NewIdleDetector(constants.IdleFrames, constants.IdleIntervalFactor, constants.IdleChangeThreshold, tol)
+	still := newScreen(60, 40)
+	changed := newScreen(60, 40)
+	paste(changed, newTemplate(8, 8, 0), 20, 20)
+
+	slow := time.Duration(constants.IdleIntervalFactor) * time.Second
+	steps := []struct {
+		frame    *image.RGBA
+		idle     bool
+		interval time.Duration
+	}{
+		{still, false, time.Second},
+		{still, false, time.Second},
+		{still, false, time.Second},
+		{still, true, slow},
+		{changed, false, time.Second},
+		{changed, false, time.Second},
+	}
+	for i, step := range steps {
+		idle, _ := d.Observe(step.frame)
+		if idle != step.idle {
+			t.Errorf("frame %d: idle = %v, want %v", i, idle, step.idle)
+		}
+		if got := d.Interval(time.Second); got != step.interval {
+			t.Errorf("frame %d: Interval = %v, want %v", i, got, step.interval)
+		}
+	}
+}
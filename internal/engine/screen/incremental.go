@@ -0,0 +1,159 @@
+package screen
+
+import (
+	"image"
+	"sort"
+)
+
+// IncrementalMatcher keeps each template's matches across frames and rescans only
+// around the blocks that changed since the previous frame (a coarse block diff).
+// Matches in unchanged areas are kept as they were, so a mostly static screen with
+// a small animated area costs a fraction of a full scan.
+//
+// Call Next once per frame, then FindAll per template. A template not searched on
+// the previous frame, or a frame of a different size, gets a full scan.
+type IncrementalMatcher struct {
+	Block     int     // Block size (px) of the diff
+	Tolerance float64 // Per-pixel color tolerance of the diff (one differing pixel changes a block)
+
+	searcher *Searcher
+	frame    image.Image
+	seq      int               // Frames seen
+	changed  []image.Rectangle // Changed areas of the current frame (merged block runs)
+	full     bool              // The current frame must be scanned in full
+	blocks   int               // Blocks of the current frame
+	dirty    int               // Changed blocks of the current frame
+	cache    map[incrementalKey]incrementalMatches
+}
+
+type incrementalKey struct {
+	template  image.Image
+	tolerance float64
+}
+
+type incrementalMatches struct {
+	seq    int // Frame the matches are for
+	points []image.Point
+}
+
+// NewIncrementalMatcher creates an incremental matcher that searches with s
+func NewIncrementalMatcher(s *Searcher, block int, tolerance float64) *IncrementalMatcher {
+	return &IncrementalMatcher{Block: block, Tolerance: tolerance, searcher: s}
+}
+
+// Next diffs frame against the previous frame and makes it the frame FindAll searches
+func (m *IncrementalMatcher) Next(frame image.Image) {
+	prev := m.frame
+	m.frame = frame
+	m.seq++
+	m.changed = nil
+	m.full = prev == nil || !prev.Bounds().Eq(frame.Bounds())
+
+	b := frame.Bounds()
+	size := max(m.Block, 1)
+	m.blocks = ((b.Dx() + size - 1) / size) * ((b.Dy() + size - 1) / size)
+	if m.full {
+		m.dirty = m.blocks
+		return
+	}
+
+	// Changed blocks, merged into horizontal runs per block row
+	m.dirty = 0
+	for y := b.Min.Y; y < b.Max.Y; y += size {
+		run := image.Rectangle{}
+		for x := b.Min.X; x < b.Max.X; x += size {
+			block := image.Rect(x, y, x+size, y+size).Intersect(b)
+			if !blockChanged(prev, frame, block, m.Tolerance) {
+				if !run.Empty() {
+					m.changed = append(m.changed, run)
+					run = image.Rectangle{}
+				}
+				continue
+			}
+			m.dirty++
+			run = run.Union(block)
+		}
+		if !run.Empty() {
+			m.changed = append(m.changed, run)
+		}
+	}
+}
+
+// Changed reports how many blocks of the current frame changed (or all of them on a
+// full scan) out of the total
+func (m *IncrementalMatcher) Changed() (changed, total int) {
+	return m.dirty, m.blocks
+}
+
+// FindAll returns the matches of templateImg on the current frame (top-left, in scan
+// order): the previous frame's matches clear of every changed area, plus a rescan of
+// each changed area widened by the template size.
+func (m *IncrementalMatcher) FindAll(templateImg image.Image, tolerance float64) []image.Point {
+	if m.frame == nil {
+		return nil
+	}
+	if m.cache == nil {
+		m.cache = make(map[incrementalKey]incrementalMatches)
+	}
+	key := incrementalKey{template: templateImg, tolerance: tolerance}
+	prev, ok := m.cache[key]
+	if m.full || !ok || prev.seq != m.seq-1 {
+		points := m.searcher.FindAllTemplates(m.frame, templateImg, tolerance)
+		m.cache[key] = incrementalMatches{seq: m.seq, points: points}
+		return points
+	}
+
+	size := templateImg.Bounds().Size()
+	var points []image.Point
+	for _, p := range prev.points {
+		if !touchesAny(image.Rectangle{Min: p, Max: p.Add(size)}, m.changed) {
+			points = append(points, p)
+		}
+	}
+	// A match overlapping a changed block can start up to a template size before it
+	grow := image.Rectangle{Min: image.Point{X: 1 - size.X, Y: 1 - size.Y}, Max: image.Point{X: size.X - 1, Y: size.Y - 1}}
+	for _, area := range m.changed {
+		roi := image.Rectangle{Min: area.Min.Add(grow.Min), Max: area.Max.Add(grow.Max)}
+		points = append(points, m.searcher.FindAllTemplatesInROI(m.frame, templateImg, roi, tolerance)...)
+	}
+
+	// Back to scan order, then drop duplicates found by overlapping rescans
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].Y != points[j].Y {
+			return points[i].Y < points[j].Y
+		}
+		return points[i].X < points[j].X
+	})
+	points = dedupMatches(points, size.X, size.Y)
+	m.cache[key] = incrementalMatches{seq: m.seq, points: points}
+	return points
+}
+
+// Reset forgets the previous frame and every cached match
+func (m *IncrementalMatcher) Reset() {
+	m.frame = nil
+	m.cache = nil
+}
+
+// blockChanged reports whether any pixel of r differs between a and b by more than tolerance
+func blockChanged(a, b image.Image, r image.Rectangle, tolerance float64) bool {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			ar, ag, ab, _ := rawPixel(a, x, y)
+			br, bg, bb, _ := rawPixel(b, x, y)
+			if !colorSimilar(ar, ag, ab, br, bg, bb, tolerance) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func touchesAny(r image.Rectangle, areas []image.Rectangle) bool {
+	for _, a := range areas {
+		if r.Overlaps(a) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package screen
+
+import (
+	"image"
+	"slices"
+	"testing"
+)
+
+func TestIncrementalMatcher(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	first := newScreen(64, 48)
+	paste(first, tpl, 4, 4)   // Static
+	paste(first, tpl, 40, 28) // Moves up within one block column
+	second := newScreen(64, 48)
+	paste(second, tpl, 4, 4)
+	paste(second, tpl, 40, 8)
+
+	s := NewSearcher()
+	m := NewIncrementalMatcher(s, 16, 10)
+	m.Next(first)
+	if got := m.FindAll(tpl, tol); len(got) != 2 {
+		t.Fatalf("first frame: FindAll = %v, want 2 matches", got)
+	}
+	s.TakeMatchCount()
+
+	m.Next(second)
+	got := m.FindAll(tpl, tol)
+	if want := []image.Point{{4, 4}, {40, 8}}; !slices.Equal(got, want) {
+		t.Errorf("second frame: FindAll = %v, want %v", got, want)
+	}
+	// Only the blocks the button left and entered are rescanned; the static one is kept
+	if changed, total := m.Changed(); changed != 3 || total != 12 {
+		t.Errorf("Changed = %d/%d blocks, want 3/12", changed, total)
+	}
+	if n := s.TakeMatchCount(); n != 1 {
+		t.Errorf("rescan found %d matches, want 1", n)
+	}
+}
@@ -0,0 +1,45 @@
+package screen
+
+import (
+	"image"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+// Matcher decides whether templateImg matches screenImg with its top-left at `at`.
+// The score is for logging only (for PixelMatcher: the fraction of failed pixels,
+// 0 = perfect). Strategies plug into a Searcher through SetMatcher.
+type Matcher interface {
+	Match(screenImg, templateImg image.Image, at image.Point, tolerance float64) (bool, float64)
+}
+
+// PixelMatcher is the default Matcher: per-pixel RGB distance after the mode's
+// preprocessing, allowing up to constants.MaxFailRate of the opaque pixels to miss.
+// Scans with a PixelMatcher also use quick-reject probes.
+type PixelMatcher struct {
+	Mode MatchMode
+}
+
+// Match implements Matcher
+func (m PixelMatcher) Match(screenImg, templateImg image.Image, at image.Point, tolerance float64) (bool, float64) {
+	result := match(screenImg, templateImg, at.X, at.Y, tolerance, m.Mode, pixelGetterFor(m.Mode), templateImg.Bounds(), constants.MaxFailRate)
+	return result.matched, result.failRate
+}
+
+// Matcher returns the strategy used to evaluate scan candidates
+func (s *Searcher) Matcher() Matcher {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.matcher == nil {
+		return PixelMatcher{Mode: s.matchMode}
+	}
+	return s.matcher
+}
+
+// SetMatcher replaces the candidate evaluation strategy (nil restores the PixelMatcher
+// for the current match mode)
+func (s *Searcher) SetMatcher(m Matcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.matcher = m
+}
@@ -0,0 +1,51 @@
+package screen
+
+import (
+	"image"
+	"slices"
+	"testing"
+)
+
+// onlyAt is a stub Matcher that accepts exactly one position
+type onlyAt struct{ at image.Point }
+
+func (m onlyAt) Match(_, _ image.Image, at image.Point, _ float64) (bool, float64) {
+	return at == m.at, 0
+}
+
+// countingMatcher is a PixelMatcher that counts the candidates it evaluates
+type countingMatcher struct {
+	PixelMatcher
+	n *int
+}
+
+func (m countingMatcher) Match(screenImg, templateImg image.Image, at image.Point, tolerance float64) (bool, float64) {
+	*m.n++
+	return m.PixelMatcher.Match(screenImg, templateImg, at, tolerance)
+}
+
+func TestSetMatcher(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	scr := newScreen(60, 40)
+	paste(scr, tpl, 17, 9)
+	s := NewSearcher()
+
+	// The scan delegates every candidate to the Matcher, replacing the pixel
+	// comparison and its quick-reject probes; nil restores the default
+	tests := []struct {
+		name    string
+		matcher Matcher
+		want    []image.Point
+	}{
+		{"default", nil, []image.Point{{17, 9}}},
+		{"stub", onlyAt{image.Pt(40, 30)}, []image.Point{{40, 30}}},
+		{"restored", nil, []image.Point{{17, 9}}},
+		{"explicit pixel matcher", PixelMatcher{Mode: MatchColor}, []image.Point{{17, 9}}},
+	}
+	for _, tt := range tests {
+		s.SetMatcher(tt.matcher)
+		if got := s.FindAllTemplates(scr, tpl, tol); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: FindAllTemplates = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package screen
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+// MatchMode selects how template and screen pixels are compared
+type MatchMode int
+
+const (
+	MatchColor  MatchMode = iota // Compare raw RGB colors (default)
+	MatchBinary                  // Binarize on luma first, so only the shape of bright text/icons matters
+	MatchEdges                   // Compare gradient magnitude, so only stable borders/outlines matter
+)
+
+var matchModeNames = map[MatchMode]string{
+	MatchColor:  "color",
+	MatchBinary: "binary",
+	MatchEdges:  "edges",
+}
+
+func (m MatchMode) String() string {
+	if name, ok := matchModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("MatchMode(%d)", int(m))
+}
+
+// ParseMatchMode converts a config name ("color", "binary", "edges") to a MatchMode.
+// An empty string means the default color mode.
+func ParseMatchMode(name string) (MatchMode, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return MatchColor, nil
+	}
+	for mode, n := range matchModeNames {
+		if n == name {
+			return mode, nil
+		}
+	}
+	return MatchColor, fmt.Errorf("unknown match mode %q", name)
+}
+
+// pixelGetter returns a function that reads a pixel as 0-255 components plus alpha,
+// preprocessed according to the current match mode
+func (s *Searcher) pixelGetter() func(image.Image, int, int) (uint32, uint32, uint32, uint32) {
+	return pixelGetterFor(s.mode())
+}
+
+// pixelGetterFor returns the pixel reader for mode
+func pixelGetterFor(mode MatchMode) func(image.Image, int, int) (uint32, uint32, uint32, uint32) {
+	switch mode {
+	case MatchBinary:
+		return binaryPixel
+	case MatchEdges:
+		return edgePixel
+	default:
+		return rawPixel
+	}
+}
+
+// rawPixel returns straight (non-premultiplied) color components normalized 0-255, plus Alpha.
+// Captured frames are *image.RGBA, so those are read straight from Pix:
+// img.At boxes a color.Color per call, which allocates on every pixel of every scan.
+func rawPixel(img image.Image, x, y int) (r, g, b, a uint32) {
+	if rgba, ok := img.(*image.RGBA); ok {
+		if !(image.Point{X: x, Y: y}.In(rgba.Rect)) {
+			return 0, 0, 0, 0
+		}
+		i := rgba.PixOffset(x, y)
+		p := rgba.Pix[i : i+4 : i+4]
+		return unpremultiply(uint32(p[0]), uint32(p[1]), uint32(p[2]), uint32(p[3]))
+	}
+	c := img.At(x, y)
+	r, g, b, a = c.RGBA()
+	return unpremultiply(r>>8, g>>8, b>>8, a>>8)
+}
+
+// unpremultiply undoes alpha premultiplication for semi-transparent pixels.
+// Both image.RGBA storage and color.RGBA() are premultiplied, so a 50%-alpha
+// template pixel would otherwise compare as half as bright as the opaque screen pixel.
+func unpremultiply(r, g, b, a uint32) (uint32, uint32, uint32, uint32) {
+	if a == 0 || a == 255 {
+		return r, g, b, a
+	}
+	return r * 255 / a, g * 255 / a, b * 255 / a, a
+}
+
+// binaryPixel thresholds the pixel luma to pure black or white
+func binaryPixel(img image.Image, x, y int) (r, g, b, a uint32) {
+	r, g, b, a = rawPixel(img, x, y)
+	v := uint32(0)
+	if luma(r, g, b) >= constants.BinaryThreshold {
+		v = 255
+	}
+	return v, v, v, a
+}
+
+// edgePixel returns the gradient magnitude around the pixel as a gray level.
+// Neighbors are clamped to the image bounds. If any neighbor is transparent the
+// gradient would be meaningless, so the pixel itself is reported transparent
+// (wildcard), keeping the template's transparency convention intact.
+func edgePixel(img image.Image, x, y int) (r, g, b, a uint32) {
+	bounds := img.Bounds()
+	lumaAt := func(px, py int) (uint32, bool) {
+		if px < bounds.Min.X {
+			px = bounds.Min.X
+		} else if px >= bounds.Max.X {
+			px = bounds.Max.X - 1
+		}
+		if py < bounds.Min.Y {
+			py = bounds.Min.Y
+		} else if py >= bounds.Max.Y {
+			py = bounds.Max.Y - 1
+		}
+		r, g, b, a := rawPixel(img, px, py)
+		return luma(r, g, b), a > 0
+	}
+
+	left, okL := lumaAt(x-1, y)
+	right, okR := lumaAt(x+1, y)
+	up, okU := lumaAt(x, y-1)
+	down, okD := lumaAt(x, y+1)
+	_, _, _, a = rawPixel(img, x, y)
+	if a == 0 || !okL || !okR || !okU || !okD {
+		return 0, 0, 0, 0
+	}
+
+	// Simple central difference: |dx| + |dy|, clamped to 255
+	v := absDiff(right, left) + absDiff(down, up)
+	if v > 255 {
+		v = 255
+	}
+	return v, v, v, a
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
+
+// luma returns the Rec. 601 brightness of a 0-255 RGB color
+func luma(r, g, b uint32) uint32 {
+	return (299*r + 587*g + 114*b) / 1000
+}
+
+// ApplyChromaKey returns a copy of img where pixels within tolerance of key are
+// fully transparent, so matching treats them as wildcards like erased background
+func ApplyChromaKey(img image.Image, key color.RGBA, tolerance float64) image.Image {
+	bounds := img.Bounds()
+	out := image.NewNRGBA(bounds)
+	kr, kg, kb := uint32(key.R), uint32(key.G), uint32(key.B)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := rawPixel(img, x, y)
+			if a > 0 && colorSimilar(r, g, b, kr, kg, kb, tolerance) {
+				a = 0
+			}
+			out.SetNRGBA(x, y, color.NRGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: uint8(a)})
+		}
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+package screen
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+// fillRect paints r of img with c
+func fillRect(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+func TestBinaryMatchIgnoresBackground(t *testing.T) {
+	white := color.RGBA{255, 255, 255, 255}
+	// White "text" (a top bar and every third column) cut from a dark gray button
+	isText := func(x, y int) bool { return y == 0 || x%3 == 0 }
+	text := image.NewNRGBA(image.Rect(0, 0, 10, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 10; x++ {
+			c := color.NRGBA{40, 40, 40, 255}
+			if isText(x, y) {
+				c = color.NRGBA{255, 255, 255, 255}
+			}
+			text.SetNRGBA(x, y, c)
+		}
+	}
+
+	// The same text on a blue button and on a red one
+	scr := newScreen(80, 30)
+	for _, b := range []struct {
+		at image.Point
+		bg color.RGBA
+	}{
+		{image.Pt(5, 5), color.RGBA{20, 20, 120, 255}},
+		{image.Pt(45, 5), color.RGBA{130, 30, 30, 255}},
+	} {
+		fillRect(scr, image.Rect(b.at.X-2, b.at.Y-2, b.at.X+12, b.at.Y+10), b.bg)
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 10; x++ {
+				if isText(x, y) {
+					scr.SetRGBA(b.at.X+x, b.at.Y+y, white)
+				}
+			}
+		}
+	}
+
+	s := NewSearcher()
+	if got := s.FindAllTemplates(scr, text, tol); len(got) != 0 {
+		t.Errorf("color mode matched %v, want nothing (the backgrounds differ)", got)
+	}
+	s.SetMatchMode(MatchBinary)
+	if got, want := s.FindAllTemplates(scr, text, tol), []image.Point{{5, 5}, {45, 5}}; !slices.Equal(got, want) {
+		t.Errorf("binary mode matched %v, want %v", got, want)
+	}
+}
+
+// progressButton draws a 40x16 button with a dark 2px border whose interior is
+// filled green from the left up to fill (0-1), gray beyond
+func progressButton(img *image.RGBA, at image.Point, fill float64) {
+	fillRect(img, image.Rectangle{Min: at, Max: at.Add(image.Pt(40, 16))}, color.RGBA{20, 20, 20, 255})
+	inner := image.Rect(at.X+2, at.Y+2, at.X+38, at.Y+14)
+	fillRect(img, inner, color.RGBA{110, 110, 110, 255})
+	inner.Max.X = inner.Min.X + int(fill*float64(inner.Dx()))
+	fillRect(img, inner, color.RGBA{60, 160, 60, 255})
+}
+
+func TestEdgeMatchIgnoresFillLevel(t *testing.T) {
+	// Cut with a 1px margin of background, as templates usually are
+	cut := newScreen(42, 18)
+	progressButton(cut, image.Pt(1, 1), 0.25)
+	tpl := image.NewNRGBA(cut.Bounds())
+	for y := 0; y < 18; y++ {
+		for x := 0; x < 42; x++ {
+			c := cut.RGBAAt(x, y)
+			tpl.SetNRGBA(x, y, color.NRGBA{c.R, c.G, c.B, 255})
+		}
+	}
+
+	// The same button on screen, three quarters full
+	scr := newScreen(80, 40)
+	progressButton(scr, image.Pt(20, 12), 0.75)
+
+	s := NewSearcher()
+	if got := s.FindAllTemplates(scr, tpl, tol); len(got) != 0 {
+		t.Errorf("color mode matched %v, want nothing (the fill differs)", got)
+	}
+	s.SetMatchMode(MatchEdges)
+	if got, want := s.FindAllTemplates(scr, tpl, tol), []image.Point{{19, 11}}; !slices.Equal(got, want) {
+		t.Errorf("edge mode matched %v, want %v", got, want)
+	}
+
+	// Transparent pixels stay wildcards: erase the interior, then repaint the screen's
+	for y := 3; y < 15; y++ {
+		for x := 3; x < 39; x++ {
+			tpl.SetNRGBA(x, y, color.NRGBA{})
+		}
+	}
+	fillRect(scr, image.Rect(22, 14, 58, 26), color.RGBA{255, 0, 255, 255})
+	if got, want := s.FindAllTemplates(scr, tpl, tol), []image.Point{{19, 11}}; !slices.Equal(got, want) {
+		t.Errorf("edge mode with a transparent interior matched %v, want %v", got, want)
+	}
+}
+
+func TestHalfAlphaTemplatePixel(t *testing.T) {
+	// A 50%-alpha pixel reads as its straight color, not darkened by its alpha
+	c := color.NRGBA{200, 100, 50, 128}
+	nrgba := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	nrgba.SetNRGBA(0, 0, c)
+	rgba := image.NewRGBA(image.Rect(0, 0, 1, 1)) // Stored premultiplied
+	rgba.Set(0, 0, c)
+	for name, img := range map[string]image.Image{"NRGBA": nrgba, "RGBA": rgba} {
+		r, g, b, a := rawPixel(img, 0, 0)
+		if !colorSimilar(r, g, b, 200, 100, 50, 2) || a != 128 {
+			t.Errorf("%s rawPixel = (%d,%d,%d,%d), want about (200,100,50,128)", name, r, g, b, a)
+		}
+	}
+
+	// A template whose pixels are all half transparent matches the opaque colors
+	tpl := newTemplate(8, 8, 0)
+	for i := 3; i < len(tpl.Pix); i += 4 {
+		tpl.Pix[i] = 128
+	}
+	scr := newScreen(40, 30)
+	paste(scr, tpl, 12, 9)
+	if got, want := NewSearcher().FindAllTemplates(scr, tpl, tol), []image.Point{{12, 9}}; !slices.Equal(got, want) {
+		t.Errorf("FindAllTemplates = %v, want %v", got, want)
+	}
+}
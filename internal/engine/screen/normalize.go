@@ -0,0 +1,133 @@
+package screen
+
+import (
+	"fmt"
+	"image"
+	"math"
+)
+
+// Normalization is a per-channel linear color correction (out = in*Gain + Offset),
+// applied to captured frames before matching. It undoes systematic color shifts of a
+// capture path (color profile, gamma) relative to the frames templates were cut from.
+type Normalization struct {
+	Gain   [3]float64 // R, G, B
+	Offset [3]float64 // R, G, B (0-255 scale)
+}
+
+// IdentityNormalization leaves colors unchanged
+func IdentityNormalization() Normalization {
+	return Normalization{Gain: [3]float64{1, 1, 1}}
+}
+
+// IsIdentity reports whether n leaves colors unchanged
+func (n Normalization) IsIdentity() bool {
+	return n == IdentityNormalization()
+}
+
+// Apply returns a normalized copy of img (same bounds), clamping each channel to 0-255
+func (n Normalization) Apply(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(b)
+
+	// Lookup tables: 256 entries per channel instead of float math per pixel
+	var lut [3][256]uint8
+	for c := 0; c < 3; c++ {
+		for v := 0; v < 256; v++ {
+			lut[c][v] = clampChannel(float64(v)*n.Gain[c] + n.Offset[c])
+		}
+	}
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			r, g, bl, a := rawPixel(img, x, y)
+			i := out.PixOffset(x, y)
+			p := out.Pix[i : i+4 : i+4]
+			// Premultiply again for RGBA (frames are opaque, so this is usually a no-op)
+			p[0] = uint8(uint32(lut[0][r]) * a / 255)
+			p[1] = uint8(uint32(lut[1][g]) * a / 255)
+			p[2] = uint8(uint32(lut[2][bl]) * a / 255)
+			p[3] = uint8(a)
+		}
+	}
+	return out
+}
+
+func clampChannel(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
+
+// FitNormalization fits, per channel by least squares, the normalization that maps the
+// captured swatch onto the reference (its true colors). Both must be the same size;
+// transparent reference pixels are ignored. A channel with no spread in the swatch
+// (a flat color) can only be corrected by an offset, so its gain stays 1.
+func FitNormalization(captured, reference image.Image) (Normalization, error) {
+	cb, rb := captured.Bounds(), reference.Bounds()
+	if cb.Dx() != rb.Dx() || cb.Dy() != rb.Dy() {
+		return Normalization{}, fmt.Errorf("swatch is %dx%d but the reference is %dx%d", cb.Dx(), cb.Dy(), rb.Dx(), rb.Dy())
+	}
+
+	var n float64
+	var sumX, sumY, sumXX, sumXY [3]float64
+	for y := 0; y < rb.Dy(); y++ {
+		for x := 0; x < rb.Dx(); x++ {
+			rr, rg, rbl, ra := rawPixel(reference, rb.Min.X+x, rb.Min.Y+y)
+			if ra == 0 {
+				continue
+			}
+			cr, cg, cbl, _ := rawPixel(captured, cb.Min.X+x, cb.Min.Y+y)
+			in := [3]float64{float64(cr), float64(cg), float64(cbl)}
+			want := [3]float64{float64(rr), float64(rg), float64(rbl)}
+			for c := 0; c < 3; c++ {
+				sumX[c] += in[c]
+				sumY[c] += want[c]
+				sumXX[c] += in[c] * in[c]
+				sumXY[c] += in[c] * want[c]
+			}
+			n++
+		}
+	}
+	if n == 0 {
+		return Normalization{}, fmt.Errorf("reference has no opaque pixels")
+	}
+
+	norm := IdentityNormalization()
+	for c := 0; c < 3; c++ {
+		variance := n*sumXX[c] - sumX[c]*sumX[c]
+		if variance > 1e-9*n*n {
+			norm.Gain[c] = (n*sumXY[c] - sumX[c]*sumY[c]) / variance
+		}
+		norm.Offset[c] = (sumY[c] - norm.Gain[c]*sumX[c]) / n
+	}
+	return norm, nil
+}
+
+// CalibrateNormalization locates reference (a swatch with known true colors) on frame
+// with a loose tolerance, since uncorrected colors may not match at the normal one,
+// and fits the normalization from where it was found. Returns the swatch position.
+func (s *Searcher) CalibrateNormalization(frame, reference image.Image, tolerance float64) (Normalization, image.Point, error) {
+	x, y, found := s.FindTemplate(frame, reference, tolerance)
+	if !found {
+		return Normalization{}, image.Point{}, fmt.Errorf("reference swatch not found on screen (tolerance %.0f)", tolerance)
+	}
+	at := image.Point{X: x, Y: y}
+	sub, ok := frame.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok {
+		return Normalization{}, at, fmt.Errorf("frame does not support cropping")
+	}
+	area := image.Rectangle{Min: at, Max: at.Add(reference.Bounds().Size())}
+	norm, err := FitNormalization(sub.SubImage(area), reference)
+	return norm, at, err
+}
+
+// SetNormalization sets the color correction applied to every capture (identity = none)
+func (s *Searcher) SetNormalization(n Normalization) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if n.IsIdentity() {
+		s.normalization = nil
+		return
+	}
+	s.normalization = &n
+}
@@ -0,0 +1,30 @@
+package screen
+
+import (
+	"image"
+	"testing"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+func TestCalibrateNormalization(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	s := NewSearcher()
+	scr := newScreen(60, 40)
+	paste(scr, tpl, 22, 13)
+	// out = in*0.6 + 50
+	shift := Normalization{Gain: [3]float64{0.6, 0.6, 0.6}, Offset: [3]float64{50, 50, 50}}
+	shifted := shift.Apply(scr)
+
+	if got := s.FindAllTemplates(shifted, tpl, tol); len(got) != 0 {
+		t.Errorf("shifted frame matched at the default tolerance: %v", got)
+	}
+	norm, _, err := s.CalibrateNormalization(shifted, tpl, constants.CalibrationTolerance)
+	if err != nil {
+		t.Fatalf("CalibrateNormalization = %v", err)
+	}
+	got := s.FindAllTemplates(norm.Apply(shifted), tpl, tol)
+	if len(got) != 1 || got[0] != image.Pt(22, 13) {
+		t.Errorf("after normalization FindAllTemplates = %v, want [(22,13)]", got)
+	}
+}
@@ -0,0 +1,58 @@
+package screen
+
+import (
+	"image"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+// OpaqueBounds returns the smallest rectangle (in img coordinates) that holds every
+// non-transparent pixel. It is empty for a fully transparent image.
+func OpaqueBounds(img image.Image) image.Rectangle {
+	b := img.Bounds()
+	box := image.Rectangle{Min: b.Max, Max: b.Min}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := rawPixel(img, x, y); a == 0 {
+				continue
+			}
+			box.Min.X = min(box.Min.X, x)
+			box.Min.Y = min(box.Min.Y, y)
+			box.Max.X = max(box.Max.X, x+1)
+			box.Max.Y = max(box.Max.Y, y+1)
+		}
+	}
+	if box.Empty() {
+		return image.Rectangle{}
+	}
+	return box
+}
+
+// matchArea returns the part of a template the full check has to compare: its opaque
+// bounds, computed once per template (LoadImage primes the cache). Pixels outside are
+// transparent wildcards in every match mode, so skipping them changes no result.
+func (s *Searcher) matchArea(templateImg image.Image) image.Rectangle {
+	s.opaqueMu.Lock()
+	defer s.opaqueMu.Unlock()
+	if s.fullTemplate {
+		return templateImg.Bounds()
+	}
+	if box, ok := s.opaque[templateImg]; ok {
+		return box
+	}
+	// Reloaded assets are new images: drop stale entries rather than grow forever
+	if s.opaque == nil || len(s.opaque) >= constants.OpaqueBoundsCacheSize {
+		s.opaque = make(map[image.Image]image.Rectangle)
+	}
+	box := OpaqueBounds(templateImg)
+	s.opaque[templateImg] = box
+	return box
+}
+
+// SetFullTemplateMatch turns off the opaque-bounds optimization, so the full check
+// walks the whole template (for comparing results and timings)
+func (s *Searcher) SetFullTemplateMatch(full bool) {
+	s.opaqueMu.Lock()
+	defer s.opaqueMu.Unlock()
+	s.fullTemplate = full
+}
@@ -0,0 +1,35 @@
+package screen
+
+import (
+	"image"
+	"slices"
+	"testing"
+)
+
+func TestOpaqueBoundsTrimming(t *testing.T) {
+	// A loosely cropped template: an 8x8 core in a wide transparent margin
+	loose := image.NewNRGBA(image.Rect(0, 0, 24, 20))
+	core := newTemplate(8, 8, 0)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			loose.SetNRGBA(9+x, 5+y, core.NRGBAAt(x, y))
+		}
+	}
+	scr := newScreen(80, 60)
+	paste(scr, core, 30, 20)                  // Match at (21, 15)
+	paste(scr, newTemplate(8, 8, 30), 40, 40) // Near miss, within tolerance
+	paste(scr, newTemplate(8, 8, 90), 55, 5)  // Too far off
+
+	if got, want := OpaqueBounds(loose), image.Rect(9, 5, 17, 13); got != want {
+		t.Errorf("OpaqueBounds = %v, want %v", got, want)
+	}
+	want := []image.Point{{21, 15}, {31, 35}}
+	s := NewSearcher()
+	if got := s.FindAllTemplates(scr, loose, tol); !slices.Equal(got, want) {
+		t.Errorf("trimmed: FindAllTemplates = %v, want %v", got, want)
+	}
+	s.SetFullTemplateMatch(true)
+	if got := s.FindAllTemplates(scr, loose, tol); !slices.Equal(got, want) {
+		t.Errorf("full: FindAllTemplates = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package screen
+
+import "image"
+
+// PartialMatch is a template found with only part of it visible (see FindAllPartial)
+type PartialMatch struct {
+	At       image.Point     // Template top-left
+	Fraction float64         // Fraction of the opaque template pixels that matched
+	Region   image.Rectangle // Bounding box of the matching pixels (screen coordinates)
+}
+
+// Center returns the center of the matched region, where a click lands on the
+// visible part of a covered button
+func (m PartialMatch) Center() image.Point {
+	return image.Point{X: (m.Region.Min.X + m.Region.Max.X) / 2, Y: (m.Region.Min.Y + m.Region.Max.Y) / 2}
+}
+
+// partialMatcher accepts a candidate when at most maxFailRate of its pixels fail
+type partialMatcher struct {
+	mode        MatchMode
+	maxFailRate float64
+	area        image.Rectangle // Template area to compare (see matchArea)
+}
+
+// Match implements Matcher
+func (m partialMatcher) Match(screenImg, templateImg image.Image, at image.Point, tolerance float64) (bool, float64) {
+	result := match(screenImg, templateImg, at.X, at.Y, tolerance, m.mode, pixelGetterFor(m.mode), m.area, m.maxFailRate)
+	return result.matched, result.failRate
+}
+
+// FindAllPartial finds templateImg where at least minFraction of its opaque pixels
+// match, for buttons partly covered by an overlay. Covered pixels may differ
+// arbitrarily, so there are no quick-reject probes and no MaxPixelDiff exit; pixels
+// are always compared in the current match mode (a SetMatcher strategy is not used).
+// Loose acceptance also admits positions a pixel or two off, so each candidate is
+// moved to the best-matching position nearby before its region is measured.
+func (s *Searcher) FindAllPartial(screenImg, templateImg image.Image, tolerance, minFraction float64) []PartialMatch {
+	area := s.matchArea(templateImg)
+	mode := s.mode()
+	m := partialMatcher{mode: mode, maxFailRate: 1 - minFraction, area: area}
+
+	var candidates []image.Point
+	s.scanWith(m, screenImg, templateImg, screenImg.Bounds(), tolerance, func(p image.Point) {
+		candidates = append(candidates, p)
+	})
+
+	getRgbAndAlpha := pixelGetterFor(mode)
+	size := templateImg.Bounds().Size()
+	var matches []PartialMatch
+	for _, p := range candidates {
+		at, result := refinePartial(screenImg, templateImg, p, tolerance, mode, area)
+		duplicate := false
+		for _, k := range matches {
+			if abs(at.X-k.At.X) <= size.X/2 && abs(at.Y-k.At.Y) <= size.Y/2 {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		region := matchedRegion(screenImg, templateImg, at, tolerance, getRgbAndAlpha, area)
+		s.debug("[Match] Partial at (%d,%d) fraction=%.3f region=%v", at.X, at.Y, 1-result.failRate, region)
+		matches = append(matches, PartialMatch{At: at, Fraction: 1 - result.failRate, Region: region})
+	}
+
+	s.matchCount += len(matches)
+	return matches
+}
+
+// refinePartial climbs from at to the neighboring position with the most matching
+// pixels (ties: the smallest mean difference) until no neighbor is better
+func refinePartial(screenImg, templateImg image.Image, at image.Point, tolerance float64, mode MatchMode, area image.Rectangle) (image.Point, matchResult) {
+	getRgbAndAlpha := pixelGetterFor(mode)
+	size := templateImg.Bounds().Size()
+	bounds := screenImg.Bounds()
+	evaluate := func(p image.Point) matchResult {
+		// maxFailRate 1: run every pixel so fail rates and mean diffs are comparable
+		return match(screenImg, templateImg, p.X, p.Y, tolerance, mode, getRgbAndAlpha, area, 1)
+	}
+	better := func(a, b matchResult) bool {
+		if a.failRate != b.failRate {
+			return a.failRate < b.failRate
+		}
+		return a.meanDiff < b.meanDiff
+	}
+
+	best := evaluate(at)
+	for steps := 0; steps < size.X+size.Y; steps++ {
+		next, nextResult := at, best
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				p := at.Add(image.Point{X: dx, Y: dy})
+				if p == at || !p.In(bounds) || p.X+size.X > bounds.Max.X || p.Y+size.Y > bounds.Max.Y {
+					continue
+				}
+				if r := evaluate(p); better(r, nextResult) {
+					next, nextResult = p, r
+				}
+			}
+		}
+		if next == at {
+			break
+		}
+		at, best = next, nextResult
+	}
+	return at, best
+}
+
+// matchedRegion returns the bounding box (screen coordinates) of the opaque template
+// pixels inside area that match the screen with the template's top-left at `at`
+func matchedRegion(screenImg, templateImg image.Image, at image.Point, tolerance float64, getRgbAndAlpha func(image.Image, int, int) (uint32, uint32, uint32, uint32), area image.Rectangle) image.Rectangle {
+	tBounds := templateImg.Bounds()
+	region := image.Rectangle{}
+	for ty := area.Min.Y; ty < area.Max.Y; ty++ {
+		for tx := area.Min.X; tx < area.Max.X; tx++ {
+			tr, tg, tb, ta := getRgbAndAlpha(templateImg, tx, ty)
+			if ta == 0 {
+				continue
+			}
+			p := at.Add(image.Point{X: tx - tBounds.Min.X, Y: ty - tBounds.Min.Y})
+			sr, sg, sb, _ := getRgbAndAlpha(screenImg, p.X, p.Y)
+			if !colorSimilar(sr, sg, sb, tr, tg, tb, tolerance) {
+				continue
+			}
+			region = region.Union(image.Rectangle{Min: p, Max: p.Add(image.Point{X: 1, Y: 1})})
+		}
+	}
+	return region
+}
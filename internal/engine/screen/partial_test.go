@@ -0,0 +1,37 @@
+package screen
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestFindAllPartial(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	scr := newScreen(60, 40)
+	paste(scr, tpl, 20, 10)
+	// An overlay covers the right 3 columns: 5/8 stays visible
+	for y := 10; y < 18; y++ {
+		for x := 25; x < 28; x++ {
+			scr.SetRGBA(x, y, color.RGBA{20, 20, 20, 255})
+		}
+	}
+	s := NewSearcher()
+
+	if got := s.FindAllTemplates(scr, tpl, tol); len(got) != 0 {
+		t.Errorf("strict match found the covered button: %v", got)
+	}
+	if got := s.FindAllPartial(scr, tpl, tol, 0.7); len(got) != 0 {
+		t.Errorf("FindAllPartial(70%%) = %v, want none", got)
+	}
+	got := s.FindAllPartial(scr, tpl, tol, 0.6)
+	if len(got) != 1 {
+		t.Fatalf("FindAllPartial(60%%) = %v, want one match", got)
+	}
+	if got[0].At != image.Pt(20, 10) {
+		t.Errorf("At = %v, want (20,10)", got[0].At)
+	}
+	if c := got[0].Center(); c != image.Pt(22, 14) {
+		t.Errorf("Center = %v, want the visible region's center (22,14)", c)
+	}
+}
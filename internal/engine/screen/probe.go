@@ -0,0 +1,84 @@
+package screen
+
+import "image"
+
+// probe is a template pixel compared before the full match, to reject a position early
+type probe struct {
+	off     image.Point // Offset from the template's top-left
+	r, g, b uint32
+}
+
+// QuickRejectProbes returns the template offsets used as quick-reject probes
+// (see selectProbes). A fully transparent template has none.
+func QuickRejectProbes(templateImg image.Image) []image.Point {
+	probes := selectProbes(templateImg, rawPixel)
+	offs := make([]image.Point, len(probes))
+	for i, p := range probes {
+		offs[i] = p.off
+	}
+	return offs
+}
+
+// selectProbes picks up to three opaque template pixels for quick rejection.
+// The top-left, center and bottom-right pixels are used where opaque; a transparent
+// one is replaced by the first, most central or last opaque pixel respectively, so
+// templates with transparent corners or centers still reject most positions early.
+func selectProbes(templateImg image.Image, getRgbAndAlpha func(image.Image, int, int) (uint32, uint32, uint32, uint32)) []probe {
+	tb := templateImg.Bounds()
+	w, h := tb.Dx(), tb.Dy()
+	if w == 0 || h == 0 {
+		return nil
+	}
+	opaque := func(p image.Point) bool {
+		_, _, _, a := getRgbAndAlpha(templateImg, tb.Min.X+p.X, tb.Min.Y+p.Y)
+		return a > 0
+	}
+
+	center := image.Point{X: w / 2, Y: h / 2}
+	offs := []image.Point{{0, 0}, center, {w - 1, h - 1}}
+	if !opaque(offs[0]) || !opaque(offs[1]) || !opaque(offs[2]) {
+		// One pass over the template for the substitutes
+		var first, central, last image.Point
+		found, best := false, -1
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				p := image.Point{X: x, Y: y}
+				if !opaque(p) {
+					continue
+				}
+				if !found {
+					first, found = p, true
+				}
+				last = p
+				if d := (x-center.X)*(x-center.X) + (y-center.Y)*(y-center.Y); best < 0 || d < best {
+					central, best = p, d
+				}
+			}
+		}
+		if !found {
+			return nil
+		}
+		for i, sub := range []image.Point{first, central, last} {
+			if !opaque(offs[i]) {
+				offs[i] = sub
+			}
+		}
+	}
+
+	probes := make([]probe, 0, len(offs))
+	for _, off := range offs {
+		duplicate := false
+		for _, p := range probes {
+			if p.off == off {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		r, g, b, _ := getRgbAndAlpha(templateImg, tb.Min.X+off.X, tb.Min.Y+off.Y)
+		probes = append(probes, probe{off: off, r: r, g: g, b: b})
+	}
+	return probes
+}
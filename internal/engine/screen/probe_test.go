@@ -0,0 +1,41 @@
+package screen
+
+import (
+	"image"
+	"image/color"
+	"slices"
+	"testing"
+)
+
+// roundedTemplate returns a 12x12 template with transparent 2x2 corners
+func roundedTemplate() *image.NRGBA {
+	tpl := newTemplate(12, 12, 0)
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 12; x++ {
+			if (x < 2 || x >= 10) && (y < 2 || y >= 10) {
+				tpl.SetNRGBA(x, y, color.NRGBA{})
+			}
+		}
+	}
+	return tpl
+}
+
+func TestQuickRejectProbesAreOpaque(t *testing.T) {
+	tpl := roundedTemplate()
+	probes := QuickRejectProbes(tpl)
+	for _, p := range probes {
+		if tpl.NRGBAAt(p.X, p.Y).A == 0 {
+			t.Errorf("probe %v is transparent", p)
+		}
+	}
+	if want := []image.Point{{2, 0}, {6, 6}, {9, 11}}; !slices.Equal(probes, want) {
+		t.Errorf("QuickRejectProbes = %v, want %v", probes, want)
+	}
+
+	// The substituted probes still find the template
+	scr := newScreen(60, 40)
+	paste(scr, tpl, 25, 14)
+	if got, want := NewSearcher().FindAllTemplates(scr, tpl, tol), []image.Point{{25, 14}}; !slices.Equal(got, want) {
+		t.Errorf("FindAllTemplates = %v, want %v", got, want)
+	}
+}
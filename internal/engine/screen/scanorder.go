@@ -0,0 +1,100 @@
+package screen
+
+import (
+	"image"
+	"sort"
+)
+
+// LargestFirst returns the indexes of templates ordered by pixel area, largest
+// first; equal areas keep their order. It is a scan order for checks where the first
+// hit wins (see FindAny): a large template has fewer positions to try, so when it is
+// on screen the small ones are never scanned. A screen showing none of the templates
+// costs the same in any order.
+func LargestFirst(templates []image.Image) []int {
+	order := make([]int, len(templates))
+	for i := range order {
+		order[i] = i
+	}
+	area := func(i int) int {
+		size := templates[i].Bounds().Size()
+		return size.X * size.Y
+	}
+	sort.SliceStable(order, func(a, b int) bool { return area(order[a]) > area(order[b]) })
+	return order
+}
+
+// FindFirst returns the first match of templateImg in area of screenImg in scan
+// order (top-to-bottom, left-to-right), stopping there: an existence check that
+// does not scan the rest of the area
+func (s *Searcher) FindFirst(screenImg, templateImg image.Image, area image.Rectangle, tolerance float64) (image.Point, bool) {
+	area = area.Intersect(screenImg.Bounds())
+	if err := CheckTemplateSize(templateImg, area.Size()); err != nil {
+		s.debug("[Match] Skipped: %v", err)
+		return image.Point{}, false
+	}
+	check := s.candidateCheck(s.Matcher(), screenImg, templateImg, tolerance)
+	size := templateImg.Bounds().Size()
+	for y := area.Min.Y; y <= area.Max.Y-size.Y; y++ {
+		for x := area.Min.X; x <= area.Max.X-size.X; x++ {
+			if at := (image.Point{X: x, Y: y}); check(at) {
+				s.matchCount++
+				return at, true
+			}
+		}
+	}
+	return image.Point{}, false
+}
+
+// FindFirstNear is FindFirst scanning center-out: top-left positions in square
+// rings of growing distance around hint (e.g. where the template was last found),
+// so a button that stayed put or moved a little is found after a few candidates
+// instead of most of the area. Without a match it scans the whole area, like
+// FindFirst. When several positions match, the one nearest hint wins rather than
+// the topmost; for a single copy both return the same point.
+func (s *Searcher) FindFirstNear(screenImg, templateImg image.Image, area image.Rectangle, hint image.Point, tolerance float64) (image.Point, bool) {
+	area = area.Intersect(screenImg.Bounds())
+	if err := CheckTemplateSize(templateImg, area.Size()); err != nil {
+		s.debug("[Match] Skipped: %v", err)
+		return image.Point{}, false
+	}
+	check := s.candidateCheck(s.Matcher(), screenImg, templateImg, tolerance)
+
+	// Valid top-left positions, inclusive
+	size := templateImg.Bounds().Size()
+	minP, maxP := area.Min, area.Max.Sub(size)
+	hint.X = min(max(hint.X, minP.X), maxP.X)
+	hint.Y = min(max(hint.Y, minP.Y), maxP.Y)
+
+	var at image.Point
+	try := func(x, y int) bool {
+		if x < minP.X || x > maxP.X || y < minP.Y || y > maxP.Y {
+			return false
+		}
+		at = image.Point{X: x, Y: y}
+		return check(at)
+	}
+	rings := max(hint.X-minP.X, maxP.X-hint.X, hint.Y-minP.Y, maxP.Y-hint.Y)
+	for r := 0; r <= rings; r++ {
+		if r == 0 {
+			if try(hint.X, hint.Y) {
+				s.matchCount++
+				return at, true
+			}
+			continue
+		}
+		// Top and bottom rows, then the columns between them (clipped to the area)
+		for x := max(hint.X-r, minP.X); x <= min(hint.X+r, maxP.X); x++ {
+			if try(x, hint.Y-r) || try(x, hint.Y+r) {
+				s.matchCount++
+				return at, true
+			}
+		}
+		for y := max(hint.Y-r+1, minP.Y); y <= min(hint.Y+r-1, maxP.Y); y++ {
+			if try(hint.X-r, y) || try(hint.X+r, y) {
+				s.matchCount++
+				return at, true
+			}
+		}
+	}
+	return image.Point{}, false
+}
@@ -0,0 +1,110 @@
+package screen
+
+import (
+	"image"
+	"slices"
+	"testing"
+)
+
+func TestFindFirstNear(t *testing.T) {
+	scr := newScreen(200, 120)
+	tpl := newTemplate(10, 10, 0)
+	paste(scr, tpl, 150, 90)
+	want := image.Pt(150, 90)
+
+	var n int
+	s := NewSearcher()
+	s.SetMatcher(countingMatcher{PixelMatcher{Mode: MatchColor}, &n})
+
+	raster, found := s.FindFirst(scr, tpl, scr.Bounds(), tol)
+	if !found || raster != want {
+		t.Fatalf("FindFirst = %v, %v; want %v", raster, found, want)
+	}
+	rasterN := n
+
+	// Center-out finds the same match, a good hint after far fewer candidates
+	n = 0
+	if p, found := s.FindFirstNear(scr, tpl, scr.Bounds(), image.Pt(147, 92), tol); !found || p != want {
+		t.Errorf("FindFirstNear(good hint) = %v, %v; want %v", p, found, want)
+	}
+	if n*10 >= rasterN {
+		t.Errorf("good hint evaluated %d candidates, raster order %d", n, rasterN)
+	}
+	if p, found := s.FindFirstNear(scr, tpl, scr.Bounds(), image.Pt(0, 0), tol); !found || p != want {
+		t.Errorf("FindFirstNear(poor hint) = %v, %v; want %v", p, found, want)
+	}
+
+	// A blank screen evaluates every position exactly once
+	n = 0
+	blank := newScreen(200, 120)
+	if _, found := s.FindFirstNear(blank, tpl, blank.Bounds(), image.Pt(60, 30), tol); found {
+		t.Error("FindFirstNear found a match on a blank screen")
+	}
+	if n != 191*111 {
+		t.Errorf("blank screen evaluated %d candidates, want %d", n, 191*111)
+	}
+}
+
+func TestLargestFirst(t *testing.T) {
+	templates := []image.Image{newTemplate(4, 4, 0), newTemplate(10, 10, 0), newTemplate(6, 6, 0), newTemplate(10, 10, 40)}
+	if got, want := LargestFirst(templates), []int{1, 3, 2, 0}; !slices.Equal(got, want) {
+		t.Errorf("LargestFirst = %v, want %v", got, want)
+	}
+	if got := LargestFirst(nil); len(got) != 0 {
+		t.Errorf("LargestFirst(nil) = %v, want none", got)
+	}
+}
+
+// largestFirstScreen shows a large template but none of the small ones
+func largestFirstScreen() (*image.RGBA, []image.Image) {
+	scr := newScreen(400, 300)
+	large := newTemplate(40, 40, 0)
+	paste(scr, large, 300, 200)
+	small := []image.Image{newTemplate(6, 6, 80), newTemplate(6, 6, 90), newTemplate(6, 6, 100)}
+	return scr, append(small, large)
+}
+
+func TestFindAnyLargestFirst(t *testing.T) {
+	scr, templates := largestFirstScreen()
+	var n int
+	s := NewSearcher()
+	s.SetMatcher(countingMatcher{PixelMatcher{Mode: MatchColor}, &n})
+
+	if i, _, found := s.FindAny(scr, templates, tol); !found || i != 3 {
+		t.Fatalf("FindAny = %d, %v; want 3, true", i, found)
+	}
+	inOrder := n
+
+	n = 0
+	order := LargestFirst(templates)
+	ordered := make([]image.Image, len(order))
+	for i, j := range order {
+		ordered[i] = templates[j]
+	}
+	if i, p, found := s.FindAny(scr, ordered, tol); !found || order[i] != 3 || p != image.Pt(300, 200) {
+		t.Errorf("FindAny(largest first) = %d, %v, %v; want the large template at (300,200)", order[i], p, found)
+	}
+	if n >= inOrder {
+		t.Errorf("largest first evaluated %d candidates, in order %d", n, inOrder)
+	}
+}
+
+func BenchmarkFindAnyOrder(b *testing.B) {
+	scr, templates := largestFirstScreen()
+	order := LargestFirst(templates)
+	ordered := make([]image.Image, len(order))
+	for i, j := range order {
+		ordered[i] = templates[j]
+	}
+	s := NewSearcher()
+	for _, bb := range []struct {
+		name      string
+		templates []image.Image
+	}{{"in order", templates}, {"largest first", ordered}} {
+		b.Run(bb.name, func(b *testing.B) {
+			for b.Loop() {
+				s.FindAny(scr, bb.templates, tol)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package screen
+
+import "math"
+
+// MaxColorDistance is the largest possible Euclidean RGB distance (sqrt(3*255^2)),
+// the loosest tolerance: every pixel matches every other
+var MaxColorDistance = math.Sqrt(3 * 255 * 255)
+
+// Similarity is the percentage form of a tolerance: how close a screen pixel must be
+// to the template pixel, from 0% (anything matches) to 100% (exact match only).
+// The mapping is linear over the whole distance range:
+//
+//	tolerance = (100 - similarity) / 100 * MaxColorDistance
+//
+// so 90% is a tolerance of about 44, and the default tolerance 60 is about 86%.
+// Matching still uses the absolute distance; this is only a friendlier way to set it.
+
+// ToleranceFromSimilarity converts a similarity percentage to the tolerance (the
+// per-pixel distance threshold). Values outside 0-100 are clamped.
+func ToleranceFromSimilarity(percent float64) float64 {
+	percent = math.Max(0, math.Min(100, percent))
+	return (100 - percent) / 100 * MaxColorDistance
+}
+
+// SimilarityFromTolerance converts a tolerance back to a similarity percentage.
+// Values outside 0-MaxColorDistance are clamped.
+func SimilarityFromTolerance(tolerance float64) float64 {
+	tolerance = math.Max(0, math.Min(MaxColorDistance, tolerance))
+	return 100 - tolerance/MaxColorDistance*100
+}
@@ -0,0 +1,44 @@
+package screen
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarityConversion(t *testing.T) {
+	near := func(a, b float64) bool { return math.Abs(a-b) < 0.05 }
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"100% is exact", ToleranceFromSimilarity(100), 0},
+		{"0% is the max distance", ToleranceFromSimilarity(0), MaxColorDistance},
+		{"50%", ToleranceFromSimilarity(50), MaxColorDistance / 2},
+		{"distance 0 is 100%", SimilarityFromTolerance(0), 100},
+		{"max distance is 0%", SimilarityFromTolerance(MaxColorDistance), 0},
+		{"86% round trip", SimilarityFromTolerance(ToleranceFromSimilarity(86)), 86},
+		{"below 0% clamps", ToleranceFromSimilarity(-5), MaxColorDistance},
+		{"above 100% clamps", ToleranceFromSimilarity(150), 0},
+	}
+	for _, tt := range tests {
+		if !near(tt.got, tt.want) {
+			t.Errorf("%s: got %.2f, want %.2f", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestSimilarityMatching(t *testing.T) {
+	scr := newScreen(32, 32)
+	paste(scr, newTemplate(8, 8, 20), 10, 10) // Red raised by 20
+	tpl := newTemplate(8, 8, 0)
+	s := NewSearcher()
+
+	// 96% is a tolerance of ~17.7, 95% of ~22.1
+	if got := s.FindAllTemplates(scr, tpl, ToleranceFromSimilarity(96)); len(got) != 0 {
+		t.Errorf("96%%: FindAllTemplates = %v, want none", got)
+	}
+	if got := s.FindAllTemplates(scr, tpl, ToleranceFromSimilarity(95)); len(got) != 1 {
+		t.Errorf("95%%: FindAllTemplates = %v, want one match", got)
+	}
+}
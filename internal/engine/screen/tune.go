@@ -0,0 +1,125 @@
+package screen
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"sort"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+)
+
+// TuneResult is the outcome of SuggestTolerance
+type TuneResult struct {
+	PositiveDistance float64     // Smallest tolerance at which the template matches the positive sample
+	PositiveAt       image.Point // Where the positive sample matched best
+	NegativeDistance float64     // Smallest tolerance at which the template matches the negative sample (+Inf if never)
+	NegativeAt       image.Point // Where the negative sample came closest
+	Suggested        float64     // Tolerance halfway between the two (maximum margin)
+}
+
+// SuggestTolerance finds a tolerance that matches the template in the positive sample
+// but not in the negative one, choosing the midpoint to maximize the margin on both sides.
+func (s *Searcher) SuggestTolerance(templateImg, positive, negative image.Image) (TuneResult, error) {
+	var result TuneResult
+
+	result.PositiveDistance, result.PositiveAt = s.MatchDistance(positive, templateImg)
+	if math.IsInf(result.PositiveDistance, 1) {
+		return result, fmt.Errorf("template never matches the positive sample (a pixel differs by more than %.0f)", constants.MaxPixelDiff)
+	}
+
+	result.NegativeDistance, result.NegativeAt = s.MatchDistance(negative, templateImg)
+	if result.NegativeDistance <= result.PositiveDistance {
+		return result, fmt.Errorf("samples are not separable: negative matches at %.1f, positive needs %.1f",
+			result.NegativeDistance, result.PositiveDistance)
+	}
+
+	upper := math.Min(result.NegativeDistance, MaxColorDistance)
+	result.Suggested = (result.PositiveDistance + upper) / 2
+	return result, nil
+}
+
+// MatchDistance returns the smallest tolerance at which the template would match
+// anywhere in screenImg, and where. Under the MaxFailRate rule that is, for each
+// position, the per-pixel diff that all but the allowed failing pixels stay under.
+// Returns +Inf if no position can match at any tolerance.
+func (s *Searcher) MatchDistance(screenImg, templateImg image.Image) (float64, image.Point) {
+	return s.matchDistance(screenImg, templateImg, screenImg.Bounds())
+}
+
+// MatchDistanceInROI is MatchDistance over the placements of the template that lie
+// entirely inside roi (screen coordinates), e.g. to check a fixed-position element
+func (s *Searcher) MatchDistanceInROI(screenImg, templateImg image.Image, roi image.Rectangle) (float64, image.Point) {
+	return s.matchDistance(screenImg, templateImg, roi.Intersect(screenImg.Bounds()))
+}
+
+func (s *Searcher) matchDistance(screenImg, templateImg image.Image, sBounds image.Rectangle) (float64, image.Point) {
+	tBounds := templateImg.Bounds()
+	tWidth, tHeight := tBounds.Dx(), tBounds.Dy()
+	mode := s.mode()
+	getRgbAndAlpha := pixelGetterFor(mode)
+
+	// Pre-read opaque template pixels once
+	type px struct {
+		dx, dy  int
+		r, g, b uint32
+	}
+	var tpx []px
+	for ty := 0; ty < tHeight; ty++ {
+		for tx := 0; tx < tWidth; tx++ {
+			r, g, b, a := getRgbAndAlpha(templateImg, tBounds.Min.X+tx, tBounds.Min.Y+ty)
+			if a > 0 {
+				tpx = append(tpx, px{tx, ty, r, g, b})
+			}
+		}
+	}
+
+	best := math.Inf(1)
+	var bestAt image.Point
+	if len(tpx) == 0 {
+		return best, bestAt
+	}
+
+	allowedFails := int(constants.MaxFailRate * float64(len(tpx)))
+	diffs := make([]float64, 0, len(tpx))
+
+	for y := sBounds.Min.Y; y <= sBounds.Max.Y-tHeight; y++ {
+		for x := sBounds.Min.X; x <= sBounds.Max.X-tWidth; x++ {
+			diffs = diffs[:0]
+			over := 0 // Pixels already worse than the best distance so far
+			rejected := false
+
+			for _, p := range tpx {
+				sr, sg, sb, _ := getRgbAndAlpha(screenImg, x+p.dx, y+p.dy)
+				diff := math.Sqrt(float64((sr-p.r)*(sr-p.r) + (sg-p.g)*(sg-p.g) + (sb-p.b)*(sb-p.b)))
+
+				// Same hard reject as match(): no tolerance can save this position
+				if mode == MatchColor && diff > constants.MaxPixelDiff {
+					rejected = true
+					break
+				}
+				if diff > best {
+					over++
+					if over > allowedFails {
+						rejected = true
+						break
+					}
+				}
+				diffs = append(diffs, diff)
+			}
+			if rejected {
+				continue
+			}
+
+			// Required tolerance: the largest diff once the allowed failures are excluded
+			sort.Float64s(diffs)
+			required := diffs[len(diffs)-1-allowedFails]
+			if required < best {
+				best = required
+				bestAt = image.Point{X: x, Y: y}
+			}
+		}
+	}
+
+	return best, bestAt
+}
@@ -0,0 +1,69 @@
+package screen
+
+import (
+	"image"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestMatchDistanceInROI(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	scr := newScreen(64, 32)
+	paste(scr, tpl, 4, 4)                     // Exact copy
+	paste(scr, newTemplate(8, 8, 20), 40, 12) // Red raised by 20
+	s := NewSearcher()
+
+	if d, at := s.MatchDistance(scr, tpl); at != image.Pt(4, 4) || int(d) != 0 {
+		t.Errorf("MatchDistance = %v at %v, want 0 at (4,4)", d, at)
+	}
+	// The region scores the shifted copy alone
+	if d, at := s.MatchDistanceInROI(scr, tpl, image.Rect(36, 8, 52, 24)); at != image.Pt(40, 12) || int(d) != 20 {
+		t.Errorf("MatchDistanceInROI = %v at %v, want 20 at (40,12)", d, at)
+	}
+}
+
+func TestSuggestTolerance(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	sample := func(shift int) *image.RGBA {
+		scr := newScreen(48, 32)
+		paste(scr, newTemplate(8, 8, shift), 20, 10)
+		return scr
+	}
+	// The button on screen is slightly off the template; a similar button is further off
+	positive, negative := sample(10), sample(50)
+	s := NewSearcher()
+
+	result, err := s.SuggestTolerance(tpl, positive, negative)
+	if err != nil {
+		t.Fatalf("SuggestTolerance = %v", err)
+	}
+	if int(result.PositiveDistance) != 10 || int(result.NegativeDistance) != 50 || result.PositiveAt != image.Pt(20, 10) {
+		t.Errorf("distances = %v at %v and %v, want 10 at (20,10) and 50",
+			result.PositiveDistance, result.PositiveAt, result.NegativeDistance)
+	}
+	if int(result.Suggested) != 30 {
+		t.Errorf("Suggested = %v, want 30 (the midpoint)", result.Suggested)
+	}
+	// The suggestion separates the samples
+	if got := s.FindAllTemplates(positive, tpl, result.Suggested); !slices.Equal(got, []image.Point{{20, 10}}) {
+		t.Errorf("positive sample matched %v at the suggested tolerance", got)
+	}
+	if got := s.FindAllTemplates(negative, tpl, result.Suggested); len(got) != 0 {
+		t.Errorf("negative sample matched %v at the suggested tolerance", got)
+	}
+
+	// A negative sample the template never matches leaves the whole range above
+	result, err = s.SuggestTolerance(tpl, positive, newScreen(48, 32))
+	if err != nil || !math.IsInf(result.NegativeDistance, 1) || result.Suggested != (result.PositiveDistance+MaxColorDistance)/2 {
+		t.Errorf("blank negative = %+v, %v; want +Inf and the midpoint up to MaxColorDistance", result, err)
+	}
+
+	// Samples the wrong way round cannot be separated
+	if _, err := s.SuggestTolerance(tpl, negative, positive); err == nil {
+		t.Error("SuggestTolerance accepted a negative closer than the positive")
+	}
+	if _, err := s.SuggestTolerance(tpl, newScreen(48, 32), negative); err == nil {
+		t.Error("SuggestTolerance accepted a positive the template never matches")
+	}
+}
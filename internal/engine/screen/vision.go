@@ -4,35 +4,115 @@ import (
 	"fmt"
 	"image"
 	"image/png"
+	"io"
 	"math"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/ConserveLee/gui-idle/internal/constants"
 	"github.com/kbinani/screenshot"
 )
 
-// Searcher handles screen capturing and template matching
+// Searcher handles screen capturing and template matching.
+//
+// Settings (display, match mode, matcher, normalization, capture retry, capturer,
+// debug func, full template match) are safe to change from any goroutine, also
+// mid-run: a scan or capture reads them once when it starts, so a change applies
+// from the next one on. Capturing (Frame, CaptureScreen), InvalidateFrame, the
+// scans and TakeMatchCount belong to a single goroutine (the bot's).
 type Searcher struct {
-	DisplayIndex int
-	debugFunc    func(string, ...interface{})
+	matchCount int // Matches found since the last TakeMatchCount
+
+	mu            sync.RWMutex // Guards the settings and the cached frame below
+	display       int          // Display index to capture
+	matchMode     MatchMode
+	matcher       Matcher        // Candidate evaluation (nil = PixelMatcher for matchMode)
+	frame         image.Image    // Per-tick cached capture (nil = invalid)
+	normalization *Normalization // Color correction applied to captures (nil = none)
+	debugFunc     func(string, ...interface{})
+
+	// Opaque Bounds: per-template box of non-transparent pixels (see matchArea)
+	opaqueMu     sync.Mutex
+	opaque       map[image.Image]image.Rectangle
+	fullTemplate bool // Match over the full template bounds (for comparison checks)
+
+	// Capture Retry: transient capture errors (e.g. a display mode change) are retried
+	capture      func(index int) (image.Image, error) // Captures a display (nil = CaptureDisplay)
+	retries      int                                  // Extra attempts per CaptureScreen
+	retryBackoff time.Duration                        // Wait before the first retry, doubling per retry
 }
 
 // NewSearcher creates a new instance
 func NewSearcher() *Searcher {
 	return &Searcher{
-		DisplayIndex: 0, // Default to main display
+		display:      0, // Default to main display
 		debugFunc:    func(string, ...interface{}) {}, // No-op by default
+		retries:      constants.CaptureRetries,
+		retryBackoff: constants.CaptureRetryBackoff,
 	}
 }
 
 // SetDebugFunc sets the debug logging function
 func (s *Searcher) SetDebugFunc(f func(string, ...interface{})) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.debugFunc = f
 }
 
-// SetDisplayID sets the target display index for capturing
-func (s *Searcher) SetDisplayID(index int) {
-	s.DisplayIndex = index
+// debug logs through the debug function
+func (s *Searcher) debug(format string, args ...interface{}) {
+	s.mu.RLock()
+	f := s.debugFunc
+	s.mu.RUnlock()
+	f(format, args...)
+}
+
+// DisplayIndex returns the index of the display being captured
+func (s *Searcher) DisplayIndex() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.display
+}
+
+// IsValidDisplay reports whether index refers to a currently active display
+func (s *Searcher) IsValidDisplay(index int) bool {
+	return index >= 0 && index < screenshot.NumActiveDisplays()
+}
+
+// SetDisplayID sets the target display index for capturing.
+// Invalid indices are rejected and the current display is kept.
+func (s *Searcher) SetDisplayID(index int) error {
+	if !s.IsValidDisplay(index) {
+		return fmt.Errorf("invalid display %d (%d active)", index, screenshot.NumActiveDisplays())
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.display = index
+	s.frame = nil
+	return nil
+}
+
+// SetMatchMode sets how pixels are compared during template matching.
+// It only affects the default PixelMatcher (see SetMatcher).
+func (s *Searcher) SetMatchMode(mode MatchMode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.matchMode = mode
+}
+
+// mode returns the current match mode
+func (s *Searcher) mode() MatchMode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.matchMode
+}
+
+// TakeMatchCount returns the number of matches found since the last call and resets it
+func (s *Searcher) TakeMatchCount() int {
+	n := s.matchCount
+	s.matchCount = 0
+	return n
 }
 
 // SaveDebugScreenshot saves the current screen to a file for debugging
@@ -41,7 +121,11 @@ func (s *Searcher) SaveDebugScreenshot(filename string) error {
 	if err != nil {
 		return err
 	}
+	return SaveImage(filename, img)
+}
 
+// SaveImage writes img to a PNG file
+func SaveImage(filename string, img image.Image) error {
 	f, err := os.Create(filename)
 	if err != nil {
 		return err
@@ -58,23 +142,102 @@ func (s *Searcher) LoadImage(path string) (image.Image, error) {
 		return nil, err
 	}
 	defer f.Close()
+	return s.DecodeImage(f, path)
+}
 
-	img, _, err := image.Decode(f)
-	return img, err
+// DecodeImage loads an image from r, e.g. a template embedded in the binary.
+// name identifies it in errors.
+func (s *Searcher) DecodeImage(r io.Reader, name string) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", name, err)
+	}
+	s.matchArea(img) // Cache the opaque bounds up front
+	return img, nil
 }
 
-// CaptureScreen returns the current screen image
+// CaptureScreen returns the current screen image.
+// kbinani/screenshot has no API to capture into an existing buffer, so every call
+// allocates a new frame; this also means a frame handed to matching is never overwritten.
+// Pixel reads on the returned *image.RGBA are allocation-free (see rawPixel).
+// A failed capture is retried (see SetCaptureRetry) before the error is returned.
 func (s *Searcher) CaptureScreen() (image.Image, error) {
+	s.mu.RLock()
+	capture, display, retries, wait, normalization := s.capture, s.display, s.retries, s.retryBackoff, s.normalization
+	s.mu.RUnlock()
+	if capture == nil {
+		capture = CaptureDisplay
+	}
+
+	img, err := capture(display)
+	for retry := 1; err != nil && retry <= retries; retry++ {
+		s.debug("[Capture] %v, retry %d/%d in %v", err, retry, retries, wait)
+		time.Sleep(wait)
+		wait *= 2
+		img, err = capture(display)
+	}
+	if err != nil || normalization == nil {
+		return img, err
+	}
+	return normalization.Apply(img), nil
+}
+
+// SetCaptureRetry sets how many times a failed capture is retried within one
+// CaptureScreen call, and the wait before the first retry (doubling per retry)
+func (s *Searcher) SetCaptureRetry(retries int, backoff time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retries = retries
+	s.retryBackoff = backoff
+}
+
+// SetCapturer replaces the display capture (nil = CaptureDisplay), e.g. with a
+// recorded frame source or a failing capturer in checks
+func (s *Searcher) SetCapturer(capture func(index int) (image.Image, error)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.capture = capture
+}
+
+// CaptureDisplay captures one display by index, independent of the selected display
+func CaptureDisplay(index int) (image.Image, error) {
 	// kbinani/screenshot handles multi-monitor bounds correctly
-	bounds := screenshot.GetDisplayBounds(s.DisplayIndex)
+	bounds := screenshot.GetDisplayBounds(index)
 
 	img, err := screenshot.CaptureRect(bounds)
 	if err != nil {
-		return nil, fmt.Errorf("failed to capture screen %d: %v", s.DisplayIndex, err)
+		return nil, fmt.Errorf("failed to capture screen %d: %v", index, err)
 	}
 	return img, nil
 }
 
+// Frame returns the cached frame for the current tick, capturing one if needed.
+// Several checks within one tick can share a frame this way; call InvalidateFrame
+// at the start of each tick and after any click so the next Frame is fresh.
+func (s *Searcher) Frame() (image.Image, error) {
+	s.mu.RLock()
+	frame := s.frame
+	s.mu.RUnlock()
+	if frame != nil {
+		return frame, nil
+	}
+	img, err := s.CaptureScreen()
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.frame = img
+	s.mu.Unlock()
+	return img, nil
+}
+
+// InvalidateFrame drops the cached frame so the next Frame call captures again
+func (s *Searcher) InvalidateFrame() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.frame = nil
+}
+
 // FindTemplate searches for the 'template' image inside the 'screen' image.
 // Returns x, y (top-left) and true if found. (Backward compatibility wrapper)
 func (s *Searcher) FindTemplate(screenImg, templateImg image.Image, tolerance float64) (int, int, bool) {
@@ -85,6 +248,32 @@ func (s *Searcher) FindTemplate(screenImg, templateImg image.Image, tolerance fl
 	return 0, 0, false
 }
 
+// FindAny searches for each template in order and returns the index and top-left
+// of the first one found ("first hit wins"); later templates are not scanned.
+// To try large templates first, pass them in LargestFirst order.
+func (s *Searcher) FindAny(screenImg image.Image, templates []image.Image, tolerance float64) (int, image.Point, bool) {
+	for i, tpl := range templates {
+		if x, y, found := s.FindTemplate(screenImg, tpl, tolerance); found {
+			return i, image.Point{X: x, Y: y}, true
+		}
+	}
+	return -1, image.Point{}, false
+}
+
+// CheckTemplateSize reports why templateImg can never match inside an area of the
+// given size: it has a zero dimension, or it is larger than the area (e.g. it was
+// cropped from a bigger display). It returns nil when the template fits.
+func CheckTemplateSize(templateImg image.Image, area image.Point) error {
+	size := templateImg.Bounds().Size()
+	if size.X <= 0 || size.Y <= 0 {
+		return fmt.Errorf("template is empty (%dx%d)", size.X, size.Y)
+	}
+	if size.X > area.X || size.Y > area.Y {
+		return fmt.Errorf("template is %dx%d, larger than the %dx%d capture", size.X, size.Y, area.X, area.Y)
+	}
+	return nil
+}
+
 // FindAllTemplatesInROI searches for templates only within the specified ROI (Region of Interest).
 // The ROI is specified in screen coordinates. Results are also in screen coordinates.
 // If roi is empty (zero rect), falls back to full screen search.
@@ -94,129 +283,187 @@ func (s *Searcher) FindAllTemplatesInROI(screenImg, templateImg image.Image, roi
 		return s.FindAllTemplates(screenImg, templateImg, tolerance)
 	}
 
-	sBounds := screenImg.Bounds()
 	tBounds := templateImg.Bounds()
 	tWidth, tHeight := tBounds.Dx(), tBounds.Dy()
 
 	// Clamp ROI to screen bounds
-	searchArea := roi.Intersect(sBounds)
+	searchArea := roi.Intersect(screenImg.Bounds())
 	if searchArea.Empty() {
 		return nil
 	}
 
-	// Ensure we have room for template matching
-	if searchArea.Dx() < tWidth || searchArea.Dy() < tHeight {
-		return nil
+	// Search only within ROI (templates that don't fit are skipped by the scan)
+	var matches []image.Point
+	s.scanTemplate(screenImg, templateImg, searchArea, tolerance, func(p image.Point) {
+		matches = append(matches, p)
+	})
+
+	matches = dedupMatches(matches, tWidth, tHeight)
+	s.matchCount += len(matches)
+	return matches
+}
+
+// FindAllTemplatesTiered tries each tolerance in turn (strictest first) and returns the
+// matches of the first tier that finds any, with that tier's index (-1 = no tier matched).
+// Loose tiers then only catch degraded targets that the strict tier misses.
+func (s *Searcher) FindAllTemplatesTiered(screenImg, templateImg image.Image, tolerances []float64) ([]image.Point, int) {
+	for tier, tolerance := range tolerances {
+		if matches := s.FindAllTemplates(screenImg, templateImg, tolerance); len(matches) > 0 {
+			return matches, tier
+		}
 	}
+	return nil, -1
+}
 
+// FindAllTemplates searches for ALL occurrences of 'template' in 'screen'.
+// Returns a slice of coordinates (top-left).
+func (s *Searcher) FindAllTemplates(screenImg, templateImg image.Image, tolerance float64) []image.Point {
 	var matches []image.Point
+	s.scanTemplate(screenImg, templateImg, screenImg.Bounds(), tolerance, func(p image.Point) {
+		matches = append(matches, p)
+	})
 
-	getRgbAndAlpha := func(img image.Image, x, y int) (r, g, b, a uint32) {
-		c := img.At(x, y)
-		r, g, b, a = c.RGBA()
-		return r >> 8, g >> 8, b >> 8, a >> 8
-	}
+	tBounds := templateImg.Bounds()
+	matches = dedupMatches(matches, tBounds.Dx(), tBounds.Dy())
+	s.matchCount += len(matches)
+	return matches
+}
 
-	// Key pixels for quick rejection
-	tr0, tg0, tb0, ta0 := getRgbAndAlpha(templateImg, tBounds.Min.X, tBounds.Min.Y)
-	tr1, tg1, tb1, ta1 := getRgbAndAlpha(templateImg, tBounds.Min.X+tWidth/2, tBounds.Min.Y+tHeight/2)
-	tr2, tg2, tb2, ta2 := getRgbAndAlpha(templateImg, tBounds.Max.X-1, tBounds.Max.Y-1)
-
-	// Search only within ROI
-	for y := searchArea.Min.Y; y <= searchArea.Max.Y-tHeight; y++ {
-		for x := searchArea.Min.X; x <= searchArea.Max.X-tWidth; x++ {
-			// Quick checks
-			if ta0 > 0 {
-				sr, sg, sb, _ := getRgbAndAlpha(screenImg, x, y)
-				if !colorSimilar(sr, sg, sb, tr0, tg0, tb0, tolerance) {
-					continue
-				}
-			}
-			if ta1 > 0 {
-				sr, sg, sb, _ := getRgbAndAlpha(screenImg, x+tWidth/2, y+tHeight/2)
-				if !colorSimilar(sr, sg, sb, tr1, tg1, tb1, tolerance) {
-					continue
-				}
-			}
-			if ta2 > 0 {
-				sr, sg, sb, _ := getRgbAndAlpha(screenImg, x+tWidth-1, y+tHeight-1)
-				if !colorSimilar(sr, sg, sb, tr2, tg2, tb2, tolerance) {
-					continue
-				}
+// CountTemplate returns len(FindAllTemplates(...)) without collecting every point.
+// It applies the same dedup, but only remembers kept matches close enough above
+// the current row to still absorb a duplicate.
+func (s *Searcher) CountTemplate(screenImg, templateImg image.Image, tolerance float64) int {
+	tBounds := templateImg.Bounds()
+	dx, dy := tBounds.Dx()/2, tBounds.Dy()/2
+
+	count := 0
+	var recent []image.Point // Kept matches within dy rows of the scan
+	s.scanTemplate(screenImg, templateImg, screenImg.Bounds(), tolerance, func(p image.Point) {
+		// Scan order is top-to-bottom, so older rows can never match again
+		n := 0
+		for _, k := range recent {
+			if p.Y-k.Y <= dy {
+				recent[n] = k
+				n++
 			}
-
-			// Full check
-			result := match(screenImg, templateImg, x, y, tolerance, getRgbAndAlpha)
-			if result.matched {
-				s.debugFunc("[Match ROI] at (%d,%d) failRate=%.2f%% maxDiff=%.1f", x, y, result.failRate*100, result.maxDiff)
-				matches = append(matches, image.Point{X: x, Y: y})
-				x += tWidth / 2
+		}
+		recent = recent[:n]
+		for _, k := range recent {
+			if abs(p.X-k.X) <= dx {
+				return
 			}
 		}
-	}
+		recent = append(recent, p)
+		count++
+	})
 
-	return matches
+	s.matchCount += count
+	return count
 }
 
-// FindAllTemplates searches for ALL occurrences of 'template' in 'screen'.
-// Returns a slice of coordinates (top-left).
-func (s *Searcher) FindAllTemplates(screenImg, templateImg image.Image, tolerance float64) []image.Point {
-	sBounds := screenImg.Bounds()
+// scanTemplate slides templateImg over area of screenImg and calls found for every
+// candidate the matcher accepts (top-left, in scan order), skipping half a template
+// ahead after each one
+func (s *Searcher) scanTemplate(screenImg, templateImg image.Image, area image.Rectangle, tolerance float64, found func(image.Point)) {
+	s.scanWith(s.Matcher(), screenImg, templateImg, area, tolerance, found)
+}
+
+// scanWith is scanTemplate with an explicit matcher
+func (s *Searcher) scanWith(matcher Matcher, screenImg, templateImg image.Image, area image.Rectangle, tolerance float64, found func(image.Point)) {
 	tBounds := templateImg.Bounds()
 	tWidth, tHeight := tBounds.Dx(), tBounds.Dy()
+	if err := CheckTemplateSize(templateImg, area.Size()); err != nil {
+		s.debug("[Match] Skipped: %v", err)
+		return
+	}
+	check := s.candidateCheck(matcher, screenImg, templateImg, tolerance)
 
-	var matches []image.Point
+	// Iterate over the area
+	// Optimization: This is a basic sliding window.
+	for y := area.Min.Y; y <= area.Max.Y-tHeight; y++ {
+		for x := area.Min.X; x <= area.Max.X-tWidth; x++ {
+			if at := (image.Point{X: x, Y: y}); check(at) {
+				found(at)
+				x += tWidth / 2
+			}
+		}
+	}
+}
 
-	// Helper to get color components normalized 0-255, plus Alpha
-	getRgbAndAlpha := func(img image.Image, x, y int) (r, g, b, a uint32) {
-		c := img.At(x, y)
-		r, g, b, a = c.RGBA()
-		return r >> 8, g >> 8, b >> 8, a >> 8
+// candidateCheck returns a check of whether templateImg matches at a top-left
+// position (quick probe rejection, then the matcher), shared by the scan orders.
+// It logs each match's score for debugging.
+func (s *Searcher) candidateCheck(matcher Matcher, screenImg, templateImg image.Image, tolerance float64) func(image.Point) bool {
+	evaluate := func(at image.Point) (bool, float64) {
+		return matcher.Match(screenImg, templateImg, at, tolerance)
 	}
 
-	// We check a few key pixels of the template against the screen for quick rejection
-	// Points: Top-Left, Center, Bottom-Right
-	tr0, tg0, tb0, ta0 := getRgbAndAlpha(templateImg, tBounds.Min.X, tBounds.Min.Y)
-	tr1, tg1, tb1, ta1 := getRgbAndAlpha(templateImg, tBounds.Min.X+tWidth/2, tBounds.Min.Y+tHeight/2)
-	tr2, tg2, tb2, ta2 := getRgbAndAlpha(templateImg, tBounds.Max.X-1, tBounds.Max.Y-1)
+	// The pixel matcher allows a quick rejection: check a few key pixels of the
+	// template against the screen first. Points: Top-Left, Center, Bottom-Right
+	// (or the nearest opaque substitutes). Other matchers see every candidate.
+	// Its full check also skips the transparent margins of loosely cropped templates.
+	var getRgbAndAlpha func(image.Image, int, int) (uint32, uint32, uint32, uint32)
+	var probes []probe
+	if pm, ok := matcher.(PixelMatcher); ok {
+		getRgbAndAlpha = pixelGetterFor(pm.Mode)
+		probes = selectProbes(templateImg, getRgbAndAlpha)
+		area := s.matchArea(templateImg)
+		evaluate = func(at image.Point) (bool, float64) {
+			result := match(screenImg, templateImg, at.X, at.Y, tolerance, pm.Mode, getRgbAndAlpha, area, constants.MaxFailRate)
+			return result.matched, result.failRate
+		}
+	}
 
-	// Iterate over the screen
-	// Optimization: This is a basic sliding window.
-	for y := sBounds.Min.Y; y <= sBounds.Max.Y-tHeight; y++ {
-		for x := sBounds.Min.X; x <= sBounds.Max.X-tWidth; x++ {
-
-			// Quick checks
-			if ta0 > 0 {
-				sr, sg, sb, _ := getRgbAndAlpha(screenImg, x, y)
-				if !colorSimilar(sr, sg, sb, tr0, tg0, tb0, tolerance) {
-					continue
-				}
-			}
-			if ta1 > 0 {
-				sr, sg, sb, _ := getRgbAndAlpha(screenImg, x+tWidth/2, y+tHeight/2)
-				if !colorSimilar(sr, sg, sb, tr1, tg1, tb1, tolerance) {
-					continue
-				}
-			}
-			if ta2 > 0 {
-				sr, sg, sb, _ := getRgbAndAlpha(screenImg, x+tWidth-1, y+tHeight-1)
-				if !colorSimilar(sr, sg, sb, tr2, tg2, tb2, tolerance) {
-					continue
-				}
+	return func(at image.Point) bool {
+		// Quick checks
+		for _, p := range probes {
+			sr, sg, sb, _ := getRgbAndAlpha(screenImg, at.X+p.off.X, at.Y+p.off.Y)
+			if !colorSimilar(sr, sg, sb, p.r, p.g, p.b, tolerance) {
+				return false
 			}
+		}
 
-			// Full check
-			result := match(screenImg, templateImg, x, y, tolerance, getRgbAndAlpha)
-			if result.matched {
-				// Log match quality for debugging
-				s.debugFunc("[Match] at (%d,%d) failRate=%.2f%% maxDiff=%.1f", x, y, result.failRate*100, result.maxDiff)
-				matches = append(matches, image.Point{X: x, Y: y})
-				x += tWidth / 2
+		// Full check
+		matched, score := evaluate(at)
+		if matched {
+			// Log match quality for debugging
+			s.debug("[Match] at (%d,%d) score=%.4f", at.X, at.Y, score)
+		}
+		return matched
+	}
+}
+
+// dedupMatches collapses clusters of matches that belong to the same button.
+// The scan only skips ahead in X, so vertically adjacent duplicates survive;
+// any point within tWidth/2 x tHeight/2 of a kept point is dropped.
+// Points are in scan order (top-to-bottom), so the first of each cluster is kept.
+func dedupMatches(points []image.Point, tWidth, tHeight int) []image.Point {
+	if len(points) < 2 {
+		return points
+	}
+
+	dx, dy := tWidth/2, tHeight/2
+	kept := make([]image.Point, 0, len(points))
+	for _, p := range points {
+		duplicate := false
+		for _, k := range kept {
+			if abs(p.X-k.X) <= dx && abs(p.Y-k.Y) <= dy {
+				duplicate = true
+				break
 			}
 		}
+		if !duplicate {
+			kept = append(kept, p)
+		}
 	}
+	return kept
+}
 
-	return matches
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
 }
 
 func colorSimilar(r1, g1, b1, r2, g2, b2 uint32, tolerance float64) bool {
@@ -230,16 +477,24 @@ type matchResult struct {
 	matched   bool
 	failRate  float64
 	maxDiff   float64
+	meanDiff  float64 // Over the pixels within tolerance (set only when the check ran to the end)
 }
 
-func match(screenImg, templateImg image.Image, sx, sy int, tolerance float64, getRgbAndAlpha func(image.Image, int, int) (uint32, uint32, uint32, uint32)) matchResult {
+// match compares the template pixels inside area (template coordinates; the template
+// bounds, or its opaque bounds since transparent pixels are skipped anyway) with the
+// screen at (sx, sy), the position of the template's top-left. Up to maxFailRate of
+// the pixels may fail; above constants.MaxFailRate (a partial match, where covered
+// pixels can differ arbitrarily) the MaxPixelDiff exit is off.
+func match(screenImg, templateImg image.Image, sx, sy int, tolerance float64, mode MatchMode, getRgbAndAlpha func(image.Image, int, int) (uint32, uint32, uint32, uint32), area image.Rectangle, maxFailRate float64) matchResult {
 	tBounds := templateImg.Bounds()
+	area = area.Sub(tBounds.Min) // Offsets from the template's top-left
 	totalPixels := 0
 	failedPixels := 0
-	maxDiff := 0.0
+	maxDiff, sumDiff := 0.0, 0.0
+	strict := maxFailRate <= constants.MaxFailRate
 
-	for ty := 0; ty < tBounds.Dy(); ty++ {
-		for tx := 0; tx < tBounds.Dx(); tx++ {
+	for ty := area.Min.Y; ty < area.Max.Y; ty++ {
+		for tx := area.Min.X; tx < area.Max.X; tx++ {
 			tr, tg, tb, ta := getRgbAndAlpha(templateImg, tBounds.Min.X+tx, tBounds.Min.Y+ty)
 
 			// Skip transparent pixels in template (act as wildcard)
@@ -255,25 +510,32 @@ func match(screenImg, templateImg image.Image, sx, sy int, tolerance float64, ge
 				maxDiff = diff
 			}
 
-			// Early exit if any pixel exceeds MaxPixelDiff (completely wrong match)
-			if diff > constants.MaxPixelDiff {
+			// Early exit if any pixel exceeds MaxPixelDiff (completely wrong match).
+			// Only meaningful for raw colors; preprocessed modes produce all-or-nothing diffs.
+			if strict && mode == MatchColor && diff > constants.MaxPixelDiff {
 				return matchResult{matched: false, failRate: float64(failedPixels) / float64(totalPixels), maxDiff: maxDiff}
 			}
 
-			if diff > tolerance {
+			if diff <= tolerance {
+				sumDiff += diff
+			} else {
 				failedPixels++
 				// Early exit if fail rate already exceeds threshold
-				if float64(failedPixels)/float64(totalPixels) > constants.MaxFailRate && totalPixels > 100 {
+				if float64(failedPixels)/float64(totalPixels) > maxFailRate && totalPixels > 100 {
 					return matchResult{matched: false, failRate: float64(failedPixels) / float64(totalPixels), maxDiff: maxDiff}
 				}
 			}
 		}
 	}
 
-	// Final check: allow up to MaxFailRate of pixels to fail
+	// Final check: allow up to maxFailRate of pixels to fail
 	if totalPixels == 0 {
 		return matchResult{matched: false, failRate: 1.0, maxDiff: 0}
 	}
 	failRate := float64(failedPixels) / float64(totalPixels)
-	return matchResult{matched: failRate <= constants.MaxFailRate, failRate: failRate, maxDiff: maxDiff}
+	result := matchResult{matched: failRate <= maxFailRate, failRate: failRate, maxDiff: maxDiff}
+	if passed := totalPixels - failedPixels; passed > 0 {
+		result.meanDiff = sumDiff / float64(passed)
+	}
+	return result
 }
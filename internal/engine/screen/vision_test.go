@@ -0,0 +1,436 @@
+package screen
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ConserveLee/gui-idle/internal/constants"
+	"github.com/kbinani/screenshot"
+)
+
+var background = color.RGBA{50, 50, 50, 255}
+
+const tol = float64(constants.DefaultTolerance)
+
+// newScreen returns a w x h screen filled with the background color
+func newScreen(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	fill(img)
+	return img
+}
+
+func fill(img *image.RGBA) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			img.SetRGBA(x, y, background)
+		}
+	}
+}
+
+// newTemplate returns an opaque patterned template whose red channel is raised by shift.
+// Every pixel differs from the background by more than the default tolerance.
+func newTemplate(w, h, shift int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{uint8(150 + shift), uint8(x * 30), uint8(y * 30), 255})
+		}
+	}
+	return img
+}
+
+// paste copies the opaque pixels of tpl onto scr with the top-left corner at (x, y)
+func paste(scr *image.RGBA, tpl *image.NRGBA, x, y int) {
+	b := tpl.Bounds()
+	for ty := b.Min.Y; ty < b.Max.Y; ty++ {
+		for tx := b.Min.X; tx < b.Max.X; tx++ {
+			c := tpl.NRGBAAt(tx, ty)
+			if c.A == 0 {
+				continue
+			}
+			scr.SetRGBA(x+tx-b.Min.X, y+ty-b.Min.Y, color.RGBA{c.R, c.G, c.B, 255})
+		}
+	}
+}
+
+func TestFindAllTemplates(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+
+	// framed has a transparent border: anything underneath must be ignored
+	framed := newTemplate(12, 12, 0)
+	framedScreen := newScreen(60, 40)
+	paste(framedScreen, framed, 30, 10)
+	for y := 0; y < 12; y++ {
+		for x := 0; x < 12; x++ {
+			if x < 2 || y < 2 || x >= 10 || y >= 10 {
+				framed.SetNRGBA(x, y, color.NRGBA{})
+				framedScreen.SetRGBA(30+x, 10+y, color.RGBA{uint8(x * 20), 255, uint8(y * 20), 255})
+			}
+		}
+	}
+
+	tests := []struct {
+		name  string
+		setup func(scr *image.RGBA)
+		tpl   image.Image
+		want  []image.Point
+	}{
+		{
+			name:  "exact match",
+			setup: func(scr *image.RGBA) { paste(scr, tpl, 17, 9) },
+			tpl:   tpl,
+			want:  []image.Point{{17, 9}},
+		},
+		{
+			name:  "diff equal to the tolerance",
+			setup: func(scr *image.RGBA) { paste(scr, newTemplate(8, 8, int(tol)), 20, 20) },
+			tpl:   tpl,
+			want:  []image.Point{{20, 20}},
+		},
+		{
+			name:  "diff one over the tolerance",
+			setup: func(scr *image.RGBA) { paste(scr, newTemplate(8, 8, int(tol)+1), 20, 20) },
+			tpl:   tpl,
+			want:  nil,
+		},
+		{
+			name:  "no match",
+			setup: func(*image.RGBA) {},
+			tpl:   tpl,
+			want:  nil,
+		},
+		{
+			name: "multiple matches in scan order",
+			setup: func(scr *image.RGBA) {
+				paste(scr, tpl, 10, 25)
+				paste(scr, tpl, 2, 2)
+				paste(scr, tpl, 30, 5)
+			},
+			tpl:  tpl,
+			want: []image.Point{{2, 2}, {30, 5}, {10, 25}},
+		},
+		{
+			name:  "transparent pixels are wildcards",
+			setup: func(scr *image.RGBA) { copy(scr.Pix, framedScreen.Pix) },
+			tpl:   framed,
+			want:  []image.Point{{30, 10}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scr := newScreen(60, 40)
+			tt.setup(scr)
+			if got := NewSearcher().FindAllTemplates(scr, tt.tpl, tol); !slices.Equal(got, tt.want) {
+				t.Errorf("FindAllTemplates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindAllTemplatesRegion(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	s := NewSearcher()
+
+	scr := newScreen(60, 40)
+	paste(scr, tpl, 2, 2)
+	paste(scr, tpl, 40, 25)
+
+	if got, want := s.FindAllTemplatesInROI(scr, tpl, image.Rect(30, 20, 60, 40), tol), []image.Point{{40, 25}}; !slices.Equal(got, want) {
+		t.Errorf("FindAllTemplatesInROI = %v, want %v", got, want)
+	}
+	// A scan region crops the frame with SubImage; matches keep frame coordinates
+	region := scr.SubImage(image.Rect(30, 20, 60, 40))
+	if got, want := s.FindAllTemplates(region, tpl, tol), []image.Point{{40, 25}}; !slices.Equal(got, want) {
+		t.Errorf("FindAllTemplates(region) = %v, want %v", got, want)
+	}
+	if got, want := s.FindAllTemplates(scr, tpl, tol), []image.Point{{2, 2}, {40, 25}}; !slices.Equal(got, want) {
+		t.Errorf("FindAllTemplates(full) = %v, want %v", got, want)
+	}
+
+	offset := image.NewRGBA(image.Rect(100, 100, 160, 140))
+	fill(offset)
+	paste(offset, tpl, 140, 125)
+	if got, want := s.FindAllTemplatesInROI(offset, tpl, image.Rect(130, 120, 160, 140), tol), []image.Point{{140, 125}}; !slices.Equal(got, want) {
+		t.Errorf("FindAllTemplatesInROI(offset bounds) = %v, want %v", got, want)
+	}
+}
+
+func TestFindAny(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	scr := newScreen(60, 40)
+	paste(scr, tpl, 25, 12)
+	absent := newTemplate(8, 8, 80)
+
+	i, p, found := NewSearcher().FindAny(scr, []image.Image{absent, tpl, tpl}, tol)
+	if !found || i != 1 || p != image.Pt(25, 12) {
+		t.Errorf("FindAny = %d, %v, %v; want 1, (25,12), true", i, p, found)
+	}
+	if _, _, found := NewSearcher().FindAny(scr, []image.Image{absent}, tol); found {
+		t.Error("FindAny found a template that is not on screen")
+	}
+}
+
+func TestFindAllTemplatesTiered(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	s := NewSearcher()
+	scr := newScreen(60, 40)
+	paste(scr, newTemplate(8, 8, int(tol)+10), 20, 20) // Faded
+
+	if points, tier := s.FindAllTemplatesTiered(scr, tpl, []float64{tol}); len(points) != 0 || tier != -1 {
+		t.Errorf("strict tier = %v, %d; want no match, -1", points, tier)
+	}
+	points, tier := s.FindAllTemplatesTiered(scr, tpl, []float64{tol, tol + 20})
+	if want := []image.Point{{20, 20}}; !slices.Equal(points, want) || tier != 1 {
+		t.Errorf("looser tier = %v, %d; want %v, 1", points, tier, want)
+	}
+}
+
+func TestCountTemplate(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	flat := image.NewNRGBA(image.Rect(0, 0, 6, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 6; x++ {
+			flat.SetNRGBA(x, y, color.NRGBA{200, 30, 30, 255})
+		}
+	}
+	redBlock := func(scr *image.RGBA, x0, y0, w, h int) {
+		for y := y0; y < y0+h; y++ {
+			for x := x0; x < x0+w; x++ {
+				scr.SetRGBA(x, y, color.RGBA{200, 30, 30, 255})
+			}
+		}
+	}
+
+	one := newScreen(60, 40)
+	paste(one, tpl, 5, 5)
+	grid := newScreen(60, 40)
+	for _, p := range []image.Point{{0, 0}, {20, 0}, {40, 0}, {10, 20}, {30, 25}} {
+		paste(grid, tpl, p.X, p.Y)
+	}
+	// Flat blocks whose raw matches need vertical dedup
+	blocks := newScreen(60, 40)
+	redBlock(blocks, 2, 2, 14, 14)
+	redBlock(blocks, 30, 5, 25, 30)
+
+	tests := []struct {
+		name string
+		scr  image.Image
+		tpl  image.Image
+		want int
+	}{
+		{"empty", newScreen(60, 40), tpl, 0},
+		{"one", one, tpl, 1},
+		{"grid", grid, tpl, 5},
+		{"flat blocks", blocks, flat, 44},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSearcher()
+			count, all := s.CountTemplate(tt.scr, tt.tpl, tol), len(s.FindAllTemplates(tt.scr, tt.tpl, tol))
+			if count != tt.want || all != tt.want {
+				t.Errorf("CountTemplate = %d, len(FindAllTemplates) = %d, want %d", count, all, tt.want)
+			}
+		})
+	}
+}
+
+func TestDedupVerticalCluster(t *testing.T) {
+	// A flat 6x6 template inside a 6x9 block matches on four consecutive rows.
+	// The scan skips ahead in X only, so all four survive it.
+	flat := image.NewNRGBA(image.Rect(0, 0, 6, 6))
+	for y := 0; y < 6; y++ {
+		for x := 0; x < 6; x++ {
+			flat.SetNRGBA(x, y, color.NRGBA{200, 30, 30, 255})
+		}
+	}
+	scr := newScreen(40, 30)
+	for y := 8; y < 17; y++ {
+		for x := 12; x < 18; x++ {
+			scr.SetRGBA(x, y, color.RGBA{200, 30, 30, 255})
+		}
+	}
+
+	var raw []image.Point
+	NewSearcher().scanTemplate(scr, flat, scr.Bounds(), tol, func(p image.Point) { raw = append(raw, p) })
+	if want := []image.Point{{12, 8}, {12, 9}, {12, 10}, {12, 11}}; !slices.Equal(raw, want) {
+		t.Fatalf("raw scan = %v, want the vertical cluster %v", raw, want)
+	}
+	// One representative per cluster: the first in scan order
+	if got, want := NewSearcher().FindAllTemplates(scr, flat, tol), []image.Point{{12, 8}}; !slices.Equal(got, want) {
+		t.Errorf("FindAllTemplates = %v, want %v", got, want)
+	}
+	// Points further apart than half the template size are separate buttons
+	if got, want := dedupMatches([]image.Point{{12, 8}, {16, 8}, {12, 11}, {12, 12}}, 6, 6), []image.Point{{12, 8}, {16, 8}, {12, 12}}; !slices.Equal(got, want) {
+		t.Errorf("dedupMatches = %v, want %v", got, want)
+	}
+}
+
+func TestSkipsOversizedAndEmptyTemplates(t *testing.T) {
+	scr := newScreen(60, 40)
+	paste(scr, newTemplate(8, 8, 0), 10, 10)
+	s := NewSearcher()
+
+	for _, tpl := range []image.Image{
+		newTemplate(80, 20, 0),                 // Wider than the capture
+		newTemplate(20, 50, 0),                 // Taller than the capture
+		image.NewNRGBA(image.Rect(0, 0, 0, 8)), // Zero width
+		image.NewNRGBA(image.Rect(0, 0, 8, 0)), // Zero height
+	} {
+		size := tpl.Bounds().Size()
+		if CheckTemplateSize(tpl, scr.Bounds().Size()) == nil {
+			t.Errorf("CheckTemplateSize(%v) accepted the template", size)
+		}
+		if got := s.FindAllTemplates(scr, tpl, tol); len(got) != 0 {
+			t.Errorf("FindAllTemplates(%v) = %v", size, got)
+		}
+		if got := s.CountTemplate(scr, tpl, tol); got != 0 {
+			t.Errorf("CountTemplate(%v) = %d", size, got)
+		}
+		if got := s.FindAllTemplatesInROI(scr, tpl, image.Rect(0, 0, 30, 30), tol); len(got) != 0 {
+			t.Errorf("FindAllTemplatesInROI(%v) = %v", size, got)
+		}
+	}
+}
+
+func TestLoadImage(t *testing.T) {
+	dir := t.TempDir()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, newTemplate(8, 8, 0)); err != nil {
+		t.Fatal(err)
+	}
+	valid, truncated := filepath.Join(dir, "20.png"), filepath.Join(dir, "30.png")
+	if err := os.WriteFile(valid, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(truncated, buf.Bytes()[:buf.Len()/2], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewSearcher()
+	if _, err := s.LoadImage(valid); err != nil {
+		t.Errorf("LoadImage(valid) = %v", err)
+	}
+	_, err := s.LoadImage(truncated)
+	if err == nil {
+		t.Fatal("LoadImage(truncated) succeeded")
+	}
+	if !strings.Contains(err.Error(), truncated) {
+		t.Errorf("error %q does not name the file", err)
+	}
+}
+
+func TestCaptureRetry(t *testing.T) {
+	s := NewSearcher()
+	calls := 0
+	s.SetCapturer(func(int) (image.Image, error) {
+		calls++
+		if calls == 1 {
+			return nil, fmt.Errorf("transient")
+		}
+		return newScreen(60, 40), nil
+	})
+
+	s.SetCaptureRetry(constants.CaptureRetries, time.Millisecond)
+	frame, err := s.CaptureScreen()
+	if err != nil {
+		t.Fatalf("CaptureScreen with retries = %v", err)
+	}
+	if calls != 2 || frame.Bounds().Dx() != 60 {
+		t.Errorf("got %d captures and a %d px frame, want 2 and 60", calls, frame.Bounds().Dx())
+	}
+
+	calls = 0
+	s.SetCaptureRetry(0, time.Millisecond)
+	if _, err := s.CaptureScreen(); err == nil {
+		t.Error("CaptureScreen without retries ignored the failure")
+	}
+	if calls != 1 {
+		t.Errorf("got %d captures without retries, want 1", calls)
+	}
+}
+
+func TestSetDisplayID(t *testing.T) {
+	s := NewSearcher()
+	n := screenshot.NumActiveDisplays()
+	for i := 0; i < n; i++ {
+		if !s.IsValidDisplay(i) || s.SetDisplayID(i) != nil || s.DisplayIndex() != i {
+			t.Errorf("display %d of %d rejected", i, n)
+		}
+	}
+
+	current := s.DisplayIndex()
+	for _, index := range []int{-1, -100, n, n + 5} {
+		if s.IsValidDisplay(index) {
+			t.Errorf("IsValidDisplay(%d) = true with %d displays", index, n)
+		}
+		if err := s.SetDisplayID(index); err == nil {
+			t.Errorf("SetDisplayID(%d) accepted an invalid index", index)
+		}
+		if got := s.DisplayIndex(); got != current {
+			t.Errorf("SetDisplayID(%d) changed the display to %d, want %d kept", index, got, current)
+		}
+	}
+}
+
+// TestConcurrentSettings toggles every Searcher setting from another goroutine while
+// scanning; run with -race to catch unguarded access
+func TestConcurrentSettings(t *testing.T) {
+	tpl := newTemplate(8, 8, 0)
+	scr := newScreen(48, 32)
+	paste(scr, tpl, 20, 10)
+	s := NewSearcher()
+	s.SetCapturer(func(int) (image.Image, error) { return scr, nil })
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			s.SetMatchMode([]MatchMode{MatchColor, MatchBinary}[i%2])
+			if i%3 == 0 {
+				s.SetMatcher(PixelMatcher{Mode: MatchColor})
+			} else {
+				s.SetMatcher(nil)
+			}
+			s.SetNormalization(Normalization{Gain: [3]float64{1, 1, 1}, Offset: [3]float64{float64(i % 2), 0, 0}})
+			s.SetCaptureRetry(i%2, time.Millisecond)
+			s.SetFullTemplateMatch(i%2 == 0)
+			s.SetDebugFunc(func(string, ...interface{}) {})
+		}
+	}()
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+
+	want := []image.Point{{20, 10}}
+	for i := 0; i < 200; i++ {
+		s.InvalidateFrame()
+		frame, err := s.Frame()
+		if err != nil {
+			t.Fatalf("Frame = %v", err)
+		}
+		// Every setting combination still finds the exact copy
+		if got := s.FindAllTemplates(frame, tpl, tol); !slices.Equal(got, want) {
+			t.Fatalf("scan %d: FindAllTemplates = %v, want %v", i, got, want)
+		}
+		s.DisplayIndex()
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"fyne.io/fyne/v2/data/binding"
 )
 
+// LogPath is the session log file (appended across runs)
+var LogPath = filepath.Join("logs", "gamebot.log")
+
 // LogLevel defines the severity of the log
 type LogLevel int
 
@@ -26,28 +29,47 @@ type AppLogger struct {
 	mu          sync.Mutex
 }
 
-// NewAppLogger creates a new logger instance
+// Options configures an AppLogger
+type Options struct {
+	// Path is the log file, appended to (its directory is created if needed).
+	// Empty disables the file: messages only go to the console and UI, and
+	// nothing is created on disk (e.g. in tests).
+	Path string
+}
+
+// NewAppLogger creates a new logger instance writing to LogPath
 func NewAppLogger(data binding.StringList) *AppLogger {
-	// Ensure logs dir exists
-	os.MkdirAll("logs", 0755)
-	
+	return NewAppLoggerWithOptions(data, Options{Path: LogPath})
+}
+
+// NewAppLoggerWithOptions creates a new logger instance with the given options
+func NewAppLoggerWithOptions(data binding.StringList, opts Options) *AppLogger {
+	l := &AppLogger{dataBinding: data}
+	if opts.Path == "" {
+		return l
+	}
+
+	// Ensure the log dir exists
+	os.MkdirAll(filepath.Dir(opts.Path), 0755)
+
 	// Open log file (append mode)
-	logPath := filepath.Join("logs", "gamebot.log")
-	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	f, err := os.OpenFile(opts.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		fmt.Printf("Failed to open log file: %v\n", err)
+		return l
 	}
-
-	return &AppLogger{
-		dataBinding: data,
-		logFile:     f,
-	}
+	l.logFile = f
+	return l
 }
 
-// Close closes the file handle
+// Close flushes and closes the file handle; later messages only go to the console and UI
 func (l *AppLogger) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 	if l.logFile != nil {
+		l.logFile.Sync()
 		l.logFile.Close()
+		l.logFile = nil
 	}
 }
 
@@ -0,0 +1,54 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/data/binding"
+	"fyne.io/fyne/v2/test"
+)
+
+func TestNoFileWithoutPath(t *testing.T) {
+	test.NewApp() // Binding updates go through fyne.Do, which needs a running app
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	l := NewAppLoggerWithOptions(binding.NewStringList(), Options{Path: ""})
+	l.Info("to the console and UI only")
+	l.Debug("debug too")
+	l.Close()
+	l.Close() // Safe to call again
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("logger without a path created %v", entries)
+	}
+}
+
+func TestCloseStopsFileWrites(t *testing.T) {
+	test.NewApp()
+	path := filepath.Join(t.TempDir(), "logs", "test.log")
+	data := binding.NewStringList()
+	l := NewAppLoggerWithOptions(data, Options{Path: path})
+	l.Info("before close")
+	l.Close()
+	l.Close()
+	l.Error("after close")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "before close") || strings.Contains(string(content), "after close") {
+		t.Errorf("log file = %q, want only the message from before Close", content)
+	}
+	// The UI still gets every message
+	if list, _ := data.Get(); len(list) != 2 {
+		t.Errorf("UI list = %q, want both messages", list)
+	}
+}
@@ -0,0 +1,22 @@
+// Package window holds platform-specific tweaks to the control window.
+package window
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver"
+)
+
+// SetAlwaysOnTop keeps win above other windows (e.g. a fullscreen game) or releases it.
+// It must run on the main goroutine after the window is shown. It returns false where
+// the platform is not supported; the window is then left unchanged.
+func SetAlwaysOnTop(win fyne.Window, onTop bool) bool {
+	native, ok := win.(driver.NativeWindow)
+	if !ok {
+		return false
+	}
+	applied := false
+	native.RunNative(func(context any) {
+		applied = setOnTop(context, onTop)
+	})
+	return applied
+}
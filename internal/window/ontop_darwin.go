@@ -0,0 +1,35 @@
+//go:build darwin
+
+package window
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework AppKit
+
+#import <AppKit/AppKit.h>
+
+static void setWindowOnTop(void *window, int onTop) {
+	NSWindow *w = (NSWindow *)window;
+	[w setLevel:(onTop ? NSFloatingWindowLevel : NSNormalWindowLevel)];
+}
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"fyne.io/fyne/v2/driver"
+)
+
+func setOnTop(context any, onTop bool) bool {
+	ctx, ok := context.(driver.MacWindowContext)
+	if !ok || ctx.NSWindow == 0 {
+		return false
+	}
+	flag := C.int(0)
+	if onTop {
+		flag = 1
+	}
+	C.setWindowOnTop(unsafe.Pointer(ctx.NSWindow), flag)
+	return true
+}
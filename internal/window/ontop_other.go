@@ -0,0 +1,8 @@
+//go:build !darwin && !windows
+
+package window
+
+// setOnTop is not supported on this platform (X11/Wayland have no portable call)
+func setOnTop(context any, onTop bool) bool {
+	return false
+}
@@ -0,0 +1,36 @@
+//go:build windows
+
+package window
+
+import (
+	"syscall"
+
+	"fyne.io/fyne/v2/driver"
+)
+
+var (
+	user32           = syscall.NewLazyDLL("user32.dll")
+	procSetWindowPos = user32.NewProc("SetWindowPos")
+)
+
+const (
+	hwndTopmost   = ^uintptr(0) // HWND_TOPMOST (-1)
+	hwndNoTopmost = ^uintptr(1) // HWND_NOTOPMOST (-2)
+
+	swpNoSize     = 0x0001
+	swpNoMove     = 0x0002
+	swpNoActivate = 0x0010
+)
+
+func setOnTop(context any, onTop bool) bool {
+	ctx, ok := context.(driver.WindowsWindowContext)
+	if !ok || ctx.HWND == 0 {
+		return false
+	}
+	after := hwndNoTopmost
+	if onTop {
+		after = hwndTopmost
+	}
+	ret, _, _ := procSetWindowPos.Call(ctx.HWND, after, 0, 0, 0, 0, swpNoSize|swpNoMove|swpNoActivate)
+	return ret != 0
+}
@@ -0,0 +1,35 @@
+package window
+
+import (
+	"image"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver"
+)
+
+// PlaceOverlay turns win into a click-through marker above every other window and
+// moves it to bounds (global coordinates, as the mouse is moved to). It must run on
+// the main goroutine after the window is shown. It returns false where the platform
+// is not supported; the window is then left unchanged.
+func PlaceOverlay(win fyne.Window, bounds image.Rectangle) bool {
+	native, ok := win.(driver.NativeWindow)
+	if !ok {
+		return false
+	}
+	applied := false
+	native.RunNative(func(context any) {
+		applied = placeOverlay(context, bounds)
+	})
+	return applied
+}
+
+// SetOverlayAlpha sets the opacity of an overlay placed with PlaceOverlay (0-1)
+func SetOverlayAlpha(win fyne.Window, alpha float64) {
+	native, ok := win.(driver.NativeWindow)
+	if !ok {
+		return
+	}
+	native.RunNative(func(context any) {
+		setOverlayAlpha(context, max(0, min(1, alpha)))
+	})
+}
@@ -0,0 +1,54 @@
+//go:build darwin
+
+package window
+
+/*
+#cgo CFLAGS: -x objective-c
+#cgo LDFLAGS: -framework AppKit
+
+#import <AppKit/AppKit.h>
+
+static void placeOverlay(void *window, int x, int y, int w, int h) {
+	NSWindow *win = (NSWindow *)window;
+	// Global coordinates are top-left based; AppKit's are bottom-left of the main screen
+	CGFloat top = [[[NSScreen screens] firstObject] frame].size.height;
+	[win setFrame:NSMakeRect(x, top - y - h, w, h) display:YES];
+	[win setLevel:NSStatusWindowLevel];
+	[win setIgnoresMouseEvents:YES];
+	[win setOpaque:NO];
+	[win setBackgroundColor:[NSColor clearColor]];
+	[win setHasShadow:NO];
+}
+
+static void setOverlayAlpha(void *window, double alpha) {
+	[(NSWindow *)window setAlphaValue:alpha];
+}
+*/
+import "C"
+
+import (
+	"image"
+	"image/color"
+	"unsafe"
+
+	"fyne.io/fyne/v2/driver"
+)
+
+// OverlayBackground is painted where an overlay should be see-through. The window is
+// transparent, but the canvas still draws its theme background behind it.
+var OverlayBackground color.Color = color.Transparent
+
+func placeOverlay(context any, bounds image.Rectangle) bool {
+	ctx, ok := context.(driver.MacWindowContext)
+	if !ok || ctx.NSWindow == 0 {
+		return false
+	}
+	C.placeOverlay(unsafe.Pointer(ctx.NSWindow), C.int(bounds.Min.X), C.int(bounds.Min.Y), C.int(bounds.Dx()), C.int(bounds.Dy()))
+	return true
+}
+
+func setOverlayAlpha(context any, alpha float64) {
+	if ctx, ok := context.(driver.MacWindowContext); ok && ctx.NSWindow != 0 {
+		C.setOverlayAlpha(unsafe.Pointer(ctx.NSWindow), C.double(alpha))
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !darwin && !windows
+
+package window
+
+import (
+	"image"
+	"image/color"
+)
+
+// OverlayBackground is painted where an overlay should be see-through
+var OverlayBackground color.Color = color.Transparent
+
+// placeOverlay is not supported on this platform (X11/Wayland have no portable call)
+func placeOverlay(context any, bounds image.Rectangle) bool {
+	return false
+}
+
+func setOverlayAlpha(context any, alpha float64) {}
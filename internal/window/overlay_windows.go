@@ -0,0 +1,64 @@
+//go:build windows
+
+package window
+
+import (
+	"image"
+	"image/color"
+
+	"fyne.io/fyne/v2/driver"
+)
+
+// OverlayBackground is painted where an overlay should be see-through. The GL canvas
+// cannot be transparent, so this color is keyed out of the window instead.
+var OverlayBackground color.Color = color.RGBA{R: 255, B: 255, A: 255}
+
+var (
+	procGetWindowLong              = user32.NewProc("GetWindowLongW")
+	procSetWindowLong              = user32.NewProc("SetWindowLongW")
+	procSetLayeredWindowAttributes = user32.NewProc("SetLayeredWindowAttributes")
+)
+
+const (
+	gwlExStyle = ^uintptr(19) // GWL_EXSTYLE (-20)
+
+	wsExTransparent = 0x00000020 // Mouse input passes through
+	wsExToolWindow  = 0x00000080 // No taskbar button
+	wsExLayered     = 0x00080000
+	wsExNoActivate  = 0x08000000
+
+	lwaColorKey = 0x1
+	lwaAlpha    = 0x2
+
+	swpShowWindow = 0x0040
+)
+
+func placeOverlay(context any, bounds image.Rectangle) bool {
+	ctx, ok := context.(driver.WindowsWindowContext)
+	if !ok || ctx.HWND == 0 {
+		return false
+	}
+	style, _, _ := procGetWindowLong.Call(ctx.HWND, gwlExStyle)
+	procSetWindowLong.Call(ctx.HWND, gwlExStyle, style|wsExTransparent|wsExToolWindow|wsExLayered|wsExNoActivate)
+	if !layer(ctx.HWND, 255) {
+		return false
+	}
+	ret, _, _ := procSetWindowPos.Call(ctx.HWND, hwndTopmost,
+		uintptr(bounds.Min.X), uintptr(bounds.Min.Y), uintptr(bounds.Dx()), uintptr(bounds.Dy()),
+		swpNoActivate|swpShowWindow)
+	return ret != 0
+}
+
+func setOverlayAlpha(context any, alpha float64) {
+	if ctx, ok := context.(driver.WindowsWindowContext); ok && ctx.HWND != 0 {
+		layer(ctx.HWND, uint8(alpha*255))
+	}
+}
+
+// layer keys out OverlayBackground and sets the window opacity
+func layer(hwnd uintptr, alpha uint8) bool {
+	r, g, b, _ := OverlayBackground.RGBA()
+	key := uintptr(r>>8) | uintptr(g>>8)<<8 | uintptr(b>>8)<<16 // COLORREF 0x00BBGGRR
+	ret, _, _ := procSetLayeredWindowAttributes.Call(hwnd, key, uintptr(alpha), lwaColorKey|lwaAlpha)
+	return ret != 0
+}
@@ -1,9 +1,15 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+
 	"github.com/ConserveLee/gui-idle/app/global"
+	"github.com/ConserveLee/gui-idle/app/modal"
 	"github.com/ConserveLee/gui-idle/app/normal"
 	"github.com/ConserveLee/gui-idle/app/tools"
+	"github.com/ConserveLee/gui-idle/internal/config"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -11,19 +17,101 @@ import (
 )
 
 func main() {
+	profileName := flag.String("profile", "", "Profile (settings and templates of one game) to use (default: the last used)")
+	cfgPath := flag.String("config", "", "Path to config file (.json/.yaml) (default: the profile's config.json)")
+	assetsDir := flag.String("assets", "", "Override assets directory")
+	display := flag.Int("display", 0, "Override display index")
+	tolerance := flag.Float64("tolerance", 0, "Override color tolerance")
+	similarity := flag.Float64("similarity", 0, "Override color tolerance as a similarity percentage (0-100)")
+	matchMode := flag.String("match-mode", "", "Override match mode (color, binary, edges)")
+	dryRun := flag.Bool("dry-run", false, "Detect and log without clicking")
+	debug := flag.Bool("debug", false, "Show debug aids such as the ROI overlay")
+	maxRuntime := flag.Duration("max-runtime", 0, "Stop automatically after this duration (0 = unlimited)")
+	seed := flag.Int64("seed", 0, "Seed for randomized behavior, to reproduce a run (0 = random)")
+	flag.Parse()
+
+	if *profileName == "" {
+		*profileName = config.ActiveProfile()
+	}
+	profile, err := config.ResolveProfile(*profileName)
+	if err != nil {
+		fmt.Printf("Config error: %v\n", err)
+		os.Exit(1)
+	}
+	if *cfgPath != "" {
+		profile.ConfigPath = *cfgPath
+	}
+	cfg, err := config.LoadProfile(profile)
+	if err != nil {
+		fmt.Printf("Config error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// CLI flags override the file, but only when explicitly set
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "assets":
+			cfg.AssetsDir = *assetsDir
+		case "display":
+			cfg.Display = *display
+			cfg.DisplayID = "" // An explicit index wins over the saved monitor
+		case "tolerance":
+			cfg.Tolerance = *tolerance
+			cfg.Similarity = 0 // Otherwise it would win again on the next load
+		case "similarity":
+			cfg.Similarity = *similarity
+			cfg.ApplySimilarity()
+		case "match-mode":
+			cfg.MatchMode = *matchMode
+		case "dry-run":
+			cfg.DryRun = *dryRun
+		case "debug":
+			cfg.Debug = *debug
+		case "max-runtime":
+			cfg.MaxRuntime = config.Duration(*maxRuntime)
+		case "seed":
+			cfg.Seed = *seed
+		}
+	})
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("Config error: %v\n", err)
+		os.Exit(1)
+	}
+
 	myApp := app.New()
 	myWindow := myApp.NewWindow("zombie-idle")
 	myWindow.Resize(fyne.NewSize(500, 600))
 
+	globalPanel, globalControl := global.NewGlobalExpeditionPanel(myWindow, cfg, profile)
+	modal.SetPauseFunc(globalControl.Pause) // App dialogs pause the bot while open
+
 	// Create tabs for different features
 	tabs := container.NewAppTabs(
-		container.NewTabItem("环球远征", global.NewGlobalExpeditionPanel()),
+		container.NewTabItem("环球远征", globalPanel),
 		container.NewTabItem("普通关卡", normal.NewNormalLevelPanel()),
-		container.NewTabItem("工具箱", tools.NewToolsPanel(myWindow)),
+		container.NewTabItem("工具箱", tools.NewToolsPanel(myWindow, globalControl.AssetsRoot, globalControl.Tolerance, globalControl.SetScanRegion, globalControl.SetColorCalibration)),
 	)
 
 	tabs.SetTabLocation(container.TabLocationTop)
 
 	myWindow.SetContent(tabs)
+
+	// Stop the bot and flush the log before exiting, so we never quit mid-click
+	myWindow.SetCloseIntercept(func() {
+		if !globalControl.Running() {
+			globalControl.Shutdown()
+			myWindow.Close()
+			return
+		}
+		modal.ShowConfirm("退出", "挂机仍在运行, 确定停止并退出?", func(confirm bool) {
+			if confirm {
+				globalControl.Shutdown()
+				myWindow.Close()
+			}
+		}, myWindow)
+	})
+	// Quitting through the app menu/shortcut bypasses the intercept
+	myApp.Lifecycle().SetOnStopped(globalControl.Shutdown)
+
 	myWindow.ShowAndRun()
 }